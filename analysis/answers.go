@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseAnswers splits a combined multi-question answer by question key
+//
+// The answer must be a JSON object, optionally inside a Markdown code
+// fence, holding a value for every key in keys. String values are kept
+// as they are; other values (e.g. a list of similar species) are kept
+// as compact JSON. Keys that were not asked for are ignored. Returns an
+// error naming the missing keys if any are absent or empty.
+func ParseAnswers(content string, keys []string) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(StripCodeFence(content)), &raw); err != nil {
+		return nil, fmt.Errorf("invalid multi-answer JSON: %w", err)
+	}
+
+	answers := make(map[string]string, len(keys))
+	var missing []string
+	for _, key := range keys {
+		value, ok := raw[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+
+		answer := answerText(value)
+		if answer == "" {
+			missing = append(missing, key)
+			continue
+		}
+		answers[key] = answer
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("answer is missing %s", strings.Join(missing, ", "))
+	}
+
+	return answers, nil
+}
+
+// answerText returns a JSON value as display text
+func answerText(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+
+	text := strings.TrimSpace(string(value))
+	if text == "null" {
+		return ""
+	}
+	return text
+}
+
+// StripCodeFence removes a Markdown code fence around a model answer
+//
+// Models often wrap JSON in a fence even when asked for JSON only.
+// Content without a fence is returned trimmed.
+func StripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+
+	// Drop the opening fence line (which may name a language)
+	i := strings.Index(content, "\n")
+	if i < 0 {
+		return ""
+	}
+
+	content = strings.TrimSpace(content[i+1:])
+	content = strings.TrimSuffix(content, "```")
+	return strings.TrimSpace(content)
+}
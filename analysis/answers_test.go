@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAnswers(t *testing.T) {
+	keys := []string{"species", "edibility", "habitat"}
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "plain JSON",
+			content: `{"species": "Chanterelle (Cantharellus cibarius)", "edibility": "Edible", "habitat": "Mossy woodland"}`,
+			want: map[string]string{
+				"species":   "Chanterelle (Cantharellus cibarius)",
+				"edibility": "Edible",
+				"habitat":   "Mossy woodland",
+			},
+		},
+		{
+			name:    "fenced with extra keys and a list",
+			content: "```json\n{\"species\": \" Fly agaric \", \"edibility\": \"Poisonous\", \"habitat\": [\"birch\", \"pine\"], \"notes\": \"x\"}\n```",
+			want: map[string]string{
+				"species":   "Fly agaric",
+				"edibility": "Poisonous",
+				"habitat":   `["birch", "pine"]`,
+			},
+		},
+		{
+			name:    "missing and empty keys",
+			content: `{"species": "Porcini", "edibility": ""}`,
+			wantErr: "answer is missing edibility, habitat",
+		},
+		{
+			name:    "null value",
+			content: `{"species": "Porcini", "edibility": null, "habitat": "Oak"}`,
+			wantErr: "answer is missing edibility",
+		},
+		{
+			name:    "not JSON",
+			content: "It is a chanterelle.",
+			wantErr: "invalid multi-answer JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAnswers(tt.content, keys)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d answers, want %d", len(got), len(tt.want))
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := map[string]string{
+		"{\"a\": 1}":               "{\"a\": 1}",
+		"  {\"a\": 1}\n":           "{\"a\": 1}",
+		"```json\n{\"a\": 1}\n```": "{\"a\": 1}",
+		"```\n{\"a\": 1}\n```\n\n": "{\"a\": 1}",
+		"```json":                  "",
+		"```json\n{\"a\": 1}":      "{\"a\": 1}",
+	}
+	for content, want := range tests {
+		if got := StripCodeFence(content); got != want {
+			t.Errorf("StripCodeFence(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
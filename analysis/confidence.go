@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Confidence is a normalized confidence level of an identification
+type Confidence string
+
+const (
+	// High means the model is confident in the identification
+	High Confidence = "High"
+
+	// Medium means the identification is plausible but uncertain
+	Medium Confidence = "Medium"
+
+	// Low means the identification is a guess
+	Low Confidence = "Low"
+
+	// Unknown means no confidence level could be found
+	Unknown Confidence = "Unknown"
+)
+
+// confidencePattern finds the level or percentage stated after a
+// "Confidence" label, allowing markdown and a short lead-in such as
+// "Level: I'd say"
+var confidencePattern = regexp.MustCompile(`(?i)confidence[^\n]{0,80}?(?:\b(high|medium|moderate|low)\b|\b(\d{1,3})\s*%)`)
+
+// Percentages at or above which a confidence counts as High and Medium
+const (
+	highPercent   = 75
+	mediumPercent = 40
+)
+
+// ParseConfidence returns the confidence level stated in an answer
+//
+// Looks for the first "Confidence" label followed on the same line by
+// High, Medium (or Moderate) or Low, in any case and with or without
+// markdown, e.g. "**Confidence**: medium". A percentage such as "85%"
+// is mapped to a level too. Returns Unknown if none is found.
+func ParseConfidence(content string) Confidence {
+	m := confidencePattern.FindStringSubmatch(content)
+	if m == nil {
+		return Unknown
+	}
+
+	if m[2] != "" {
+		percent, err := strconv.Atoi(m[2])
+		switch {
+		case err != nil || percent > 100:
+			return Unknown
+		case percent >= highPercent:
+			return High
+		case percent >= mediumPercent:
+			return Medium
+		default:
+			return Low
+		}
+	}
+
+	switch strings.ToLower(m[1]) {
+	case "high":
+		return High
+	case "medium", "moderate":
+		return Medium
+	default:
+		return Low
+	}
+}
@@ -0,0 +1,31 @@
+package analysis
+
+import "testing"
+
+func TestParseConfidence(t *testing.T) {
+	tests := []struct {
+		content string
+		want    Confidence
+	}{
+		{"**Confidence**: medium", Medium},
+		{"2. **Confidence Level**: High", High},
+		{"Confidence: LOW - the gills are not visible", Low},
+		{"**Confidence Level:** Moderate", Medium},
+		{"Confidence level: I'd say high, given the ring and volva", High},
+		{"Confidence: 85%", High},
+		{"**Confidence**: about 50 %", Medium},
+		{"Confidence: 20%", Low},
+		{"Confidence: 75%", High},
+		{"Confidence: 40%", Medium},
+		{"Confidence: 150%", Unknown},
+		{"1. **Species**: Chanterelle\n2. **Confidence**: Medium\n3. **Edibility**: Edible (low risk)", Medium},
+		{"**Confidence**:\nHigh", Unknown},
+		{"The cap is low and the stem is high.", Unknown},
+		{"", Unknown},
+	}
+	for _, tt := range tests {
+		if got := ParseConfidence(tt.content); got != tt.want {
+			t.Errorf("ParseConfidence(%q) = %s, want %s", tt.content, got, tt.want)
+		}
+	}
+}
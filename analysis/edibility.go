@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"regexp"
+	"strings"
+)
+
+// edibilityLabel matches an "Edibility" label, e.g. "4. **Edibility**:",
+// and captures the text after it on the same line
+var edibilityLabel = regexp.MustCompile(`(?i)^[\s#>*_\-\d.]*edibility[*_]*\s*[:\-–]?\s*[*_]*\s*(.*)$`)
+
+// ParseEdibility returns the edibility statement of an answer
+//
+// Finds the line labeled "Edibility" and returns the text after the
+// label, or the next non-empty line if the label stands on its own (as
+// a heading would). Markdown emphasis is removed. Returns an empty
+// string if there is no such label.
+func ParseEdibility(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		m := edibilityLabel.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		if text := cleanStatement(m[1]); text != "" {
+			return text
+		}
+		for _, next := range lines[i+1:] {
+			if text := cleanStatement(next); text != "" {
+				return text
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// cleanStatement strips list markers and emphasis from a line of text
+func cleanStatement(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, "-*> ")
+	s = strings.NewReplacer("**", "", "__", "").Replace(s)
+	return strings.TrimSpace(s)
+}
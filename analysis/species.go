@@ -0,0 +1,52 @@
+// Package analysis extracts structured facts from classification answers
+package analysis
+
+import (
+	"regexp"
+	"strings"
+)
+
+// binomial matches a scientific name such as "Amanita muscaria"
+const binomial = `([A-Z][a-z]+ (?:[a-z]+-)?[a-z]{2,})`
+
+// Patterns tried in order of reliability
+var (
+	// "Scientific name: Amanita muscaria", with optional markdown
+	labeledPattern = regexp.MustCompile(`(?i:scientific name)[^:\n]*:[\s*_]*` + binomial)
+
+	// "*Amanita muscaria*" or "_Amanita muscaria_"
+	italicPattern = regexp.MustCompile(`[*_]` + binomial + `[*_]`)
+
+	// "Fly Agaric (Amanita muscaria)"
+	parenthesizedPattern = regexp.MustCompile(`\(` + binomial + `\)`)
+)
+
+// ParseSpecies returns the scientific name identified in an answer
+//
+// Looks for an explicitly labeled scientific name first, then for the
+// first italicized or parenthesized binomial. Returns an empty string if
+// no scientific name is found.
+func ParseSpecies(content string) string {
+	for _, pattern := range []*regexp.Regexp{labeledPattern, italicPattern, parenthesizedPattern} {
+		if m := pattern.FindStringSubmatch(content); m != nil {
+			return NormalizeSpecies(m[1])
+		}
+	}
+	return ""
+}
+
+// NormalizeSpecies puts a scientific name in canonical form
+//
+// Collapses whitespace and capitalizes only the genus, so that names
+// written differently by the model compare equal.
+func NormalizeSpecies(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return ""
+	}
+	for i, f := range fields {
+		fields[i] = strings.ToLower(f)
+	}
+	fields[0] = strings.ToUpper(fields[0][:1]) + fields[0][1:]
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,116 @@
+// Package annotate provides drawing utilities for overlaying analysis results on images
+package annotate
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// FeatureBox is an approximate bounding box for a mushroom feature
+//
+// Coordinates are normalized to the image size: (0,0) is the top-left
+// corner and (1,1) the bottom-right, so boxes are independent of the
+// resolution the model saw.
+type FeatureBox struct {
+	// Feature name (e.g. "cap", "gills", "stem")
+	Label string `json:"label"`
+
+	// Left edge as a fraction of the image width
+	X float64 `json:"x"`
+
+	// Top edge as a fraction of the image height
+	Y float64 `json:"y"`
+
+	// Box width as a fraction of the image width
+	Width float64 `json:"width"`
+
+	// Box height as a fraction of the image height
+	Height float64 `json:"height"`
+}
+
+// Clamp returns the box restricted to the unit square
+//
+// Negative or out-of-range origins are moved inside the image and the
+// size is reduced so the box does not extend past the right or bottom edge.
+func (b FeatureBox) Clamp() FeatureBox {
+	x0 := clampUnit(b.X)
+	y0 := clampUnit(b.Y)
+	x1 := clampUnit(b.X + b.Width)
+	y1 := clampUnit(b.Y + b.Height)
+
+	return FeatureBox{
+		Label:  b.Label,
+		X:      x0,
+		Y:      y0,
+		Width:  math.Max(0, x1-x0),
+		Height: math.Max(0, y1-y0),
+	}
+}
+
+// clampUnit restricts a value to the range [0, 1]
+func clampUnit(v float64) float64 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return math.Min(1, math.Max(0, v))
+}
+
+// featureColors assigns a distinct outline colour to common features
+var featureColors = map[string]color.RGBA{
+	"cap":   {R: 230, G: 57, B: 70, A: 255},
+	"gills": {R: 69, G: 123, B: 157, A: 255},
+	"stem":  {R: 42, G: 157, B: 143, A: 255},
+}
+
+// defaultColor is used for features without an assigned colour
+var defaultColor = color.RGBA{R: 244, G: 162, B: 97, A: 255}
+
+// DrawBoxes returns a copy of img with the feature boxes outlined
+//
+// Boxes are clamped to the image before drawing. The outline thickness
+// scales with the image size so it stays visible on large photos.
+func DrawBoxes(img image.Image, boxes []FeatureBox) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	draw.Draw(out, bounds, img, bounds.Min, draw.Src)
+
+	thickness := max(2, min(bounds.Dx(), bounds.Dy())/200)
+
+	for _, box := range boxes {
+		box = box.Clamp()
+		if box.Width == 0 || box.Height == 0 {
+			continue
+		}
+
+		rect := image.Rect(
+			bounds.Min.X+int(box.X*float64(bounds.Dx())),
+			bounds.Min.Y+int(box.Y*float64(bounds.Dy())),
+			bounds.Min.X+int((box.X+box.Width)*float64(bounds.Dx())),
+			bounds.Min.Y+int((box.Y+box.Height)*float64(bounds.Dy())),
+		)
+
+		c, ok := featureColors[box.Label]
+		if !ok {
+			c = defaultColor
+		}
+		drawOutline(out, rect, thickness, c)
+	}
+
+	return out
+}
+
+// drawOutline draws a rectangle border of the given thickness
+func drawOutline(dst draw.Image, r image.Rectangle, thickness int, c color.Color) {
+	src := image.NewUniform(c)
+	edges := []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+thickness),
+		image.Rect(r.Min.X, r.Max.Y-thickness, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+thickness, r.Max.Y),
+		image.Rect(r.Max.X-thickness, r.Min.Y, r.Max.X, r.Max.Y),
+	}
+	for _, edge := range edges {
+		draw.Draw(dst, edge.Intersect(r), src, image.Point{}, draw.Over)
+	}
+}
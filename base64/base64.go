@@ -3,10 +3,41 @@ package base64
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 )
 
+// ErrImageTooLarge matches errors for images over the size limit
+var ErrImageTooLarge = errors.New("image too large")
+
+// SizeError reports an image file that exceeds the size limit
+//
+// It matches ErrImageTooLarge with errors.Is.
+type SizeError struct {
+	// Path of the image file
+	File string
+
+	// Size of the file in bytes
+	Size int64
+
+	// Maximum allowed size in bytes
+	Limit int64
+}
+
+// Error returns a description of the size and limit
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("%s is %s, over the %s limit",
+		filepath.Base(e.File), FormatBytes(e.Size), FormatBytes(e.Limit))
+}
+
+// Is reports whether target is ErrImageTooLarge
+func (e *SizeError) Is(target error) bool {
+	return target == ErrImageTooLarge
+}
+
 // EncodeData encodes binary data to Base64 string
 //
 // Converts binary data to Base64 encoded string following RFC 4648.
@@ -15,24 +46,161 @@ func EncodeData(data []byte) string {
 	return base64.StdEncoding.EncodeToString(data)
 }
 
-// ReadImageToBase64 reads an image file and encodes it as Base64
+// DecodeData decodes a Base64 string produced by EncodeData
 //
-// Opens the specified image file in binary mode, reads its entire contents,
-// and returns a Base64 encoded representation. This is commonly used for
-// embedding images in JSON requests to vision APIs.
-func ReadImageToBase64(filename string) (string, error) {
+// Only the standard, padded alphabet is accepted; use DecodeDataURL for
+// pasted text that may be in other forms.
+func DecodeData(s string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 data: %w", err)
+	}
+
+	return data, nil
+}
+
+// DecodeToFile decodes a Base64 string and writes the bytes to filename
+//
+// Nothing is written if s is not valid Base64. An existing file is
+// replaced.
+func DecodeToFile(s, filename string) error {
+	data, err := DecodeData(s)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// ReadImage reads an image file into memory
+//
+// Opens the specified image file in binary mode and returns its entire
+// contents. Empty files are rejected since they cannot hold an image.
+func ReadImage(filename string) ([]byte, error) {
 	// Read the entire file
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
 	}
 
 	// Check if file is empty
 	if len(data) == 0 {
-		return "", fmt.Errorf("file %s is empty", filename)
+		return nil, fmt.Errorf("file %s is empty", filename)
+	}
+
+	return data, nil
+}
+
+// ReadImageWithLimit reads an image file no larger than maxBytes
+//
+// The size is checked with os.Stat before reading, so oversized files
+// are rejected without loading them; the returned *SizeError matches
+// ErrImageTooLarge. A non-positive maxBytes disables the check.
+func ReadImageWithLimit(filename string, maxBytes int64) ([]byte, error) {
+	if maxBytes > 0 {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+		}
+		if info.Size() > maxBytes {
+			return nil, &SizeError{File: filename, Size: info.Size(), Limit: maxBytes}
+		}
+	}
+
+	return ReadImage(filename)
+}
+
+// ReadImageFrom reads an image from r, e.g. standard input
+//
+// Like ReadImageWithLimit for data without a file: name is only used in
+// messages, and at most maxBytes are read before the image is rejected
+// with a *SizeError (no limit if maxBytes is not positive). Empty input
+// is rejected.
+func ReadImageFrom(r io.Reader, name string, maxBytes int64) ([]byte, error) {
+	limited := r
+	if maxBytes > 0 {
+		limited = io.LimitReader(r, maxBytes+1)
+	}
+	data, err := io.ReadAll(limited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		// Count the rest without keeping it, for an accurate message
+		rest, _ := io.Copy(io.Discard, r)
+		return nil, &SizeError{File: name, Size: int64(len(data)) + rest, Limit: maxBytes}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+
+	return data, nil
+}
+
+// FormatBytes formats a byte count for messages, e.g. "2.4 MB"
+func FormatBytes(n int64) string {
+	const kb, mb = 1024, 1024 * 1024
+	switch {
+	case n >= mb:
+		return fmt.Sprintf("%.1f MB", float64(n)/mb)
+	case n >= kb:
+		return fmt.Sprintf("%d KB", n/kb)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+// ReadImageToBase64 reads an image file and encodes it as Base64
+//
+// Opens the specified image file in binary mode, reads its entire contents,
+// and returns a Base64 encoded representation. This is commonly used for
+// embedding images in JSON requests to vision APIs. Files that are not
+// images in a supported format are rejected (see ValidateImage).
+func ReadImageToBase64(filename string) (string, error) {
+	data, err := ReadImage(filename)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateImage(data); err != nil {
+		return "", fmt.Errorf("%s: %w", filename, err)
 	}
 
 	// Encode to base64
 	encoded := EncodeData(data)
 	return encoded, nil
+}
+
+// ReaderToBase64 reads an image from r and encodes it as Base64
+//
+// Like ReadImageToBase64 for data without a file name, such as an image
+// piped to standard input. The format is detected from the data itself.
+func ReaderToBase64(r io.Reader) (string, error) {
+	data, err := ReadImageFrom(r, "input", 0)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateImage(data); err != nil {
+		return "", err
+	}
+
+	return EncodeData(data), nil
+}
+
+// ReadImageToBase64WithLimit is like ReadImageToBase64 but rejects files
+// larger than maxBytes (see ReadImageWithLimit)
+func ReadImageToBase64WithLimit(filename string, maxBytes int64) (string, error) {
+	data, err := ReadImageWithLimit(filename, maxBytes)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateImage(data); err != nil {
+		return "", fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return EncodeData(data), nil
 }
\ No newline at end of file
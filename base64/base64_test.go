@@ -0,0 +1,150 @@
+package base64
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeData(t *testing.T) {
+	for _, data := range [][]byte{{}, {0}, []byte("ab"), encodePNG(t, 3, 2)} {
+		got, err := DecodeData(EncodeData(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("round trip of %d bytes gave %d bytes", len(data), len(got))
+		}
+	}
+
+	// Only the padded standard alphabet is accepted
+	for _, s := range []string{"not base64!", "YWI", "-_8=", "data:image/png;base64,YWI="} {
+		if _, err := DecodeData(s); err == nil {
+			t.Errorf("DecodeData(%q): want an error", s)
+		}
+	}
+}
+
+func TestDecodeToFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.png")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := encodePNG(t, 3, 2)
+	if err := DecodeToFile(EncodeData(data), path); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Errorf("file holds %d bytes, want the %d decoded ones", len(got), len(data))
+	}
+
+	// Invalid input writes nothing
+	invalid := filepath.Join(dir, "invalid.png")
+	if err := DecodeToFile("not base64!", invalid); err == nil {
+		t.Error("want an error for invalid base64")
+	}
+	if _, err := os.Stat(invalid); !os.IsNotExist(err) {
+		t.Errorf("file written for invalid input: %v", err)
+	}
+}
+
+func TestReaderToBase64(t *testing.T) {
+	data := encodePNG(t, 3, 2)
+	encoded, err := ReaderToBase64(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != EncodeData(data) {
+		t.Errorf("got %q, want the base64 of the image", encoded)
+	}
+
+	for name, input := range map[string][]byte{"empty": {}, "text": []byte("not an image")} {
+		if _, err := ReaderToBase64(bytes.NewReader(input)); err == nil {
+			t.Errorf("%s input: want an error", name)
+		}
+	}
+}
+
+func TestReadImageFromLimit(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, 100)
+	if got, err := ReadImageFrom(bytes.NewReader(data), "stdin", 100); err != nil || len(got) != 100 {
+		t.Errorf("at the limit: got %d bytes, %v", len(got), err)
+	}
+
+	_, err := ReadImageFrom(bytes.NewReader(data), "stdin", 40)
+	var sizeErr *SizeError
+	if !errors.As(err, &sizeErr) || !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("err = %v, want a *SizeError", err)
+	}
+	if sizeErr.Size != 100 || sizeErr.Limit != 40 || sizeErr.File != "stdin" {
+		t.Errorf("got %+v, want the full size of the input", sizeErr)
+	}
+}
+
+// writeTemp writes data to a file in a temporary directory
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadImageWithLimit(t *testing.T) {
+	path := writeTemp(t, "photo.jpg", bytes.Repeat([]byte{1}, 2048))
+
+	for _, limit := range []int64{0, -1, 2048, 4096} {
+		if data, err := ReadImageWithLimit(path, limit); err != nil || len(data) != 2048 {
+			t.Errorf("limit %d: got %d bytes, %v", limit, len(data), err)
+		}
+	}
+
+	_, err := ReadImageWithLimit(path, 1024)
+	var sizeErr *SizeError
+	if !errors.As(err, &sizeErr) || !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("err = %v, want a *SizeError", err)
+	}
+	if sizeErr.Size != 2048 || sizeErr.Limit != 1024 {
+		t.Errorf("got %+v", sizeErr)
+	}
+	if want := "photo.jpg is 2 KB, over the 1 KB limit"; err.Error() != want {
+		t.Errorf("message %q, want %q", err, want)
+	}
+
+	if _, err := ReadImageWithLimit(filepath.Join(t.TempDir(), "missing.jpg"), 1024); err == nil || errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("missing file: err = %v, want a read error", err)
+	}
+}
+
+func TestReadImageToBase64WithLimit(t *testing.T) {
+	png := encodePNG(t, 3, 2)
+	encoded, err := ReadImageToBase64WithLimit(writeTemp(t, "cap.png", png), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != EncodeData(png) {
+		t.Error("got a different encoding of the PNG")
+	}
+
+	tests := map[string][]byte{
+		"notes.txt":  []byte("Found under beech trees, white spore print."),
+		"empty.jpg":  {},
+		"large.png":  append(png, make([]byte, 2048)...),
+		"binary.bin": {0x00, 0x01, 0x02, 0x03},
+	}
+	for name, data := range tests {
+		if _, err := ReadImageToBase64WithLimit(writeTemp(t, name, data), 1024); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+
+	// The same checks apply without a limit
+	if _, err := ReadImageToBase64(writeTemp(t, "notes.txt", tests["notes.txt"])); err == nil {
+		t.Error("text file: want an error without a limit too")
+	}
+}
@@ -0,0 +1,154 @@
+package base64
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DetectMimeType sniffs the MIME type of image data
+//
+// Uses http.DetectContentType, which inspects at most the first 512
+// bytes. Recognizes JPEG, PNG, GIF, WebP and BMP among others; returns
+// "application/octet-stream" for unknown data.
+func DetectMimeType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// EncodeDataURI encodes data as a data: URI with its detected MIME type
+func EncodeDataURI(data []byte) string {
+	return fmt.Sprintf("data:%s;base64,%s", DetectMimeType(data), EncodeData(data))
+}
+
+// ReadImageToDataURI reads an image file and encodes it as a data: URI
+//
+// The MIME type is sniffed from the file contents rather than assumed,
+// producing e.g. "data:image/png;base64,..." for PNG files. Files that
+// are not images in a supported format are rejected.
+func ReadImageToDataURI(filename string) (string, error) {
+	data, err := ReadImage(filename)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateImage(data); err != nil {
+		return "", fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return EncodeDataURI(data), nil
+}
+
+// DecodeDataURL decodes an image from a data: URL or raw base64 text
+//
+// Accepts "data:image/png;base64,..." as well as bare base64 (standard or
+// URL alphabet, padded or not). Whitespace inside the payload is ignored.
+// Returns the decoded bytes and their MIME type, sniffed from the data
+// rather than trusted from the URL. Fails if the text is not base64 or
+// does not decode to an image.
+func DecodeDataURL(s string) ([]byte, string, error) {
+	payload := strings.TrimSpace(s)
+
+	// Strip the data: URL header if present
+	if hasDataScheme(payload) {
+		var err error
+		_, payload, err = splitDataURI(payload)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Drop line breaks and spaces introduced by copying
+	payload = compactBase64(payload)
+	if payload == "" {
+		return nil, "", fmt.Errorf("no image data found")
+	}
+
+	data, err := decodeAnyBase64(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+
+	mimeType := DetectMimeType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, "", fmt.Errorf("data is not an image (detected %s)", mimeType)
+	}
+
+	return data, mimeType, nil
+}
+
+// ParseDataURI splits a data: URI into its MIME type and decoded payload
+//
+// Only base64 data URIs such as "data:image/png;base64,..." are accepted.
+// The MIME type is returned as declared, without parameters like charset,
+// and defaults to "text/plain" when omitted, as in RFC 2397. Unlike
+// DecodeDataURL the payload is not required to be an image.
+func ParseDataURI(s string) (string, []byte, error) {
+	s = strings.TrimSpace(s)
+	if !hasDataScheme(s) {
+		return "", nil, fmt.Errorf("not a data URI: missing data: scheme")
+	}
+
+	mimeType, payload, err := splitDataURI(s)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := decodeAnyBase64(compactBase64(payload))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid base64 data: %w", err)
+	}
+
+	return mimeType, data, nil
+}
+
+// hasDataScheme reports whether s starts with the data: scheme
+func hasDataScheme(s string) bool {
+	return len(s) >= 5 && strings.EqualFold(s[:5], "data:")
+}
+
+// splitDataURI separates the MIME type and payload of a base64 data: URI
+func splitDataURI(s string) (string, string, error) {
+	comma := strings.Index(s, ",")
+	if comma < 0 {
+		return "", "", fmt.Errorf("malformed data URL: missing ','")
+	}
+
+	params := strings.Split(s[len("data:"):comma], ";")
+	if len(params) < 2 || !strings.EqualFold(strings.TrimSpace(params[len(params)-1]), "base64") {
+		return "", "", fmt.Errorf("data URL is not base64 encoded")
+	}
+
+	mimeType := strings.ToLower(strings.TrimSpace(params[0]))
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+
+	return mimeType, s[comma+1:], nil
+}
+
+// compactBase64 drops line breaks and spaces from base64 text
+func compactBase64(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// decodeAnyBase64 decodes base64 in any of the common alphabets
+func decodeAnyBase64(s string) ([]byte, error) {
+	encodings := []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	}
+
+	var firstErr error
+	for _, enc := range encodings {
+		data, err := enc.DecodeString(s)
+		if err == nil {
+			return data, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
@@ -0,0 +1,88 @@
+package base64
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// Byte fixtures holding just the magic numbers the formats are sniffed by
+var (
+	pngFixture  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpegFixture = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	gifFixture  = []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	webpFixture = []byte("RIFF\x24\x00\x00\x00WEBPVP8 ")
+)
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"PNG", pngFixture, "image/png"},
+		{"JPEG", jpegFixture, "image/jpeg"},
+		{"GIF", gifFixture, "image/gif"},
+		{"WebP", webpFixture, "image/webp"},
+		{"unknown", []byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := DetectMimeType(tt.data); got != tt.want {
+			t.Errorf("%s: DetectMimeType = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDataURI(t *testing.T) {
+	uri := EncodeDataURI(pngFixture)
+	if !strings.HasPrefix(uri, "data:image/png;base64,") {
+		t.Errorf("EncodeDataURI = %q", uri)
+	}
+}
+
+func TestDecodeDataURL(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(jpegFixture)
+
+	for _, input := range []string{
+		"data:image/png;base64," + encoded, // declared type is not trusted
+		encoded,
+		"  " + encoded[:8] + "\n" + encoded[8:] + "\n",
+		base64.RawURLEncoding.EncodeToString(jpegFixture),
+	} {
+		data, mimeType, err := DecodeDataURL(input)
+		if err != nil {
+			t.Errorf("DecodeDataURL(%q): %v", input, err)
+			continue
+		}
+		if mimeType != "image/jpeg" || string(data) != string(jpegFixture) {
+			t.Errorf("DecodeDataURL(%q) = %q, %q", input, data, mimeType)
+		}
+	}
+
+	for _, input := range []string{
+		"",
+		"not base64!",
+		"data:image/png," + encoded,
+		base64.StdEncoding.EncodeToString([]byte("plain text")),
+	} {
+		if _, _, err := DecodeDataURL(input); err == nil {
+			t.Errorf("DecodeDataURL(%q) succeeded", input)
+		}
+	}
+}
+
+func TestParseDataURI(t *testing.T) {
+	mimeType, data, err := ParseDataURI("data:Text/Plain;charset=utf-8;base64,aGVsbG8=")
+	if err != nil || mimeType != "text/plain" || string(data) != "hello" {
+		t.Errorf("got %q, %q, %v", mimeType, data, err)
+	}
+
+	mimeType, _, err = ParseDataURI("data:;base64,aGVsbG8=")
+	if err != nil || mimeType != "text/plain" {
+		t.Errorf("default type = %q, %v", mimeType, err)
+	}
+
+	if _, _, err := ParseDataURI("aGVsbG8="); err == nil {
+		t.Error("URI without scheme accepted")
+	}
+}
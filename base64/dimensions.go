@@ -0,0 +1,54 @@
+package base64
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+)
+
+// DimensionError reports an image with more pixels than allowed
+//
+// It matches ErrImageTooLarge with errors.Is.
+type DimensionError struct {
+	// Width of the image in pixels
+	Width int
+
+	// Height of the image in pixels
+	Height int
+
+	// Maximum allowed number of pixels
+	Limit int64
+}
+
+// Error returns a description of the dimensions and limit
+func (e *DimensionError) Error() string {
+	return fmt.Sprintf("image is %dx%d pixels (%.1f megapixels), over the %.1f megapixel limit",
+		e.Width, e.Height, float64(e.Width)*float64(e.Height)/1e6, float64(e.Limit)/1e6)
+}
+
+// Is reports whether target is ErrImageTooLarge
+func (e *DimensionError) Is(target error) bool {
+	return target == ErrImageTooLarge
+}
+
+// CheckDimensions rejects images with more than maxPixels pixels
+//
+// Only the image header is read, so a small file that would decode to a
+// huge bitmap is refused before any memory is allocated for it; the
+// returned *DimensionError matches ErrImageTooLarge. A non-positive
+// maxPixels disables the check.
+func CheckDimensions(data []byte, maxPixels int64) error {
+	if maxPixels <= 0 {
+		return nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return &DimensionError{Width: cfg.Width, Height: cfg.Height, Limit: maxPixels}
+	}
+	return nil
+}
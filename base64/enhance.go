@@ -0,0 +1,80 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// enhanceTargetLuminance is the mean luminance, as a fraction of full
+// brightness, that dark images are brightened to
+const enhanceTargetLuminance = 0.45
+
+// MeanLuminance returns the average brightness of an image
+//
+// The result is a fraction between 0 (black) and 1 (white). Large images
+// are sampled on the same grid as EstimateSharpness.
+func MeanLuminance(img image.Image) float64 {
+	b := img.Bounds()
+	if b.Empty() {
+		return 0
+	}
+	step := max(1, max(b.Dx(), b.Dy())/sharpnessSampleSize)
+
+	var sum float64
+	n := 0
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			c := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			sum += float64(c.Y)
+			n++
+		}
+	}
+
+	return sum / float64(n) / 255
+}
+
+// AutoEnhanceIfDark brightens an underexposed image
+//
+// If the mean luminance of img is below threshold (a fraction between 0
+// and 1), returns a copy with a gamma curve applied so the mean is
+// lifted to about enhanceTargetLuminance. Blacks stay black and whites
+// stay white, so shadows open up without clipping highlights. Images at
+// or above the threshold, and non-positive thresholds, return the image
+// unchanged.
+func AutoEnhanceIfDark(img image.Image, threshold float64) image.Image {
+	if threshold <= 0 {
+		return img
+	}
+
+	mean := MeanLuminance(img)
+	if mean >= threshold || mean >= enhanceTargetLuminance {
+		return img
+	}
+
+	// Solve mean^gamma = target; a nearly black image would otherwise
+	// get an extreme curve
+	mean = math.Max(mean, 1.0/255)
+	gamma := math.Log(enhanceTargetLuminance) / math.Log(mean)
+
+	var curve [256]uint8
+	for i := range curve {
+		curve[i] = uint8(math.Round(255 * math.Pow(float64(i)/255, gamma)))
+	}
+
+	b := img.Bounds()
+	out := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			out.SetNRGBA(x, y, color.NRGBA{
+				R: curve[c.R],
+				G: curve[c.G],
+				B: curve[c.B],
+				A: c.A,
+			})
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,56 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// grayRamp returns an image with luminance rising from low to high
+// across its width
+func grayRamp(low, high uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 64, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetGray(x, y, color.Gray{Y: low + uint8(int(high-low)*x/63)})
+		}
+	}
+	return img
+}
+
+func TestAutoEnhanceIfDark(t *testing.T) {
+	dark := grayRamp(0, 80)
+	before := MeanLuminance(dark)
+
+	out := AutoEnhanceIfDark(dark, 0.25)
+	after := MeanLuminance(out)
+	if after <= before || math.Abs(after-enhanceTargetLuminance) > 0.1 {
+		t.Errorf("mean luminance %.2f -> %.2f, want about %.2f", before, after, enhanceTargetLuminance)
+	}
+	if c := color.GrayModel.Convert(out.At(0, 0)).(color.Gray); c.Y != 0 {
+		t.Errorf("black became %d", c.Y)
+	}
+
+	// The darkest to brightest order of the pixels is kept
+	prev := -1
+	for x := 0; x < 64; x++ {
+		c := int(color.GrayModel.Convert(out.At(x, 0)).(color.Gray).Y)
+		if c < prev {
+			t.Fatalf("pixel %d darker than its left neighbour after enhancing", x)
+		}
+		prev = c
+	}
+}
+
+func TestAutoEnhanceIfDarkLeavesBrightImages(t *testing.T) {
+	bright := grayRamp(100, 250)
+	if out := AutoEnhanceIfDark(bright, 0.25); out != image.Image(bright) {
+		t.Error("bright image was changed")
+	}
+
+	dark := grayRamp(0, 80)
+	if out := AutoEnhanceIfDark(dark, 0); out != image.Image(dark) {
+		t.Error("image changed with enhancing disabled")
+	}
+}
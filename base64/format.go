@@ -0,0 +1,39 @@
+package base64
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+)
+
+// ErrUnsupportedFormat matches errors for data no image decoder accepts
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// SupportedFormat reports whether the image data can be decoded
+//
+// Only the image header is parsed, using the decoders registered in this
+// build. Returns the detected format name (e.g. "jpeg") and true when a
+// decoder is available, or an empty string and false otherwise.
+func SupportedFormat(data []byte) (string, bool) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+	return format, true
+}
+
+// ValidateImage checks that data is an image in a supported format
+//
+// Like SupportedFormat only the header is parsed. The returned error
+// matches ErrUnsupportedFormat with errors.Is and names the sniffed MIME
+// type, so picking e.g. a PDF is reported before anything is uploaded.
+func ValidateImage(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: no data", ErrUnsupportedFormat)
+	}
+	if _, ok := SupportedFormat(data); !ok {
+		return fmt.Errorf("%w (detected %s)", ErrUnsupportedFormat, DetectMimeType(data))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package base64
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"strings"
+	"testing"
+)
+
+func TestSupportedFormat(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+	}{
+		{"png", encodePNG(t, 8, 6), "png"},
+		{"jpeg", encodeJPEG(t, 8, 6), "jpeg"},
+		{"gif", gifData.Bytes(), "gif"},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 \x18\x00\x00\x00"), ""},
+		{"bmp", append([]byte("BM"), make([]byte, 60)...), ""},
+		{"pdf", []byte("%PDF-1.7\n"), ""},
+		{"truncated png", encodePNG(t, 8, 6)[:12], ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		format, ok := SupportedFormat(tt.data)
+		if format != tt.format || ok != (tt.format != "") {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, format, ok, tt.format)
+		}
+
+		err := ValidateImage(tt.data)
+		if tt.format != "" && err != nil {
+			t.Errorf("%s: ValidateImage: %v", tt.name, err)
+		}
+		if tt.format == "" && !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("%s: ValidateImage = %v, want ErrUnsupportedFormat", tt.name, err)
+		}
+	}
+}
+
+func TestValidateImageNamesDetectedType(t *testing.T) {
+	err := ValidateImage([]byte("%PDF-1.7\n"))
+	if err == nil || !strings.Contains(err.Error(), "application/pdf") {
+		t.Errorf("err = %v, want the detected MIME type", err)
+	}
+}
@@ -0,0 +1,42 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Letterbox pads an image with bars to reach a target aspect ratio
+//
+// The ratio is width divided by height (e.g. 4.0/3.0). The original image
+// is drawn unscaled in the center of a canvas filled with bg, so nothing is
+// cropped. Images already at the target ratio are returned unchanged, as
+// are images for a non-positive ratio.
+func Letterbox(img image.Image, ratio float64, bg color.Color) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if ratio <= 0 || width == 0 || height == 0 {
+		return img
+	}
+
+	// Grow whichever dimension is too short for the target ratio
+	newWidth, newHeight := width, height
+	if float64(width)/float64(height) > ratio {
+		newHeight = int(math.Round(float64(width) / ratio))
+	} else {
+		newWidth = int(math.Round(float64(height) * ratio))
+	}
+	if newWidth == width && newHeight == height {
+		return img
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	draw.Draw(out, out.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	// Center the original on the canvas
+	offset := image.Pt((newWidth-width)/2, (newHeight-height)/2)
+	draw.Draw(out, bounds.Sub(bounds.Min).Add(offset), img, bounds.Min, draw.Src)
+
+	return out
+}
@@ -0,0 +1,65 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// solidImage returns an image of the given size filled with c
+func solidImage(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestLetterbox(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	pad := color.RGBA{128, 128, 128, 255}
+
+	tests := []struct {
+		name          string
+		width, height int
+		ratio         float64
+		outW, outH    int
+		padAt         image.Point
+		imageAt       image.Point
+	}{
+		// Too wide for 4:3: bars above and below
+		{"wide", 200, 100, 4.0 / 3, 200, 150, image.Pt(100, 5), image.Pt(100, 75)},
+		// Too tall for 4:3: bars left and right
+		{"tall", 90, 120, 4.0 / 3, 160, 120, image.Pt(5, 60), image.Pt(80, 60)},
+		{"square", 100, 100, 1.5, 150, 100, image.Pt(10, 50), image.Pt(75, 50)},
+	}
+	for _, tt := range tests {
+		out := Letterbox(solidImage(tt.width, tt.height, red), tt.ratio, pad)
+
+		b := out.Bounds()
+		if b.Dx() != tt.outW || b.Dy() != tt.outH {
+			t.Errorf("%s: got %dx%d, want %dx%d", tt.name, b.Dx(), b.Dy(), tt.outW, tt.outH)
+			continue
+		}
+		if got := color.RGBAModel.Convert(out.At(tt.padAt.X, tt.padAt.Y)); got != pad {
+			t.Errorf("%s: bar at %v = %v, want %v", tt.name, tt.padAt, got, pad)
+		}
+		if got := color.RGBAModel.Convert(out.At(tt.imageAt.X, tt.imageAt.Y)); got != red {
+			t.Errorf("%s: center at %v = %v, want the image", tt.name, tt.imageAt, got)
+		}
+	}
+}
+
+func TestLetterboxUnchanged(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+
+	img := solidImage(160, 120, red)
+	if out := Letterbox(img, 4.0/3, LetterboxGrey); out != image.Image(img) {
+		t.Errorf("image at the target ratio was copied to %v", out.Bounds())
+	}
+	if out := Letterbox(img, 0, LetterboxGrey); out != image.Image(img) {
+		t.Error("image changed for a ratio of 0")
+	}
+}
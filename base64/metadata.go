@@ -0,0 +1,54 @@
+package base64
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// jpegQuality is the quality used when re-encoding JPEG images
+const jpegQuality = 95
+
+// StripMetadata removes embedded metadata from image data
+//
+// Decodes the image and re-encodes it in its original format. The standard
+// library encoders never write EXIF, XMP or other ancillary chunks, so GPS
+// coordinates, camera details and similar tags are dropped. Supports JPEG,
+// PNG and GIF input; GIF animations are reduced to their first frame.
+// The EXIF orientation is applied first, as it is lost with the tags.
+func StripMetadata(data []byte) ([]byte, error) {
+	img, format, err := DecodeOriented(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-encode in the original format
+	return EncodeImage(img, format)
+}
+
+// EncodeImage encodes an image in the named format
+//
+// Supports "jpeg", "png" and "gif", the format names reported by
+// image.Decode. The output never contains metadata.
+func EncodeImage(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		return nil, fmt.Errorf("unsupported image format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s image: %w", format, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package base64
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// withEXIF inserts an EXIF segment holding the given orientation right
+// after the start marker of JPEG data
+func withEXIF(t *testing.T, data []byte, orientation uint16) []byte {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		t.Fatal("not JPEG data")
+	}
+
+	// Little-endian TIFF header and an IFD with the orientation only
+	var tiff bytes.Buffer
+	tiff.WriteString("II")
+	binary.Write(&tiff, binary.LittleEndian, []uint16{42})
+	binary.Write(&tiff, binary.LittleEndian, []uint32{8})
+	binary.Write(&tiff, binary.LittleEndian, []uint16{1, exifOrientationTag, 3})
+	binary.Write(&tiff, binary.LittleEndian, []uint32{1})
+	binary.Write(&tiff, binary.LittleEndian, []uint16{orientation, 0})
+	binary.Write(&tiff, binary.LittleEndian, []uint32{0})
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{0xFF, 0xD8}, segment...)
+	return append(out, data[2:]...)
+}
+
+// encodeJPEG encodes a solid image of the given size as JPEG
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStripMetadataRemovesEXIF(t *testing.T) {
+	data := withEXIF(t, encodeJPEG(t, 40, 20), 1)
+	if exifData(data) == nil {
+		t.Fatal("fixture has no EXIF segment")
+	}
+
+	stripped, err := StripMetadata(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exifData(stripped) != nil || bytes.Contains(stripped, []byte("Exif\x00\x00")) {
+		t.Error("EXIF segment survived stripping")
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("stripped image does not decode: %v", err)
+	}
+	if format != "jpeg" || img.Bounds().Dx() != 40 || img.Bounds().Dy() != 20 {
+		t.Errorf("got %s image of %v, want a 40x20 jpeg", format, img.Bounds())
+	}
+	if r, _, _, _ := img.At(20, 10).RGBA(); r>>8 < 0x70 || r>>8 > 0x90 {
+		t.Errorf("pixel = %v, want mid gray", img.At(20, 10))
+	}
+}
+
+func TestStripMetadataAppliesOrientation(t *testing.T) {
+	// Orientation 6 must be applied before the tag is lost
+	stripped, err := StripMetadata(withEXIF(t, encodeJPEG(t, 40, 20), 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 20 || cfg.Height != 40 {
+		t.Errorf("got %dx%d, want the 20x40 upright image", cfg.Width, cfg.Height)
+	}
+}
@@ -0,0 +1,150 @@
+package base64
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+)
+
+// exifOrientationTag is the EXIF tag holding the orientation
+const exifOrientationTag = 0x0112
+
+// Orientation returns the EXIF orientation of JPEG data
+//
+// The value (1-8) tells how the stored pixels must be rotated or flipped
+// for display, as phone cameras save photos in sensor order and only
+// record how the phone was held. 1, the upright orientation, is returned
+// when there is no EXIF data or the tag is missing or invalid.
+func Orientation(data []byte) int {
+	tiff := exifData(data)
+	if tiff == nil || len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	// Look through the entries of the first IFD
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+	count := int64(order.Uint16(tiff[ifd:]))
+	for i := int64(0); i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > int64(len(tiff)) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		// A SHORT value is stored in the first bytes of the value field
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 1
+		}
+		return orientation
+	}
+	return 1
+}
+
+// exifData returns the TIFF structure of the EXIF segment of JPEG data
+//
+// Returns nil for other formats and JPEGs without EXIF data.
+func exifData(data []byte) []byte {
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return nil
+	}
+
+	// Walk the marker segments up to the image data
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan or end of image; no metadata follows
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return nil
+		}
+		payload := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return payload[6:]
+		}
+		pos += 2 + length
+	}
+	return nil
+}
+
+// Orient rotates and flips an image into its upright orientation
+//
+// orientation is an EXIF orientation value as returned by Orientation;
+// images with orientation 1 or an unknown value are returned unchanged.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Orientations 5-8 swap the sides
+	outWidth, outHeight := width, height
+	if orientation >= 5 {
+		outWidth, outHeight = height, width
+	}
+	out := image.NewRGBA(image.Rect(0, 0, outWidth, outHeight))
+
+	for y := 0; y < outHeight; y++ {
+		for x := 0; x < outWidth; x++ {
+			// Find the source pixel shown at (x, y)
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = width-1-x, y
+			case 3: // rotated 180°
+				sx, sy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, height-1-y
+			case 5: // mirrored along the top-left diagonal
+				sx, sy = y, x
+			case 6: // needs a 90° clockwise turn
+				sx, sy = y, height-1-x
+			case 7: // mirrored along the top-right diagonal
+				sx, sy = width-1-y, height-1-x
+			case 8: // needs a 90° counter-clockwise turn
+				sx, sy = width-1-y, x
+			}
+			out.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return out
+}
+
+// DecodeOriented decodes image data and puts it upright
+//
+// Like image.Decode, but applies the EXIF orientation of JPEG data so
+// photos taken with a turned phone are neither shown nor uploaded
+// sideways. Images without EXIF data are returned as decoded.
+func DecodeOriented(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	return Orient(img, Orientation(data)), format, nil
+}
@@ -0,0 +1,106 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{R: 0xFF, A: 0xFF}
+	green = color.RGBA{G: 0xFF, A: 0xFF}
+	blue  = color.RGBA{B: 0xFF, A: 0xFF}
+	white = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+)
+
+// corners returns the top-left, top-right, bottom-left and bottom-right
+// pixels of an image
+func corners(img image.Image) [4]color.RGBA {
+	b := img.Bounds()
+	at := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	}
+	return [4]color.RGBA{
+		at(b.Min.X, b.Min.Y), at(b.Max.X-1, b.Min.Y),
+		at(b.Min.X, b.Max.Y-1), at(b.Max.X-1, b.Max.Y-1),
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3×2 image with red, green, blue and white corners, as stored
+	src := solidImage(3, 2, color.RGBA{A: 0xFF})
+	src.SetRGBA(0, 0, red)
+	src.SetRGBA(2, 0, green)
+	src.SetRGBA(0, 1, blue)
+	src.SetRGBA(2, 1, white)
+
+	tests := []struct {
+		orientation int
+		wide        bool
+		want        [4]color.RGBA
+	}{
+		{1, true, [4]color.RGBA{red, green, blue, white}},
+		{2, true, [4]color.RGBA{green, red, white, blue}},
+		{3, true, [4]color.RGBA{white, blue, green, red}},
+		{4, true, [4]color.RGBA{blue, white, red, green}},
+		{5, false, [4]color.RGBA{red, blue, green, white}},
+		{6, false, [4]color.RGBA{blue, red, white, green}},
+		{7, false, [4]color.RGBA{white, green, blue, red}},
+		{8, false, [4]color.RGBA{green, white, red, blue}},
+		{0, true, [4]color.RGBA{red, green, blue, white}},
+		{9, true, [4]color.RGBA{red, green, blue, white}},
+	}
+	for _, tt := range tests {
+		got := Orient(src, tt.orientation)
+		wantSize := image.Pt(3, 2)
+		if !tt.wide {
+			wantSize = image.Pt(2, 3)
+		}
+		if size := got.Bounds().Size(); size != wantSize {
+			t.Errorf("orientation %d: size %v, want %v", tt.orientation, size, wantSize)
+			continue
+		}
+		if c := corners(got); c != tt.want {
+			t.Errorf("orientation %d: corners %v, want %v", tt.orientation, c, tt.want)
+		}
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	data := encodeJPEG(t, 4, 2)
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		if got := Orientation(withEXIF(t, data, orientation)); got != int(orientation) {
+			t.Errorf("Orientation = %d, want %d", got, orientation)
+		}
+	}
+
+	// Invalid values and missing EXIF data read as upright
+	for _, orientation := range []uint16{0, 9} {
+		if got := Orientation(withEXIF(t, data, orientation)); got != 1 {
+			t.Errorf("orientation %d: Orientation = %d, want 1", orientation, got)
+		}
+	}
+	if got := Orientation(data); got != 1 {
+		t.Errorf("JPEG without EXIF: Orientation = %d, want 1", got)
+	}
+	if got := Orientation(encodePNG(t, 4, 2)); got != 1 {
+		t.Errorf("PNG: Orientation = %d, want 1", got)
+	}
+}
+
+func TestDecodeOriented(t *testing.T) {
+	img, format, err := DecodeOriented(withEXIF(t, encodeJPEG(t, 4, 2), 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" {
+		t.Errorf("format = %q, want jpeg", format)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(2, 4) {
+		t.Errorf("size = %v, want the sides swapped to 2×4", size)
+	}
+
+	if _, _, err := DecodeOriented([]byte("not an image")); err == nil {
+		t.Error("want an error for data that is not an image")
+	}
+}
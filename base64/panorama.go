@@ -0,0 +1,94 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// tileOverlap is the minimum fraction of a tile shared with its neighbour,
+// so that features on a seam appear whole in at least one tile
+const tileOverlap = 0.1
+
+// AspectRatio returns the ratio of an image's longer side to its shorter
+//
+// The result is at least 1 for any non-empty image, whatever its
+// orientation. Empty images return 0.
+func AspectRatio(img image.Image) float64 {
+	b := img.Bounds()
+	short := min(b.Dx(), b.Dy())
+	if short <= 0 {
+		return 0
+	}
+	return float64(max(b.Dx(), b.Dy())) / float64(short)
+}
+
+// TileWide splits an extremely wide or tall image into overlapping tiles
+//
+// Images whose AspectRatio exceeds maxRatio are cut along their long
+// side into tiles with at most that ratio; neighbouring tiles overlap by
+// at least tileOverlap of a tile. Other images, and any image for a
+// maxRatio below 1, are returned as the only element.
+func TileWide(img image.Image, maxRatio float64) []image.Image {
+	if maxRatio < 1 || AspectRatio(img) <= maxRatio {
+		return []image.Image{img}
+	}
+
+	b := img.Bounds()
+	wide := b.Dx() > b.Dy()
+	long, short := b.Dx(), b.Dy()
+	if !wide {
+		long, short = short, long
+	}
+
+	// Longest tile within the ratio, spaced evenly so the last tile ends
+	// at the edge
+	size := max(1, int(float64(short)*maxRatio))
+	stride := float64(size) * (1 - tileOverlap)
+	n := int(math.Ceil(float64(long-size)/stride)) + 1
+
+	tiles := make([]image.Image, 0, n)
+	for i := 0; i < n; i++ {
+		start := i * (long - size) / (n - 1)
+		r := image.Rect(b.Min.X+start, b.Min.Y, b.Min.X+start+size, b.Max.Y)
+		if !wide {
+			r = image.Rect(b.Min.X, b.Min.Y+start, b.Max.X, b.Min.Y+start+size)
+		}
+		tiles = append(tiles, crop(img, r))
+	}
+	return tiles
+}
+
+// crop returns the part of img inside r
+//
+// Shares pixels with img when the image type supports it, and copies
+// them otherwise.
+func crop(img image.Image, r image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(out, out.Bounds(), img, r.Min, draw.Src)
+	return out
+}
+
+// FitAspect letterboxes an image whose AspectRatio exceeds maxRatio
+//
+// Bars of color bg are added to the short side until the ratio is
+// maxRatio, in either orientation. Other images, and any image for a
+// maxRatio below 1, are returned unchanged.
+func FitAspect(img image.Image, maxRatio float64, bg color.Color) image.Image {
+	if maxRatio < 1 || AspectRatio(img) <= maxRatio {
+		return img
+	}
+
+	b := img.Bounds()
+	if b.Dx() > b.Dy() {
+		return Letterbox(img, maxRatio, bg)
+	}
+	return Letterbox(img, 1/maxRatio, bg)
+}
@@ -0,0 +1,52 @@
+package base64
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTileWide(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		tiles         int
+		tileW, tileH  int
+	}{
+		{"wide", 1000, 100, 4, 300, 100},
+		{"tall", 100, 1000, 4, 100, 300},
+		{"just over", 310, 100, 2, 300, 100},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+		tiles := TileWide(img, 3)
+		if len(tiles) != tt.tiles {
+			t.Errorf("%s: got %d tiles, want %d", tt.name, len(tiles), tt.tiles)
+			continue
+		}
+
+		covered := 0
+		for i, tile := range tiles {
+			b := tile.Bounds()
+			if b.Dx() != tt.tileW || b.Dy() != tt.tileH {
+				t.Errorf("%s: tile %d is %dx%d, want %dx%d", tt.name, i, b.Dx(), b.Dy(), tt.tileW, tt.tileH)
+			}
+			if AspectRatio(tile) > 3 {
+				t.Errorf("%s: tile %d has ratio %.2f", tt.name, i, AspectRatio(tile))
+			}
+			covered = max(covered, b.Max.X, b.Max.Y)
+		}
+		if covered != max(tt.width, tt.height) {
+			t.Errorf("%s: tiles end at %d, want the far edge %d", tt.name, covered, max(tt.width, tt.height))
+		}
+	}
+}
+
+func TestTileWideNormalImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for _, maxRatio := range []float64{3, 0} {
+		tiles := TileWide(img, maxRatio)
+		if len(tiles) != 1 || tiles[0] != image.Image(img) {
+			t.Errorf("max ratio %v: got %d tiles, want the image itself", maxRatio, len(tiles))
+		}
+	}
+}
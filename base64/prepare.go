@@ -0,0 +1,189 @@
+package base64
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// LetterboxGrey is the neutral grey of letterbox bars, shared by the GUI
+// and the command line so both send the same images
+var LetterboxGrey = color.RGBA{R: 128, G: 128, B: 128, A: 255}
+
+// PrepareOptions selects the preprocessing applied before upload
+type PrepareOptions struct {
+	// Refuse images with more pixels than this (0 disables)
+	MaxPixels int64
+
+	// Drop EXIF and other metadata
+	StripMetadata bool
+
+	// Shrink so the longest side is at most this many pixels (0 disables)
+	MaxDimension int
+
+	// JPEG quality (1-100) for downscaled images
+	JPEGQuality int
+
+	// Pad to this width/height ratio (0 disables)
+	LetterboxRatio float64
+
+	// Color of the letterbox bars (LetterboxGrey if nil)
+	LetterboxColor color.Color
+
+	// Longest allowed ratio of long to short side; more extreme images
+	// are letterboxed to it, or split if SplitPanoramas is set (0
+	// disables)
+	MaxAspectRatio float64
+
+	// Split images over MaxAspectRatio into tiles (PrepareImages only)
+	SplitPanoramas bool
+
+	// Most tiles a split may produce, e.g. the API's image limit per
+	// request; images needing more are letterboxed instead (0 disables)
+	MaxTiles int
+
+	// Brighten images whose mean luminance is below this fraction of full
+	// brightness (0 disables)
+	EnhanceDarkThreshold float64
+}
+
+// PrepareImage applies the selected preprocessing to image data
+//
+// The image is only decoded when a step needs pixels or its EXIF
+// orientation has to be applied (see DecodeOriented). Any re-encoded
+// output is free of metadata, so stripping comes for free in that case.
+// Downscaled images are re-encoded as JPEG, since the model does not
+// need lossless detail; otherwise the original format is kept. Images
+// over MaxPixels are refused with a *DimensionError before decoding.
+// SplitPanoramas is ignored; use PrepareImages to split.
+func PrepareImage(data []byte, opts PrepareOptions) ([]byte, error) {
+	if err := CheckDimensions(data, opts.MaxPixels); err != nil {
+		return nil, err
+	}
+
+	// Sideways photos are always turned upright for the model
+	rotated := Orientation(data) != 1
+	if !rotated && !opts.StripMetadata && opts.LetterboxRatio <= 0 && opts.MaxDimension <= 0 &&
+		opts.EnhanceDarkThreshold <= 0 && opts.MaxAspectRatio <= 0 {
+		return data, nil
+	}
+
+	img, format, err := DecodeOriented(data)
+	if err != nil {
+		return nil, err
+	}
+
+	img, downscaled, changed := applySteps(img, opts)
+	if !changed && !rotated && !opts.StripMetadata {
+		return data, nil
+	}
+
+	return encodePrepared(img, format, downscaled, opts)
+}
+
+// PrepareImages is like PrepareImage but may split the image
+//
+// With SplitPanoramas set, images whose AspectRatio exceeds
+// MaxAspectRatio are cut into overlapping tiles (see TileWide), each
+// prepared and returned in order. Otherwise, or when the split would
+// need more than MaxTiles tiles, the single prepared image is returned,
+// letterboxed to MaxAspectRatio.
+func PrepareImages(data []byte, opts PrepareOptions) ([][]byte, error) {
+	if !opts.SplitPanoramas || opts.MaxAspectRatio <= 0 {
+		prepared, err := PrepareImage(data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{prepared}, nil
+	}
+
+	if err := CheckDimensions(data, opts.MaxPixels); err != nil {
+		return nil, err
+	}
+
+	img, format, err := DecodeOriented(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tiles := TileWide(img, opts.MaxAspectRatio)
+	if len(tiles) == 1 || (opts.MaxTiles > 0 && len(tiles) > opts.MaxTiles) {
+		prepared, err := PrepareImage(data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{prepared}, nil
+	}
+
+	// Tiles are new images, so they are always encoded
+	prepared := make([][]byte, 0, len(tiles))
+	for _, tile := range tiles {
+		tile, downscaled, _ := applySteps(tile, opts)
+		encoded, err := encodePrepared(tile, format, downscaled, opts)
+		if err != nil {
+			return nil, err
+		}
+		prepared = append(prepared, encoded)
+	}
+	return prepared, nil
+}
+
+// applySteps runs the pixel processing steps selected in opts
+//
+// Reports whether the image was downscaled and whether it was changed
+// at all.
+func applySteps(img image.Image, opts PrepareOptions) (image.Image, bool, bool) {
+	if opts.LetterboxColor == nil {
+		opts.LetterboxColor = LetterboxGrey
+	}
+
+	// Shrink oversized photos to save tokens and upload time
+	downscaled := false
+	if opts.MaxDimension > 0 {
+		before := img.Bounds()
+		img = Downscale(img, opts.MaxDimension)
+		downscaled = img.Bounds() != before
+	}
+
+	// Lift underexposed photos, before padding so the bars are not
+	// counted or brightened
+	enhanced := false
+	if opts.EnhanceDarkThreshold > 0 {
+		before := img
+		img = AutoEnhanceIfDark(img, opts.EnhanceDarkThreshold)
+		enhanced = img != before
+	}
+
+	// Bring extreme panoramas within the ratio the API handles well
+	fitted := false
+	if opts.MaxAspectRatio > 0 {
+		before := img.Bounds()
+		img = FitAspect(img, opts.MaxAspectRatio, opts.LetterboxColor)
+		fitted = img.Bounds() != before
+	}
+
+	// Pad to the target ratio so the model does not crop the subject
+	if opts.LetterboxRatio > 0 {
+		img = Letterbox(img, opts.LetterboxRatio, opts.LetterboxColor)
+	}
+
+	return img, downscaled, downscaled || enhanced || fitted || opts.LetterboxRatio > 0
+}
+
+// encodePrepared encodes a processed image for upload
+//
+// Re-encoding drops EXIF data such as GPS coordinates.
+func encodePrepared(img image.Image, format string, downscaled bool, opts PrepareOptions) ([]byte, error) {
+	var encoded []byte
+	var err error
+	if downscaled {
+		encoded, err = EncodeJPEG(img, opts.JPEGQuality)
+	} else {
+		encoded, err = EncodeImage(img, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare image: %w", err)
+	}
+
+	return encoded, nil
+}
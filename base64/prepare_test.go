@@ -0,0 +1,49 @@
+package base64
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// encodePNG returns a blank PNG of the given size
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPrepareImagesMaxTiles(t *testing.T) {
+	// A 10:1 strip needs three tiles at a ratio of 4
+	panorama := encodePNG(t, 1000, 100)
+	opts := PrepareOptions{MaxAspectRatio: 4, SplitPanoramas: true}
+
+	tiles, err := PrepareImages(panorama, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tiles) != 3 {
+		t.Fatalf("got %d tiles, want 3", len(tiles))
+	}
+
+	// Too many tiles for the image limit: letterbox instead
+	opts.MaxTiles = 2
+	prepared, err := PrepareImages(panorama, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prepared) != 1 {
+		t.Fatalf("got %d images, want 1", len(prepared))
+	}
+	img, _, err := DecodeOriented(prepared[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratio := AspectRatio(img); ratio > 4 {
+		t.Errorf("aspect ratio %.2f, want the image letterboxed to 4", ratio)
+	}
+}
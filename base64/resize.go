@@ -0,0 +1,94 @@
+package base64
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+)
+
+// Downscale shrinks an image so its longest side is at most maxDim pixels
+//
+// The aspect ratio is preserved. Each output pixel is the average of the
+// source pixels it covers, which avoids the aliasing of nearest-neighbour
+// sampling. Images already small enough, and non-positive maxDim, return
+// the image unchanged.
+func Downscale(img image.Image, maxDim int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxDim <= 0 || (width <= maxDim && height <= maxDim) {
+		return img
+	}
+
+	scale := float64(maxDim) / float64(max(width, height))
+	newWidth := max(1, int(math.Round(float64(width)*scale)))
+	newHeight := max(1, int(math.Round(float64(height)*scale)))
+
+	out := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		// Source rows covered by this output row
+		y0 := bounds.Min.Y + y*height/newHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/newHeight)
+		for x := 0; x < newWidth; x++ {
+			x0 := bounds.Min.X + x*width/newWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/newWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			out.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return out
+}
+
+// EncodeJPEG encodes an image as JPEG at the given quality (1-100)
+//
+// Transparent areas are flattened onto white, since JPEG has no alpha
+// channel.
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode jpeg image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DownscaleToBase64 resizes image data for upload and encodes it as Base64
+//
+// Decodes the image, shrinks it so the longest side is at most maxDim
+// pixels and re-encodes it as JPEG at the given quality.
+func DownscaleToBase64(data []byte, maxDim, quality int) (string, error) {
+	img, _, err := DecodeOriented(data)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := EncodeJPEG(Downscale(img, maxDim), quality)
+	if err != nil {
+		return "", err
+	}
+	return EncodeData(encoded), nil
+}
@@ -0,0 +1,45 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDownscale(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4000, 2000))
+	img.Set(0, 0, color.White)
+
+	small := Downscale(img, 2048)
+	if b := small.Bounds(); b.Dx() != 2048 || b.Dy() != 1024 {
+		t.Errorf("downscaled to %dx%d, want 2048x1024", b.Dx(), b.Dy())
+	}
+
+	tall := Downscale(image.NewRGBA(image.Rect(0, 0, 1000, 4000)), 1000)
+	if b := tall.Bounds(); b.Dx() != 250 || b.Dy() != 1000 {
+		t.Errorf("downscaled to %dx%d, want 250x1000", b.Dx(), b.Dy())
+	}
+
+	if Downscale(img, 4000) != image.Image(img) || Downscale(img, 0) != image.Image(img) {
+		t.Error("image within the limit was changed")
+	}
+}
+
+func TestPrepareImageMaxDimension(t *testing.T) {
+	data := encodePNG(t, 4000, 3000)
+
+	prepared, err := PrepareImage(data, PrepareOptions{MaxDimension: 2048, JPEGQuality: 85})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := DecodeOriented(prepared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); max(b.Dx(), b.Dy()) > 2048 {
+		t.Errorf("prepared image is %dx%d, over the 2048 limit", b.Dx(), b.Dy())
+	}
+	if format != "jpeg" {
+		t.Errorf("downscaled image encoded as %s, want jpeg", format)
+	}
+}
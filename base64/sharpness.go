@@ -0,0 +1,51 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+)
+
+// sharpnessSampleSize is the longest side, in pixels, of the grid the
+// sharpness estimate is computed on
+const sharpnessSampleSize = 1024
+
+// EstimateSharpness estimates how sharp an image is
+//
+// Returns the variance of the Laplacian of the image's luminance: edges
+// in a focused photo give a high variance, blur a low one. Large images
+// are sampled on a grid of at most sharpnessSampleSize pixels per side,
+// so the value does not depend on camera resolution alone. Images too
+// small to filter return 0.
+func EstimateSharpness(img image.Image) float64 {
+	b := img.Bounds()
+	step := max(1, max(b.Dx(), b.Dy())/sharpnessSampleSize)
+	w, h := b.Dx()/step, b.Dy()/step
+	if w < 3 || h < 3 {
+		return 0
+	}
+
+	// Sample luminance
+	gray := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.GrayModel.Convert(img.At(b.Min.X+x*step, b.Min.Y+y*step)).(color.Gray)
+			gray[y*w+x] = float64(c.Y)
+		}
+	}
+
+	// Apply the 4-neighbour Laplacian and accumulate its variance
+	var sum, sumSq float64
+	n := 0
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			lap := gray[i-1] + gray[i+1] + gray[i-w] + gray[i+w] - 4*gray[i]
+			sum += lap
+			sumSq += lap * lap
+			n++
+		}
+	}
+
+	mean := sum / float64(n)
+	return sumSq/float64(n) - mean*mean
+}
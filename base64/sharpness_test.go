@@ -0,0 +1,63 @@
+package base64
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// checkerboard returns a black and white pattern of squares of the given
+// size
+func checkerboard(width, height, square int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x/square+y/square)%2 == 0 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+// boxBlur averages every pixel with its neighbours within radius
+func boxBlur(img *image.Gray, radius int) *image.Gray {
+	b := img.Bounds()
+	out := image.NewGray(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sum, n := 0, 0
+			for dy := -radius; dy <= radius; dy++ {
+				for dx := -radius; dx <= radius; dx++ {
+					if p := image.Pt(x+dx, y+dy); p.In(b) {
+						sum += int(img.GrayAt(p.X, p.Y).Y)
+						n++
+					}
+				}
+			}
+			out.SetGray(x, y, color.Gray{Y: uint8(sum / n)})
+		}
+	}
+	return out
+}
+
+func TestEstimateSharpness(t *testing.T) {
+	sharp := checkerboard(120, 90, 8)
+	blurred := boxBlur(sharp, 3)
+
+	sharpScore := EstimateSharpness(sharp)
+	blurredScore := EstimateSharpness(blurred)
+	if sharpScore <= 0 {
+		t.Fatalf("sharp image scored %v", sharpScore)
+	}
+	if blurredScore >= sharpScore/4 {
+		t.Errorf("blurred image scored %v, sharp %v; want the blurred one far lower", blurredScore, sharpScore)
+	}
+
+	if flat := EstimateSharpness(image.NewGray(image.Rect(0, 0, 50, 50))); flat != 0 {
+		t.Errorf("uniform image scored %v, want 0", flat)
+	}
+	if tiny := EstimateSharpness(checkerboard(2, 2, 1)); tiny != 0 {
+		t.Errorf("2x2 image scored %v, want 0", tiny)
+	}
+}
@@ -0,0 +1,160 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+)
+
+// ChangeKind describes how an image's identification differs between
+// two sessions
+type ChangeKind string
+
+const (
+	// Unchanged means both sessions identified the same species
+	Unchanged ChangeKind = "unchanged"
+
+	// Changed means the identified species differs
+	Changed ChangeKind = "changed"
+
+	// New means the image only appears in the current session
+	New ChangeKind = "new"
+
+	// Missing means the image only appears in the previous session
+	Missing ChangeKind = "missing"
+)
+
+// Change is the comparison result for one image
+type Change struct {
+	// Hash of the image data
+	Hash string
+
+	// Image path, from the current session when available
+	File string
+
+	// How the identification changed
+	Kind ChangeKind
+
+	// Species identified in the previous session (empty if New)
+	Before string
+
+	// Species identified in the current session (empty if Missing)
+	After string
+}
+
+// Comparison lists the differences between two sessions
+type Comparison struct {
+	// One entry per image, sorted by file name
+	Changes []Change
+}
+
+// HashImage returns the hash used to match images across sessions
+func HashImage(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Compare matches two sessions' results by image hash
+//
+// The species of each result is read with analysis.ParseSpecies, so
+// answers worded differently compare equal when they name the same
+// species. Failed results and answers without a recognizable species
+// are reported as "(failed)" and "(unknown)" respectively.
+func Compare(previous, current []Result) Comparison {
+	before := make(map[string]Result, len(previous))
+	for _, r := range previous {
+		before[r.Hash] = r
+	}
+
+	var comparison Comparison
+	seen := make(map[string]bool, len(current))
+	for _, r := range current {
+		seen[r.Hash] = true
+		change := Change{
+			Hash:  r.Hash,
+			File:  r.File,
+			After: speciesOf(r),
+		}
+
+		old, ok := before[r.Hash]
+		switch {
+		case !ok:
+			change.Kind = New
+		case speciesOf(old) == change.After:
+			change.Kind = Unchanged
+			change.Before = speciesOf(old)
+		default:
+			change.Kind = Changed
+			change.Before = speciesOf(old)
+		}
+		comparison.Changes = append(comparison.Changes, change)
+	}
+
+	for _, r := range previous {
+		if !seen[r.Hash] {
+			comparison.Changes = append(comparison.Changes, Change{
+				Hash:   r.Hash,
+				File:   r.File,
+				Kind:   Missing,
+				Before: speciesOf(r),
+			})
+		}
+	}
+
+	sort.SliceStable(comparison.Changes, func(i, j int) bool {
+		return comparison.Changes[i].File < comparison.Changes[j].File
+	})
+	return comparison
+}
+
+// Count returns the number of images with the given kind of change
+func (c Comparison) Count(kind ChangeKind) int {
+	n := 0
+	for _, change := range c.Changes {
+		if change.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Report formats the comparison as a diff-style text report
+//
+// Each image gets one line prefixed with "~" (changed), "=" (unchanged),
+// "+" (new) or "-" (missing), followed by a line of totals.
+func (c Comparison) Report() string {
+	var b strings.Builder
+	for _, change := range c.Changes {
+		name := filepath.Base(change.File)
+		switch change.Kind {
+		case Changed:
+			fmt.Fprintf(&b, "~ %s: %s -> %s\n", name, change.Before, change.After)
+		case Unchanged:
+			fmt.Fprintf(&b, "= %s: %s\n", name, change.After)
+		case New:
+			fmt.Fprintf(&b, "+ %s: %s\n", name, change.After)
+		case Missing:
+			fmt.Fprintf(&b, "- %s: %s\n", name, change.Before)
+		}
+	}
+
+	fmt.Fprintf(&b, "%d changed, %d unchanged, %d new, %d missing\n",
+		c.Count(Changed), c.Count(Unchanged), c.Count(New), c.Count(Missing))
+	return b.String()
+}
+
+// speciesOf returns the species named in a result for comparison
+func speciesOf(r Result) string {
+	if r.Err != nil {
+		return "(failed)"
+	}
+	if species := analysis.ParseSpecies(r.Content); species != "" {
+		return species
+	}
+	return "(unknown)"
+}
@@ -0,0 +1,83 @@
+package batch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	previous := []Result{
+		{File: "old/a.jpg", Hash: "a", Content: "Chanterelle (Cantharellus cibarius)"},
+		{File: "old/b.jpg", Hash: "b", Content: "Porcini (Boletus edulis)"},
+		{File: "old/d.jpg", Hash: "d", Content: "Fly agaric (Amanita muscaria)"},
+		{File: "old/e.jpg", Hash: "e", Err: errors.New("timeout")},
+	}
+	current := []Result{
+		// Worded differently, same species
+		{File: "new/a.jpg", Hash: "a", Content: "**Scientific name**: Cantharellus cibarius"},
+		{File: "new/b.jpg", Hash: "b", Content: "Bay bolete (Imleria badia)"},
+		{File: "new/c.jpg", Hash: "c", Content: "Something unrecognizable"},
+		{File: "new/e.jpg", Hash: "e", Content: "Morel (Morchella esculenta)"},
+	}
+
+	comparison := Compare(previous, current)
+
+	want := map[string]Change{
+		"a": {Kind: Unchanged, Before: "Cantharellus cibarius", After: "Cantharellus cibarius", File: "new/a.jpg"},
+		"b": {Kind: Changed, Before: "Boletus edulis", After: "Imleria badia", File: "new/b.jpg"},
+		"c": {Kind: New, After: "(unknown)", File: "new/c.jpg"},
+		"d": {Kind: Missing, Before: "Amanita muscaria", File: "old/d.jpg"},
+		"e": {Kind: Changed, Before: "(failed)", After: "Morchella esculenta", File: "new/e.jpg"},
+	}
+	if len(comparison.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(comparison.Changes), len(want), comparison.Changes)
+	}
+	for _, change := range comparison.Changes {
+		w := want[change.Hash]
+		w.Hash = change.Hash
+		if change != w {
+			t.Errorf("change %s = %+v, want %+v", change.Hash, change, w)
+		}
+	}
+
+	// Sorted by file name
+	for i := 1; i < len(comparison.Changes); i++ {
+		if comparison.Changes[i-1].File > comparison.Changes[i].File {
+			t.Errorf("changes not sorted: %q before %q", comparison.Changes[i-1].File, comparison.Changes[i].File)
+		}
+	}
+
+	counts := map[ChangeKind]int{Changed: 2, Unchanged: 1, New: 1, Missing: 1}
+	for kind, n := range counts {
+		if got := comparison.Count(kind); got != n {
+			t.Errorf("Count(%s) = %d, want %d", kind, got, n)
+		}
+	}
+}
+
+func TestComparisonReport(t *testing.T) {
+	comparison := Comparison{Changes: []Change{
+		{File: "dir/a.jpg", Kind: Changed, Before: "Boletus edulis", After: "Imleria badia"},
+		{File: "dir/b.jpg", Kind: Unchanged, Before: "Morchella esculenta", After: "Morchella esculenta"},
+		{File: "dir/c.jpg", Kind: New, After: "(unknown)"},
+		{File: "dir/d.jpg", Kind: Missing, Before: "Amanita muscaria"},
+	}}
+
+	want := "~ a.jpg: Boletus edulis -> Imleria badia\n" +
+		"= b.jpg: Morchella esculenta\n" +
+		"+ c.jpg: (unknown)\n" +
+		"- d.jpg: Amanita muscaria\n" +
+		"1 changed, 1 unchanged, 1 new, 1 missing\n"
+	if got := comparison.Report(); got != want {
+		t.Errorf("Report() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHashImage(t *testing.T) {
+	if HashImage([]byte("a")) == HashImage([]byte("b")) {
+		t.Error("different data hashes alike")
+	}
+	if HashImage([]byte("a")) != HashImage([]byte("a")) {
+		t.Error("same data hashes differently")
+	}
+}
@@ -0,0 +1,203 @@
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+)
+
+// imageExtensions lists the file extensions picked up from a folder
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".heic", ".heif"}
+
+// ListImages returns the image files directly inside dir, sorted by name
+//
+// Files are recognized by extension, ignoring case. Subdirectories are
+// not searched.
+func ListImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !isImageFile(entry.Name()) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isImageFile reports whether name has one of imageExtensions
+func isImageFile(name string) bool {
+	ext := filepath.Ext(name)
+	for _, known := range imageExtensions {
+		if strings.EqualFold(ext, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyFunc classifies the contents of one image file, returning the
+// answer
+type ClassifyFunc func(ctx context.Context, file string, data []byte) (string, error)
+
+// Options controls the pace of a batch run
+type Options struct {
+	// Number of images classified at the same time (1 when below 1)
+	Concurrency int
+
+	// Minimum time between starting two classifications, to stay under
+	// API rate limits
+	Delay time.Duration
+
+	// Files larger than this are failed without being read (0 disables)
+	MaxBytes int64
+
+	// Called after each image with its result and the number of images
+	// finished so far (optional)
+	Progress func(result Result, done int)
+}
+
+// Run classifies files with classify and returns one Result per file
+//
+// Results are in the order of files. A file that cannot be read or
+// classified gets a Result with Err set and the run continues with the
+// next one. Cancelling ctx stops starting new classifications; files not
+// started are reported with ctx.Err().
+func Run(ctx context.Context, files []string, classify ClassifyFunc, opts Options) []Result {
+	results := make([]Result, len(files))
+	slots := make(chan struct{}, max(opts.Concurrency, 1))
+
+	var mu sync.Mutex
+	done := 0
+	finish := func(i int, result Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = result
+		done++
+		if opts.Progress != nil {
+			opts.Progress(result, done)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, file := range files {
+		if i > 0 && opts.Delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(opts.Delay):
+			}
+		}
+		if ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case slots <- struct{}{}:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			finish(i, Result{File: file, Err: err})
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			finish(i, classifyFile(ctx, file, classify, opts.MaxBytes))
+		}(i, file)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// classifyFile reads, hashes and classifies a single file
+func classifyFile(ctx context.Context, file string, classify ClassifyFunc, maxBytes int64) Result {
+	result := Result{File: file}
+
+	data, err := base64.ReadImageWithLimit(file, maxBytes)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Hash = HashImage(data)
+
+	result.Content, result.Err = classify(ctx, file, data)
+	return result
+}
+
+// reportEntry is one image in a batch report
+type reportEntry struct {
+	File       string `json:"file"`
+	Hash       string `json:"hash,omitempty"`
+	Species    string `json:"species,omitempty"`
+	Confidence string `json:"confidence,omitempty"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// newReportEntry extracts the report fields of a result
+func newReportEntry(r Result) reportEntry {
+	entry := reportEntry{File: r.File, Hash: r.Hash}
+	if r.Err != nil {
+		entry.Error = r.Err.Error()
+		return entry
+	}
+	entry.Species = analysis.ParseSpecies(r.Content)
+	entry.Confidence = string(analysis.ParseConfidence(r.Content))
+	entry.Result = r.Content
+	return entry
+}
+
+// WriteReport writes batch results to filename
+//
+// A ".csv" extension selects CSV with a header row; anything else is
+// written as a JSON array. Each image gets its file, hash, species,
+// confidence and full answer, or the error it failed with.
+func WriteReport(filename string, results []Result) error {
+	entries := make([]reportEntry, len(results))
+	for i, r := range results {
+		entries[i] = newReportEntry(r)
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		w.Write([]string{"file", "hash", "species", "confidence", "result", "error"})
+		for _, e := range entries {
+			w.Write([]string{e.File, e.Hash, e.Species, e.Confidence, e.Result, e.Error})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("failed to format report: %w", err)
+		}
+		data = []byte(b.String())
+	} else {
+		var err error
+		data, err = json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report file %s: %w", filename, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package batch provides utilities for classifying many images in one run
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// Result is the outcome of classifying a single image in a batch
+type Result struct {
+	// Path to the classified image
+	File string
+
+	// Hash of the image data (see HashImage), used to match results
+	// across sessions even if the file was renamed
+	Hash string
+
+	// Classification text (valid if Err is nil)
+	Content string
+
+	// Failure reason, ideally an *openai.Error (nil on success)
+	Err error
+}
+
+// Summary aggregates the results of a batch run
+type Summary struct {
+	// Number of images processed
+	Total int `json:"total"`
+
+	// Number of successful classifications
+	Succeeded int `json:"succeeded"`
+
+	// Number of failed classifications
+	Failed int `json:"failed"`
+
+	// Failure counts keyed by error category
+	FailuresByCategory map[openai.ErrorCategory]int `json:"failures_by_category"`
+}
+
+// Summarize tallies batch results by outcome and failure category
+//
+// Failures are categorized with errors.As against *openai.Error; errors
+// of any other type are counted as openai.CategoryOther.
+func Summarize(results []Result) Summary {
+	summary := Summary{
+		Total:              len(results),
+		FailuresByCategory: make(map[openai.ErrorCategory]int),
+	}
+
+	for _, result := range results {
+		if result.Err == nil {
+			summary.Succeeded++
+			continue
+		}
+
+		summary.Failed++
+		summary.FailuresByCategory[categorize(result.Err)]++
+	}
+
+	return summary
+}
+
+// categorize returns the category of a batch failure
+func categorize(err error) openai.ErrorCategory {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.Category != "" {
+		return apiErr.Category
+	}
+	return openai.CategoryOther
+}
+
+// String formats the summary for display, including the failure breakdown
+func (s Summary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Processed %d images: %d succeeded, %d failed", s.Total, s.Succeeded, s.Failed)
+
+	// List categories in a stable order
+	categories := make([]string, 0, len(s.FailuresByCategory))
+	for category := range s.FailuresByCategory {
+		categories = append(categories, string(category))
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		fmt.Fprintf(&b, "\n  %s: %d", category, s.FailuresByCategory[openai.ErrorCategory(category)])
+	}
+
+	return b.String()
+}
+
+// WriteStats writes the summary as JSON (e.g. stats.json)
+func WriteStats(filename string, summary Summary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats file %s: %w", filename, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+func TestSummarize(t *testing.T) {
+	rateLimited := &openai.Error{Category: openai.CategoryRateLimit, Message: "slow down"}
+	results := []Result{
+		{File: "a.jpg", Content: "Chanterelle"},
+		{File: "b.jpg", Err: rateLimited},
+		{File: "c.jpg", Err: fmt.Errorf("image c.jpg: %w", rateLimited)},
+		{File: "d.jpg", Err: &openai.Error{Category: openai.CategoryAuth}},
+		{File: "e.jpg", Err: errors.New("file not found")},
+		{File: "f.jpg", Err: &openai.Error{Message: "uncategorized"}},
+		{File: "g.jpg", Content: "Porcini"},
+	}
+
+	summary := Summarize(results)
+	if summary.Total != 7 || summary.Succeeded != 2 || summary.Failed != 5 {
+		t.Errorf("got %d total, %d succeeded, %d failed", summary.Total, summary.Succeeded, summary.Failed)
+	}
+
+	want := map[openai.ErrorCategory]int{
+		openai.CategoryRateLimit: 2,
+		openai.CategoryAuth:      1,
+		openai.CategoryOther:     2,
+	}
+	if len(summary.FailuresByCategory) != len(want) {
+		t.Errorf("FailuresByCategory = %v, want %v", summary.FailuresByCategory, want)
+	}
+	for category, n := range want {
+		if summary.FailuresByCategory[category] != n {
+			t.Errorf("%s: %d failures, want %d", category, summary.FailuresByCategory[category], n)
+		}
+	}
+
+	wantString := "Processed 7 images: 2 succeeded, 5 failed\n  auth: 1\n  other: 2\n  rate-limit: 2"
+	if s := summary.String(); s != wantString {
+		t.Errorf("String() = %q, want %q", s, wantString)
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	summary := Summarize([]Result{{Content: "ok"}, {Err: &openai.Error{Category: openai.CategoryTimeout}}})
+	if err := WriteStats(path, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Summary
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Total != 2 || got.Failed != 1 || got.FailuresByCategory[openai.CategoryTimeout] != 1 {
+		t.Errorf("stats = %s", data)
+	}
+}
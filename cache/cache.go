@@ -0,0 +1,197 @@
+// Package cache keeps classification results so repeated requests for the
+// same image do not cost another API call
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// entry is one cached value
+type entry struct {
+	// Key the value is stored under, e.g. a content hash
+	Key string `json:"key"`
+
+	// Cached value, usually a JSON encoded response
+	Value json.RawMessage `json:"value"`
+}
+
+// Cache maps keys to values, evicting the least recently used entry once
+// it holds maxEntries
+//
+// When backed by a file, every change is written to disk before the
+// call returns, so results survive restarts. A lookup moves its entry to
+// the front; that order is saved with the next change. A nil *Cache
+// stores nothing and never hits. Cache is safe for concurrent use.
+type Cache struct {
+	mu         sync.Mutex
+	path       string
+	maxEntries int
+	// Least recently used first
+	entries []entry
+}
+
+// Open loads the cache stored at path, holding at most maxEntries
+//
+// A missing file yields an empty cache, and an empty path a cache kept
+// in memory only. Returns nil, which caches nothing, if maxEntries is
+// not positive.
+func Open(path string, maxEntries int) (*Cache, error) {
+	if maxEntries <= 0 {
+		return nil, nil
+	}
+
+	c := &Cache{path: path, maxEntries: maxEntries}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse cache %s: %w", path, err)
+	}
+	// The limit may have been lowered since the file was written
+	if excess := len(c.entries) - maxEntries; excess > 0 {
+		c.entries = c.entries[excess:]
+	}
+	return c, nil
+}
+
+// Get returns the value stored under key
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.find(key)
+	if i < 0 {
+		return nil, false
+	}
+	e := c.entries[i]
+	c.entries = append(append(c.entries[:i:i], c.entries[i+1:]...), e)
+	return e.Value, true
+}
+
+// Put stores value under key, replacing any earlier value
+//
+// The least recently used entries are evicted to stay within the limit.
+func (c *Cache) Put(key string, value []byte) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.entries
+	entries := append([]entry(nil), c.entries...)
+	if i := c.find(key); i >= 0 {
+		entries = append(entries[:i], entries[i+1:]...)
+	}
+	entries = append(entries, entry{Key: key, Value: value})
+	if excess := len(entries) - c.maxEntries; excess > 0 {
+		entries = entries[excess:]
+	}
+
+	c.entries = entries
+	if err := c.save(); err != nil {
+		c.entries = previous
+		return err
+	}
+	return nil
+}
+
+// Clear removes all entries, e.g. after the prompt was improved
+func (c *Cache) Clear() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.entries
+	c.entries = nil
+	if err := c.save(); err != nil {
+		c.entries = previous
+		return err
+	}
+	return nil
+}
+
+// Len returns the number of cached entries
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// find returns the index of the entry stored under key, or -1
+func (c *Cache) find(key string) int {
+	for i, e := range c.entries {
+		if e.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// save writes the entries to the backing file, if any
+//
+// Like the queue, the file is replaced atomically so a crash never
+// leaves a partial cache.
+func (c *Cache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	entries := c.entries
+	if entries == nil {
+		entries = []entry{}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+	return nil
+}
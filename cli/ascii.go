@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// transliterations maps common non-ASCII characters to ASCII lookalikes
+var transliterations = map[rune]string{
+	// Latin letters with diacritics
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a",
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Ā': "A",
+	'æ': "ae", 'Æ': "AE", 'ç': "c", 'Ç': "C", 'č': "c", 'Č': "C",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ě': "e",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ě': "E",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ī': "I",
+	'ñ': "n", 'Ñ': "N", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O", 'Ō': "O",
+	'œ': "oe", 'Œ': "OE", 'ř': "r", 'Ř': "R", 'š': "s", 'Š': "S", 'ß': "ss",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ū': "U", 'Ů': "U",
+	'ý': "y", 'ÿ': "y", 'Ý': "Y", 'ž': "z", 'Ž': "Z", 'ł': "l", 'Ł': "L",
+
+	// Punctuation and symbols
+	'‘': "'", '’': "'", '“': "\"", '”': "\"", '–': "-", '—': "--",
+	'…': "...", '•': "*", '·': "-", '°': " deg", '×': "x", 'µ': "u",
+	'±': "+/-", '≈': "~", '≤': "<=", '≥': ">=", '→': "->", '⚠': "!",
+	' ': " ",
+}
+
+// ToASCII rewrites text using only ASCII characters
+//
+// Accented letters and typographic punctuation are transliterated to
+// their closest ASCII form (e.g. "é" becomes "e", "—" becomes "--").
+// Any other non-ASCII character is escaped as \uXXXX (or \UXXXXXXXX)
+// so no information is silently lost.
+func ToASCII(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case transliterations[r] != "":
+			b.WriteString(transliterations[r])
+		case r > 0xFFFF:
+			fmt.Fprintf(&b, "\\U%08X", r)
+		default:
+			fmt.Fprintf(&b, "\\u%04X", r)
+		}
+	}
+	return b.String()
+}
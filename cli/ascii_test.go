@@ -0,0 +1,23 @@
+package cli
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Chanterelle", "Chanterelle"},
+		{"Cèpe de Bordeaux", "Cepe de Bordeaux"},
+		{"Größe: 5–10 cm", "Grosse: 5-10 cm"},
+		{"“Edible” — but cook well…", "\"Edible\" -- but cook well..."},
+		{"Spores 8×5 µm, 20°C", "Spores 8x5 um, 20 degC"},
+		{"⚠ Deadly", "! Deadly"},
+		{"Fliegenpilz 毒", "Fliegenpilz \\u6BD2"},
+		{"🍄", "\\U0001F344"},
+	}
+	for _, tt := range tests {
+		if got := ToASCII(tt.in); got != tt.want {
+			t.Errorf("ToASCII(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
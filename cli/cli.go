@@ -0,0 +1,276 @@
+// Package cli classifies images from the command line without the GUI
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+	"github.com/mushroom-classifier/mushroom-classifier-go/cache"
+	"github.com/mushroom-classifier/mushroom-classifier-go/config"
+	"github.com/mushroom-classifier/mushroom-classifier-go/httpclient"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+	"github.com/mushroom-classifier/mushroom-classifier-go/provider"
+)
+
+// Options controls command line output
+type Options struct {
+	// Destination for results
+	Out io.Writer
+
+	// Transliterate or escape non-ASCII characters, for consoles that
+	// cannot display UTF-8
+	ASCII bool
+
+	// Print the API request for each image instead of sending it (Run
+	// and RunReader only)
+	DryRun bool
+
+	// Ask the standard questions in one request and print each answer
+	// under its own heading (Run and RunReader only)
+	Multi bool
+}
+
+// Run classifies each image file in turn and prints the answers
+//
+// Every file is attempted even if earlier ones fail; failures are
+// printed in place of the answer and reported in the returned error.
+func Run(cfg *config.Config, files []string, opts Options) error {
+	s, err := newSession(cfg)
+	if err != nil {
+		return err
+	}
+	s.dryRun = opts.DryRun
+	s.multi = opts.Multi
+
+	failed := 0
+	for i, file := range files {
+		if i > 0 {
+			opts.print("\n")
+		}
+		opts.print(fmt.Sprintf("=== %s ===\n\n", filepath.Base(file)))
+
+		content, err := s.classify(file)
+		if err != nil {
+			failed++
+			opts.print(fmt.Sprintf("Error: %v\n", err))
+			continue
+		}
+		opts.print(content + "\n")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images failed", failed, len(files))
+	}
+	return nil
+}
+
+// RunReader classifies an image read from r, e.g. piped to standard input
+//
+// The format is detected from the data, as there is no file name. Empty
+// input is an error.
+func RunReader(cfg *config.Config, r io.Reader, opts Options) error {
+	s, err := newSession(cfg)
+	if err != nil {
+		return err
+	}
+	s.dryRun = opts.DryRun
+	s.multi = opts.Multi
+
+	data, err := base64.ReadImageFrom(r, "standard input", cfg.MaxImageBytes)
+	if err != nil {
+		return err
+	}
+	content, err := s.classifyData(context.Background(), "standard input", data)
+	if err != nil {
+		return err
+	}
+	opts.print(content + "\n")
+	return nil
+}
+
+// print writes text, filtered for the configured encoding
+func (opts Options) print(text string) {
+	if opts.ASCII {
+		text = ToASCII(text)
+	}
+	io.WriteString(opts.Out, text)
+}
+
+// session holds what all classifications of a run share
+type session struct {
+	cfg      *config.Config
+	provider provider.Provider
+
+	// Rate limiter and result cache; either may be nil
+	limiter *httpclient.RateLimiter
+	cache   *cache.Cache
+
+	// Print requests instead of sending them
+	dryRun bool
+
+	// Ask prompts.StandardQuestions in one combined request
+	multi bool
+}
+
+// newSession prepares the provider, rate limiter and result cache of cfg
+func newSession(cfg *config.Config) (*session, error) {
+	p, err := provider.FromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	results, err := cache.Open(cfg.ResultCacheFile, cfg.ResultCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session{
+		cfg:      cfg,
+		provider: p,
+		limiter:  httpclient.NewRateLimiter(cfg.RequestsPerMinute),
+		cache:    results,
+	}, nil
+}
+
+// classify reads, prepares and classifies a single image file
+func (s *session) classify(file string) (string, error) {
+	data, err := base64.ReadImageWithLimit(file, s.cfg.MaxImageBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return s.classifyData(context.Background(), file, data)
+}
+
+// classifyData prepares and classifies the contents of an image file
+//
+// file is only used in warnings.
+func (s *session) classifyData(ctx context.Context, file string, data []byte) (string, error) {
+	cfg := s.cfg
+	if err := base64.ValidateImage(data); err != nil {
+		return "", err
+	}
+
+	prepared, err := base64.PrepareImages(data, base64.PrepareOptions{
+		MaxPixels:      cfg.MaxImagePixels,
+		StripMetadata:  cfg.StripMetadata,
+		MaxDimension:   cfg.MaxImageDimension,
+		JPEGQuality:    cfg.JPEGQuality,
+		LetterboxRatio: cfg.LetterboxRatio,
+		LetterboxColor: base64.LetterboxGrey,
+
+		MaxAspectRatio: cfg.PanoramaMaxRatio,
+		SplitPanoramas: cfg.PanoramaMode == "tile",
+		MaxTiles:       cfg.MaxImagesPerRequest,
+
+		EnhanceDarkThreshold: cfg.EnhanceDarkThreshold,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Tiles of a split panorama are sent together
+	images := make([]openai.ImageInput, 0, len(prepared))
+	for _, img := range prepared {
+		images = append(images, openai.ImageInput{
+			Data:     base64.EncodeData(img),
+			MimeType: base64.DetectMimeType(img),
+		})
+	}
+
+	req := &openai.Request{
+		APIKey:             cfg.APIKey(),
+		APIURL:             cfg.APIURL(),
+		Organization:       cfg.OpenAIOrganization,
+		Project:            cfg.OpenAIProject,
+		Model:              cfg.Model,
+		Prompt:             prompts.WithLanguage(prompts.Mushroom(), cfg.Language),
+		SystemPrompt:       cfg.SystemPrompt,
+		Images:             images,
+		MaxImages:          cfg.MaxImagesPerRequest,
+		RawBase64Image:     cfg.RawBase64Image,
+		ImageDetail:        cfg.ImageDetail,
+		ShrinkMinQuality:   cfg.ShrinkMinQuality,
+		MaxTokens:          cfg.MaxTokens,
+		Temperature:        cfg.Temperature,
+		TopP:               cfg.TopP,
+		MinContentLength:   cfg.MinResultLength,
+		Timeout:            cfg.HTTPTimeout,
+		Proxy:              cfg.Proxy,
+		CAFile:             cfg.CAFile,
+		RateLimiter:        s.limiter,
+		Cache:              s.cache,
+		ContentPath:        cfg.ContentPath(cfg.APIURL()),
+		Debug:              cfg.Debug,
+		RetryMalformedJSON: cfg.RetryMalformedJSON,
+	}
+	if s.multi {
+		req.Prompt = prompts.WithLanguage(prompts.MultiQuestion(prompts.StandardQuestions), cfg.Language)
+		req.JSONResponse = true
+	}
+	if s.dryRun {
+		return dryRunBody(cfg, req)
+	}
+
+	resp, err := s.provider.AnalyzeImage(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if err := resp.Err(); err != nil {
+		return "", err
+	}
+	if resp.Truncated {
+		log.Printf("Warning: the answer for %s was truncated; raise OPENAI_MAX_TOKENS", file)
+	}
+
+	if s.multi {
+		return formatAnswers(resp.Content, prompts.StandardQuestions)
+	}
+	return resp.Content, nil
+}
+
+// formatAnswers splits a combined answer to questions and lists each
+// answer under its question key
+func formatAnswers(content string, questions []prompts.Question) (string, error) {
+	answers, err := analysis.ParseAnswers(content, prompts.Keys(questions))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, q := range questions {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s: %s\n", strings.ToUpper(q.Key[:1])+q.Key[1:], answers[q.Key])
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// dryRunBody returns the indented body req would be sent with
+//
+// Only OpenAI-compatible requests can be shown; the API key is never
+// part of the body.
+func dryRunBody(cfg *config.Config, req *openai.Request) (string, error) {
+	if cfg.Provider != "openai" {
+		return "", fmt.Errorf("dry runs show OpenAI requests only, but PROVIDER is %s", cfg.Provider)
+	}
+
+	body, err := openai.BuildRequestJSON(req)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
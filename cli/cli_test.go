@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+)
+
+func TestFormatAnswers(t *testing.T) {
+	content := "```json\n" +
+		`{"species": "Chanterelle", "edibility": "Edible", "habitat": "Beech forest"}` +
+		"\n```"
+
+	got, err := formatAnswers(content, prompts.StandardQuestions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Species: Chanterelle\n\nEdibility: Edible\n\nHabitat: Beech forest"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := formatAnswers(`{"species": "Chanterelle"}`, prompts.StandardQuestions); err == nil {
+		t.Error("incomplete answer accepted")
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/batch"
+	"github.com/mushroom-classifier/mushroom-classifier-go/config"
+	"github.com/mushroom-classifier/mushroom-classifier-go/provider"
+)
+
+// statsFile is the name of the run statistics written next to the report
+const statsFile = "stats.json"
+
+// RunFolder classifies every image in dir and writes a combined report
+//
+// Images are classified unattended with the configured concurrency and
+// delay between requests (BATCH_CONCURRENCY, BATCH_DELAY_SECONDS). A line
+// per image and a summary are printed as the run progresses. Failures do
+// not stop the run; they are recorded in the report, which is JSON or
+// CSV depending on the extension of report (see batch.WriteReport), and
+// counted by category in stats.json next to it.
+// Copies of the same image classified at the same time share one API
+// call.
+func RunFolder(cfg *config.Config, dir, report string, opts Options) error {
+	s, err := newSession(cfg)
+	if err != nil {
+		return err
+	}
+	s.provider = provider.Deduplicate(s.provider)
+
+	files, err := batch.ListImages(dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no images found in %s", dir)
+	}
+
+	results := batch.Run(context.Background(), files,
+		func(ctx context.Context, file string, data []byte) (string, error) {
+			return s.classifyData(ctx, file, data)
+		},
+		batch.Options{
+			Concurrency: cfg.BatchConcurrency,
+			Delay:       cfg.BatchDelay,
+			MaxBytes:    cfg.MaxImageBytes,
+			Progress: func(result batch.Result, done int) {
+				opts.print(fmt.Sprintf("[%d/%d] %s: %s\n", done, len(files),
+					filepath.Base(result.File), resultLine(result)))
+			},
+		})
+
+	if err := batch.WriteReport(report, results); err != nil {
+		return err
+	}
+
+	summary := batch.Summarize(results)
+	stats := filepath.Join(filepath.Dir(report), statsFile)
+	if err := batch.WriteStats(stats, summary); err != nil {
+		return err
+	}
+
+	opts.print(fmt.Sprintf("\n%s\nReport written to %s, statistics to %s\n", summary, report, stats))
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d images failed", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+// resultLine summarizes a batch result in a few words
+func resultLine(result batch.Result) string {
+	if result.Err != nil {
+		return fmt.Sprintf("error: %v", result.Err)
+	}
+	species := analysis.ParseSpecies(result.Content)
+	if species == "" {
+		species = "species not recognized"
+	}
+	return fmt.Sprintf("%s (%s confidence)", species, analysis.ParseConfidence(result.Content))
+}
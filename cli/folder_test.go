@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/batch"
+	"github.com/mushroom-classifier/mushroom-classifier-go/config"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// mockConfig loads a configuration for the offline mock provider
+func mockConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("PROVIDER", "mock")
+	return loadConfig(t)
+}
+
+// serverConfig loads a configuration sending requests to url
+func serverConfig(t *testing.T, url string) *config.Config {
+	t.Helper()
+	t.Setenv("PROVIDER", "openai")
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_API_KEY_FILE", "")
+	t.Setenv("OPENAI_API_URL", url)
+	return loadConfig(t)
+}
+
+// loadConfig loads the configuration, isolated from the user's files,
+// classifying one image at a time without delay
+func loadConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("RESULT_CACHE_FILE", "")
+	t.Setenv("RESULT_CACHE_SIZE", "0")
+	t.Setenv("BATCH_CONCURRENCY", "1")
+	t.Setenv("BATCH_DELAY_SECONDS", "0")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// writePNG writes a small PNG image to dir/name
+func writePNG(t *testing.T, dir, name string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 32, 24))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunFolder(t *testing.T) {
+	cfg := mockConfig(t)
+	dir := t.TempDir()
+	writePNG(t, dir, "a.png")
+	writePNG(t, dir, "b.PNG")
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an image"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(t.TempDir(), "report.json")
+
+	var out bytes.Buffer
+	if err := RunFolder(cfg, dir, report, Options{Out: &out}); err != nil {
+		t.Fatalf("RunFolder: %v\n%s", err, out.String())
+	}
+
+	for _, want := range []string{"a.png: Cantharellus cibarius", "b.PNG: Cantharellus cibarius", "Report written to " + report} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "notes.txt") {
+		t.Errorf("non-image file classified:\n%s", out.String())
+	}
+
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil || len(entries) != 2 {
+		t.Errorf("report = %s, want 2 entries", data)
+	}
+}
+
+func TestRunFolderFailure(t *testing.T) {
+	cfg := mockConfig(t)
+	dir := t.TempDir()
+	writePNG(t, dir, "good.png")
+	if err := os.WriteFile(filepath.Join(dir, "broken.jpg"), []byte("not a JPEG"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"report.json", "report.csv"} {
+		report := filepath.Join(t.TempDir(), name)
+
+		var out bytes.Buffer
+		err := RunFolder(cfg, dir, report, Options{Out: &out})
+		if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+			t.Errorf("%s: err = %v, want 1 of 2 images failed", name, err)
+		}
+		if !strings.Contains(out.String(), "broken.jpg: error:") || !strings.Contains(out.String(), "good.png: Cantharellus cibarius") {
+			t.Errorf("%s: output:\n%s", name, out.String())
+		}
+
+		// The report lists the failed image next to the good one
+		rows := readReport(t, report)
+		if len(rows) != 2 {
+			t.Fatalf("%s: got %d rows, want 2: %v", name, len(rows), rows)
+		}
+		broken, good := rows[0], rows[1]
+		if filepath.Base(broken["file"]) != "broken.jpg" || broken["error"] == "" || broken["result"] != "" {
+			t.Errorf("%s: broken image row = %v", name, broken)
+		}
+		if filepath.Base(good["file"]) != "good.png" || good["error"] != "" || good["species"] != "Cantharellus cibarius" {
+			t.Errorf("%s: good image row = %v", name, good)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(report), statsFile)); err != nil {
+			t.Errorf("%s: statistics not written: %v", name, err)
+		}
+	}
+}
+
+// readReport reads the rows of a JSON or CSV report, keyed by column
+func readReport(t *testing.T, report string) []map[string]string {
+	t.Helper()
+	f, err := os.Open(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var rows []map[string]string
+	if filepath.Ext(report) != ".csv" {
+		if err := json.NewDecoder(f).Decode(&rows); err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("reading %s: %v", report, err)
+	}
+	for _, record := range records[1:] {
+		row := make(map[string]string)
+		for i, column := range records[0] {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestRunFolderEmpty(t *testing.T) {
+	cfg := mockConfig(t)
+
+	if err := RunFolder(cfg, t.TempDir(), filepath.Join(t.TempDir(), "report.json"), Options{Out: &bytes.Buffer{}}); err == nil {
+		t.Error("empty folder accepted")
+	}
+}
+
+func TestRunFolderStats(t *testing.T) {
+	// Answer the first image, then reject the key, then rate limit
+	replies := []struct {
+		status int
+		body   string
+	}{
+		{http.StatusOK, `{"choices":[{"message":{"content":"**Species**: Morchella esculenta\n**Confidence**: High"},"finish_reason":"stop"}]}`},
+		{http.StatusUnauthorized, `{"error":{"message":"Incorrect API key","type":"invalid_request_error","code":"invalid_api_key"}}`},
+		{http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`},
+	}
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		reply := replies[min(int(calls.Add(1)), len(replies))-1]
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(reply.status)
+		io.WriteString(w, reply.body)
+	}))
+	defer server.Close()
+
+	cfg := serverConfig(t, server.URL)
+	dir := t.TempDir()
+	writePNG(t, dir, "a.png")
+	writePNG(t, dir, "b.png")
+	writePNG(t, dir, "c.png")
+	if err := os.WriteFile(filepath.Join(dir, "d.jpg"), []byte("not a JPEG"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reportDir := t.TempDir()
+
+	var out bytes.Buffer
+	if err := RunFolder(cfg, dir, filepath.Join(reportDir, "report.json"), Options{Out: &out}); err == nil {
+		t.Errorf("RunFolder succeeded with failed images:\n%s", out.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(reportDir, statsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats batch.Summary
+	if err := json.Unmarshal(data, &stats); err != nil {
+		t.Fatalf("%s: %v\n%s", statsFile, err, data)
+	}
+	if stats.Total != 4 || stats.Succeeded != 1 || stats.Failed != 3 {
+		t.Errorf("got %d total, %d succeeded, %d failed, want 4, 1, 3", stats.Total, stats.Succeeded, stats.Failed)
+	}
+	want := map[openai.ErrorCategory]int{
+		openai.CategoryAuth:      1,
+		openai.CategoryRateLimit: 1,
+		openai.CategoryOther:     1,
+	}
+	if len(stats.FailuresByCategory) != len(want) {
+		t.Errorf("FailuresByCategory = %v, want %v", stats.FailuresByCategory, want)
+	}
+	for category, n := range want {
+		if stats.FailuresByCategory[category] != n {
+			t.Errorf("%s failures = %d, want %d", category, stats.FailuresByCategory[category], n)
+		}
+	}
+}
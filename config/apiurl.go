@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// normalizeAPIURL checks the OpenAI endpoint and fixes common mistakes
+//
+// The URL must be an absolute http(s) URL. A trailing slash is dropped,
+// and a base URL ending in /v1, as often copied from documentation, gets
+// /chat/completions appended; either change is logged. A plain http URL
+// to a host other than this machine is accepted with a warning, as the
+// API key would travel unencrypted.
+func normalizeAPIURL(name, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid value for %s: %q (expected e.g. https://api.openai.com/v1/chat/completions)", name, raw)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	if strings.HasSuffix(u.Path, "/v1") {
+		u.Path += "/chat/completions"
+	}
+
+	normalized := u.String()
+	if normalized != raw {
+		log.Printf("Warning: %s %q corrected to %s", name, raw, normalized)
+	}
+	if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+		log.Printf("Warning: %s uses plain http; the API key is sent unencrypted", name)
+	}
+	return normalized, nil
+}
+
+// isLoopback reports whether host names this machine, e.g. a local model
+// server
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
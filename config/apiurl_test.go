@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestNormalizeAPIURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://api.openai.com/v1/chat/completions", "https://api.openai.com/v1/chat/completions"},
+		{"https://api.openai.com/v1", "https://api.openai.com/v1/chat/completions"},
+		{"https://api.openai.com/v1/", "https://api.openai.com/v1/chat/completions"},
+		{"https://api.openai.com/v1/chat/completions/", "https://api.openai.com/v1/chat/completions"},
+		{"  http://localhost:11434/v1  ", "http://localhost:11434/v1/chat/completions"},
+		{"https://proxy.example.com/openai/v1?api-version=2", "https://proxy.example.com/openai/v1/chat/completions?api-version=2"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeAPIURL("OPENAI_API_URL", tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeAPIURL(%q) = %q, %v, want %q", tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAPIURLInvalid(t *testing.T) {
+	for _, raw := range []string{"", "api.openai.com/v1", "ftp://api.openai.com/v1", "https://", "https://exa mple.com"} {
+		if got, err := normalizeAPIURL("OPENAI_API_URL", raw); err == nil {
+			t.Errorf("normalizeAPIURL(%q) = %q, want an error", raw, got)
+		}
+	}
+}
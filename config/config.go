@@ -3,55 +3,899 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// MaxTokensLimit is the largest answer length, in tokens, that may be
+// requested; current vision models cannot produce longer answers
+const MaxTokensLimit = 16384
+
 // Config holds application configuration loaded from environment
 //
 // This structure contains all configuration parameters needed by the
 // application, primarily API credentials and endpoints.
 type Config struct {
+	// Vision API used for classification: "openai" or "anthropic"
+	Provider string
+
 	// OpenAI API key for authentication
 	OpenAIAPIKey string
 
 	// OpenAI API endpoint URL
 	OpenAIAPIURL string
+
+	// OpenAI organization and project billed for requests (optional,
+	// for accounts belonging to several)
+	OpenAIOrganization string
+	OpenAIProject      string
+
+	// Anthropic API key, used when Provider is "anthropic"
+	AnthropicAPIKey string
+
+	// Anthropic Messages API endpoint URL
+	AnthropicAPIURL string
+
+	// HTTP or SOCKS5 proxy for API calls, overriding HTTPS_PROXY
+	// (optional)
+	Proxy string
+
+	// PEM file of extra root certificates trusted for API calls
+	// (optional)
+	CAFile string
+
+	// Answer of the mock provider in place of its sample (optional)
+	MockResponse string
+
+	// Time the mock provider takes to answer (optional)
+	MockDelay time.Duration
+
+	// Vision model used unless another is selected in the GUI
+	Model string
+
+	// System prompt sent ahead of every request (optional)
+	SystemPrompt string
+
+	// Language answers are requested in (empty for English)
+	Language string
+
+	// Default limit on the length of an answer, in tokens
+	MaxTokens int
+
+	// Test the API key and URL when the GUI starts
+	CheckConnection bool
+
+	// Stream answers as they are generated
+	Stream bool
+
+	// Retry once when the API returns a malformed JSON body
+	RetryMalformedJSON bool
+
+	// JSON path of the answer in non-standard responses, keyed by API
+	// host (e.g. "api.example.com" -> "output[0].text")
+	ContentPaths map[string]string
+
+	// Send images as bare base64 rather than data: URLs
+	RawBase64Image bool
+
+	// Resolution OpenAI views images at: "low" or "high" (empty for auto)
+	ImageDetail string
+
+	// Re-ask for a full analysis when a result is shorter than this (0 disables)
+	MinResultLength int
+
+	// Strip EXIF and other metadata from images before sending them
+	StripMetadata bool
+
+	// Offer a text-only query when an image format cannot be decoded
+	TextOnlyFallback bool
+
+	// Re-classify the current image when request parameters change
+	AutoRerun bool
+
+	// Offer marking the cap, gills and stem on the image
+	FeatureBoxes bool
+
+	// Offer sending a hand-written JSON messages array
+	ExpertMode bool
+
+	// Ask again with high image detail when the first answer has low
+	// confidence (costs a second request)
+	RetryLowConfidence bool
+
+	// Ask for calibrated probabilities and the top candidate species
+	CalibratedConfidence bool
+
+	// Number of most recent follow-up exchanges shown below the
+	// classification in the results (0 shows all)
+	MaxDisplayedExchanges int
+
+	// Maximum number of images sent in one request (0 means no limit)
+	MaxImagesPerRequest int
+
+	// Reject image files larger than this many bytes (0 disables)
+	MaxImageBytes int64
+
+	// Refuse images that decode to more than this many pixels (0 disables)
+	MaxImagePixels int64
+
+	// Downscale images so the longest side is at most this many pixels
+	// before upload (0 disables)
+	MaxImageDimension int
+
+	// JPEG quality (1-100) used for downscaled images
+	JPEGQuality int
+
+	// Lowest JPEG quality (1-100) images are re-encoded at when the API
+	// rejects a request as too large (0 disables the retries)
+	ShrinkMinQuality int
+
+	// Pad images with bars to this width/height ratio (0 disables)
+	LetterboxRatio float64
+
+	// Longest ratio of long to short image side sent as is (0 disables)
+	PanoramaMaxRatio float64
+
+	// How more extreme images are handled: "letterbox" or "tile"
+	PanoramaMode string
+
+	// Brighten images whose mean luminance is below this fraction of full
+	// brightness before upload (0 disables)
+	EnhanceDarkThreshold float64
+
+	// Warn about images whose estimated sharpness is below this value
+	// (0 disables)
+	SharpnessThreshold float64
+
+	// Time after which a classification is cancelled as too slow (0
+	// disables)
+	ClassifyTimeout time.Duration
+
+	// Hard limit after which a stuck classification is abandoned (0 disables)
+	WatchdogTimeout time.Duration
+
+	// Time limit for each API call
+	HTTPTimeout time.Duration
+
+	// Most API calls started per minute; further calls wait (0 disables)
+	RequestsPerMinute int
+
+	// Number of folder images classified at the same time
+	BatchConcurrency int
+
+	// Minimum time between starting two classifications of a folder
+	BatchDelay time.Duration
+
+	// Number of identical requests whose answers are put to a vote
+	// (1 disables)
+	EnsembleSize int
+
+	// Sampling temperature sent with requests (nil uses the API default)
+	Temperature *float64
+
+	// Nucleus sampling probability sent with requests (nil uses the API
+	// default)
+	TopP *float64
+
+	// Unit system measurements are converted to: metric, imperial or
+	// empty for no conversion
+	UnitSystem string
+
+	// File past classifications are recorded in (empty uses the default
+	// location in the user's config directory)
+	HistoryFile string
+
+	// Log request bodies and API call outcomes for troubleshooting
+	Debug bool
+
+	// File the classification queue is saved to (empty keeps it in memory)
+	QueueFile string
+
+	// Most results kept in the result cache (0 disables the cache)
+	ResultCacheSize int
+
+	// File the result cache is saved to (empty keeps it in memory)
+	ResultCacheFile string
+
+	// Markdown journal results can be appended to (empty hides the
+	// journal button)
+	JournalFile string
+
+	// File the edited classification prompt is saved to (empty uses the
+	// user's config directory)
+	PromptFile string
+
+	// GUI theme until one is picked in the GUI: "system", "light" or
+	// "dark"
+	Theme string
+
+	// Log destination: stderr, file or syslog
+	LogDest string
+
+	// Log file path used when LogDest is "file"
+	LogFile string
 }
 
-// Load reads configuration from .env file
+// Load reads configuration from the environment and an optional .env file
 //
-// Reads the .env file from the current directory and parses key-value
-// pairs. Currently supports OPENAI_API_KEY and OPENAI_API_URL variables.
-// Lines starting with '#' are treated as comments.
+// Reads the .env file from the current directory, if present, and parses
+// key-value pairs into the environment. Variables already set in the
+// environment take precedence. Without a .env file the configuration comes
+// from the environment alone (e.g. in CI or a container). Lines starting
+// with '#' are treated as comments. Settings missing from both are taken
+// from the JSON config file at DefaultFilePath, if there is one.
 func Load() (*Config, error) {
-	// Try to load .env file from current directory
+	return LoadFile("")
+}
+
+// LoadFile is like Load but reads the JSON config file at path
+//
+// With an empty path the file at DefaultFilePath is used if it exists;
+// otherwise the file must exist. See loadFile for its format.
+func LoadFile(path string) (*Config, error) {
+	// Load .env file from current directory, if there is one
 	envPath := filepath.Join(".", ".env")
-	if err := godotenv.Load(envPath); err != nil {
-		// Check if it's a file not found error
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("configuration file .env not found")
-		}
+	if err := godotenv.Load(envPath); err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to load .env file: %w", err)
 	}
 
+	// Fill in settings from the config file, below the environment
+	fileSettings = nil
+	required := path != ""
+	if path == "" {
+		if defaultPath, err := DefaultFilePath(); err == nil {
+			path = defaultPath
+		}
+	}
+	if path != "" {
+		settings, err := loadFile(path, required)
+		if err != nil {
+			return nil, err
+		}
+		fileSettings = settings
+	}
+
 	// Create config struct
-	config := &Config{
-		OpenAIAPIKey: os.Getenv("OPENAI_API_KEY"),
-		OpenAIAPIURL: os.Getenv("OPENAI_API_URL"),
+	openAIKey, err := getEnvSecret("OPENAI_API_KEY")
+	if err != nil {
+		return nil, err
+	}
+	anthropicKey, err := getEnvSecret("ANTHROPIC_API_KEY")
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate required fields
-	if config.OpenAIAPIKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY not found in .env file")
+	config := &Config{
+		Provider:        strings.ToLower(strings.TrimSpace(getEnv("PROVIDER"))),
+		OpenAIAPIKey:    openAIKey,
+		OpenAIAPIURL:    getEnv("OPENAI_API_URL"),
+		AnthropicAPIKey: anthropicKey,
+		AnthropicAPIURL: getEnv("ANTHROPIC_API_URL"),
+
+		OpenAIOrganization: strings.TrimSpace(getEnv("OPENAI_ORG")),
+		OpenAIProject:      strings.TrimSpace(getEnv("OPENAI_PROJECT")),
 	}
 
 	if config.OpenAIAPIURL == "" {
 		// Set default URL if not provided
 		config.OpenAIAPIURL = "https://api.openai.com/v1/chat/completions"
+	} else {
+		config.OpenAIAPIURL, err = normalizeAPIURL("OPENAI_API_URL", config.OpenAIAPIURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.AnthropicAPIURL == "" {
+		config.AnthropicAPIURL = "https://api.anthropic.com/v1/messages"
+	}
+
+	// Validate the key of the selected provider
+	switch config.Provider {
+	case "", "openai":
+		config.Provider = "openai"
+		if config.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY (or OPENAI_API_KEY_FILE) is not set in the environment or .env file")
+		}
+		config.Model = strings.TrimSpace(getEnv("OPENAI_MODEL"))
+		if config.Model == "" {
+			config.Model = "gpt-4o"
+		}
+	case "anthropic":
+		if config.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY (or ANTHROPIC_API_KEY_FILE) is not set in the environment or .env file")
+		}
+		config.Model = strings.TrimSpace(getEnv("ANTHROPIC_MODEL"))
+		if config.Model == "" {
+			config.Model = "claude-3-5-sonnet-latest"
+		}
+	case "mock":
+		// Nothing is sent, so no key is needed
+		config.Model = "mock"
+		config.MockResponse = getEnv("MOCK_RESPONSE")
+		mockDelay, err := getEnvFloat("MOCK_DELAY_SECONDS", 0)
+		if err != nil {
+			return nil, err
+		}
+		if mockDelay < 0 {
+			return nil, fmt.Errorf("MOCK_DELAY_SECONDS must not be negative")
+		}
+		config.MockDelay = time.Duration(mockDelay * float64(time.Second))
+	default:
+		return nil, fmt.Errorf("invalid value for PROVIDER: %q", config.Provider)
+	}
+
+	config.Proxy = strings.TrimSpace(getEnv("API_PROXY"))
+	if config.Proxy != "" {
+		u, err := url.Parse(config.Proxy)
+		if err != nil || u.Host == "" {
+			// The value may hold credentials, so it is not repeated
+			return nil, fmt.Errorf("invalid value for API_PROXY: expected e.g. http://host:port")
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("invalid value for API_PROXY: the scheme must be http, https or socks5")
+		}
+	}
+
+	config.CAFile = strings.TrimSpace(getEnv("API_CA_FILE"))
+	if config.CAFile != "" {
+		if _, err := os.Stat(config.CAFile); err != nil {
+			return nil, fmt.Errorf("failed to read API_CA_FILE: %w", err)
+		}
+	}
+
+	config.SystemPrompt = getEnv("OPENAI_SYSTEM_PROMPT")
+	config.Language = parseLanguage(getEnv("LANGUAGE"))
+
+	maxTokens, err := getEnvInt("OPENAI_MAX_TOKENS", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if maxTokens < 1 || maxTokens > MaxTokensLimit {
+		return nil, fmt.Errorf("OPENAI_MAX_TOKENS must be between 1 and %d", MaxTokensLimit)
+	}
+	config.MaxTokens = maxTokens
+
+	// Parse optional flags
+	checkConnection, err := getEnvBool("CHECK_CONNECTION", true)
+	if err != nil {
+		return nil, err
+	}
+	config.CheckConnection = checkConnection
+
+	stream, err := getEnvBool("OPENAI_STREAM", true)
+	if err != nil {
+		return nil, err
+	}
+	config.Stream = stream
+
+	retry, err := getEnvBool("OPENAI_RETRY_MALFORMED_JSON", false)
+	if err != nil {
+		return nil, err
+	}
+	config.RetryMalformedJSON = retry
+
+	contentPaths, err := parseContentPaths(getEnv("RESPONSE_CONTENT_PATHS"))
+	if err != nil {
+		return nil, err
+	}
+	config.ContentPaths = contentPaths
+
+	rawBase64, err := getEnvBool("OPENAI_RAW_BASE64_IMAGE", false)
+	if err != nil {
+		return nil, err
+	}
+	config.RawBase64Image = rawBase64
+
+	config.ImageDetail = strings.ToLower(getEnv("OPENAI_IMAGE_DETAIL"))
+	switch config.ImageDetail {
+	case "low", "high":
+	case "", "auto":
+		// Auto is the API default, so it is not sent at all
+		config.ImageDetail = ""
+	default:
+		return nil, fmt.Errorf("invalid value for OPENAI_IMAGE_DETAIL: %q", config.ImageDetail)
+	}
+
+	minResultLength, err := getEnvInt("MIN_RESULT_LENGTH", 0)
+	if err != nil {
+		return nil, err
+	}
+	if minResultLength < 0 {
+		return nil, fmt.Errorf("MIN_RESULT_LENGTH must not be negative")
+	}
+	config.MinResultLength = minResultLength
+
+	stripMetadata, err := getEnvBool("STRIP_METADATA", true)
+	if err != nil {
+		return nil, err
+	}
+	config.StripMetadata = stripMetadata
+
+	textOnlyFallback, err := getEnvBool("TEXT_ONLY_FALLBACK", true)
+	if err != nil {
+		return nil, err
+	}
+	config.TextOnlyFallback = textOnlyFallback
+
+	autoRerun, err := getEnvBool("AUTO_RERUN", false)
+	if err != nil {
+		return nil, err
+	}
+	config.AutoRerun = autoRerun
+
+	featureBoxes, err := getEnvBool("FEATURE_BOXES", false)
+	if err != nil {
+		return nil, err
+	}
+	config.FeatureBoxes = featureBoxes
+
+	expertMode, err := getEnvBool("EXPERT_MODE", false)
+	if err != nil {
+		return nil, err
+	}
+	config.ExpertMode = expertMode
+
+	retryLow, err := getEnvBool("RETRY_LOW_CONFIDENCE", false)
+	if err != nil {
+		return nil, err
+	}
+	config.RetryLowConfidence = retryLow
+
+	calibrated, err := getEnvBool("CALIBRATED_CONFIDENCE", false)
+	if err != nil {
+		return nil, err
+	}
+	config.CalibratedConfidence = calibrated
+
+	maxExchanges, err := getEnvInt("MAX_DISPLAYED_EXCHANGES", 20)
+	if err != nil {
+		return nil, err
+	}
+	if maxExchanges < 0 {
+		return nil, fmt.Errorf("MAX_DISPLAYED_EXCHANGES must not be negative")
+	}
+	config.MaxDisplayedExchanges = maxExchanges
+
+	maxImages, err := getEnvInt("MAX_IMAGES_PER_REQUEST", defaultMaxImages(config.APIURL()))
+	if err != nil {
+		return nil, err
+	}
+	if maxImages < 0 {
+		return nil, fmt.Errorf("MAX_IMAGES_PER_REQUEST must not be negative")
+	}
+	config.MaxImagesPerRequest = maxImages
+
+	maxImageMB, err := getEnvFloat("MAX_IMAGE_MB", 20)
+	if err != nil {
+		return nil, err
 	}
+	if maxImageMB < 0 {
+		return nil, fmt.Errorf("MAX_IMAGE_MB must not be negative")
+	}
+	config.MaxImageBytes = int64(maxImageMB * 1024 * 1024)
+
+	maxMegapixels, err := getEnvFloat("MAX_IMAGE_MEGAPIXELS", 100)
+	if err != nil {
+		return nil, err
+	}
+	if maxMegapixels < 0 {
+		return nil, fmt.Errorf("MAX_IMAGE_MEGAPIXELS must not be negative")
+	}
+	config.MaxImagePixels = int64(maxMegapixels * 1e6)
+
+	maxDimension, err := getEnvInt("MAX_IMAGE_DIMENSION", 2048)
+	if err != nil {
+		return nil, err
+	}
+	if maxDimension < 0 {
+		return nil, fmt.Errorf("MAX_IMAGE_DIMENSION must not be negative")
+	}
+	config.MaxImageDimension = maxDimension
+
+	jpegQuality, err := getEnvInt("JPEG_QUALITY", 85)
+	if err != nil {
+		return nil, err
+	}
+	if jpegQuality < 1 || jpegQuality > 100 {
+		return nil, fmt.Errorf("JPEG_QUALITY must be between 1 and 100")
+	}
+	config.JPEGQuality = jpegQuality
+
+	shrinkMinQuality, err := getEnvInt("SHRINK_MIN_QUALITY", 0)
+	if err != nil {
+		return nil, err
+	}
+	if shrinkMinQuality < 0 || shrinkMinQuality > 100 {
+		return nil, fmt.Errorf("SHRINK_MIN_QUALITY must be between 0 and 100")
+	}
+	config.ShrinkMinQuality = shrinkMinQuality
+
+	letterboxRatio, err := getEnvFloat("LETTERBOX_RATIO", 0)
+	if err != nil {
+		return nil, err
+	}
+	if letterboxRatio < 0 {
+		return nil, fmt.Errorf("LETTERBOX_RATIO must not be negative")
+	}
+	config.LetterboxRatio = letterboxRatio
+
+	panoramaRatio, err := getEnvFloat("PANORAMA_MAX_RATIO", 4)
+	if err != nil {
+		return nil, err
+	}
+	if panoramaRatio != 0 && panoramaRatio < 1 {
+		return nil, fmt.Errorf("PANORAMA_MAX_RATIO must be 0 or at least 1")
+	}
+	config.PanoramaMaxRatio = panoramaRatio
+
+	config.PanoramaMode = strings.ToLower(getEnv("PANORAMA_MODE"))
+	switch config.PanoramaMode {
+	case "letterbox", "tile":
+	case "":
+		config.PanoramaMode = "letterbox"
+	default:
+		return nil, fmt.Errorf("invalid value for PANORAMA_MODE: %q", config.PanoramaMode)
+	}
+
+	enhanceDark, err := getEnvFloat("ENHANCE_DARK_THRESHOLD", 0)
+	if err != nil {
+		return nil, err
+	}
+	if enhanceDark < 0 || enhanceDark > 1 {
+		return nil, fmt.Errorf("ENHANCE_DARK_THRESHOLD must be between 0 and 1")
+	}
+	config.EnhanceDarkThreshold = enhanceDark
+
+	sharpness, err := getEnvFloat("SHARPNESS_THRESHOLD", 0)
+	if err != nil {
+		return nil, err
+	}
+	if sharpness < 0 {
+		return nil, fmt.Errorf("SHARPNESS_THRESHOLD must not be negative")
+	}
+	config.SharpnessThreshold = sharpness
+
+	classifyTimeout, err := getEnvInt("CLASSIFY_TIMEOUT_SECONDS", 60)
+	if err != nil {
+		return nil, err
+	}
+	if classifyTimeout < 0 {
+		return nil, fmt.Errorf("CLASSIFY_TIMEOUT_SECONDS must not be negative")
+	}
+	config.ClassifyTimeout = time.Duration(classifyTimeout) * time.Second
+
+	watchdogSeconds, err := getEnvInt("CLASSIFY_WATCHDOG_SECONDS", 300)
+	if err != nil {
+		return nil, err
+	}
+	if watchdogSeconds < 0 {
+		return nil, fmt.Errorf("CLASSIFY_WATCHDOG_SECONDS must not be negative")
+	}
+	config.WatchdogTimeout = time.Duration(watchdogSeconds) * time.Second
+
+	httpTimeout, err := getEnvInt("HTTP_TIMEOUT_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if httpTimeout <= 0 {
+		return nil, fmt.Errorf("HTTP_TIMEOUT_SECONDS must be positive")
+	}
+	config.HTTPTimeout = time.Duration(httpTimeout) * time.Second
+
+	requestsPerMinute, err := getEnvInt("REQUESTS_PER_MINUTE", 0)
+	if err != nil {
+		return nil, err
+	}
+	if requestsPerMinute < 0 {
+		return nil, fmt.Errorf("REQUESTS_PER_MINUTE must not be negative")
+	}
+	config.RequestsPerMinute = requestsPerMinute
+
+	batchConcurrency, err := getEnvInt("BATCH_CONCURRENCY", 1)
+	if err != nil {
+		return nil, err
+	}
+	if batchConcurrency < 1 {
+		return nil, fmt.Errorf("BATCH_CONCURRENCY must be at least 1")
+	}
+	config.BatchConcurrency = batchConcurrency
+
+	batchDelay, err := getEnvFloat("BATCH_DELAY_SECONDS", 1)
+	if err != nil {
+		return nil, err
+	}
+	if batchDelay < 0 {
+		return nil, fmt.Errorf("BATCH_DELAY_SECONDS must not be negative")
+	}
+	config.BatchDelay = time.Duration(batchDelay * float64(time.Second))
+
+	ensembleSize, err := getEnvInt("ENSEMBLE_SIZE", 1)
+	if err != nil {
+		return nil, err
+	}
+	if ensembleSize < 1 {
+		return nil, fmt.Errorf("ENSEMBLE_SIZE must be at least 1")
+	}
+	config.EnsembleSize = ensembleSize
+
+	temperature, err := getEnvOptionalFloat("OPENAI_TEMPERATURE")
+	if err != nil {
+		return nil, err
+	}
+	if temperature != nil && (*temperature < 0 || *temperature > 2) {
+		return nil, fmt.Errorf("OPENAI_TEMPERATURE must be between 0 and 2")
+	}
+	config.Temperature = temperature
+
+	topP, err := getEnvOptionalFloat("OPENAI_TOP_P")
+	if err != nil {
+		return nil, err
+	}
+	if topP != nil && (*topP < 0 || *topP > 1) {
+		return nil, fmt.Errorf("OPENAI_TOP_P must be between 0 and 1")
+	}
+	config.TopP = topP
+
+	// Unit system for measurements in the report (no conversion by default)
+	config.UnitSystem = strings.ToLower(getEnv("UNIT_SYSTEM"))
+	switch config.UnitSystem {
+	case "", "metric", "imperial":
+	case "none":
+		config.UnitSystem = ""
+	default:
+		return nil, fmt.Errorf("invalid value for UNIT_SYSTEM: %q", config.UnitSystem)
+	}
+
+	debug, err := getEnvBool("DEBUG", false)
+	if err != nil {
+		return nil, err
+	}
+	config.Debug = debug
+
+	config.QueueFile = getEnv("QUEUE_FILE")
+
+	resultCacheSize, err := getEnvInt("RESULT_CACHE_SIZE", 0)
+	if err != nil {
+		return nil, err
+	}
+	if resultCacheSize < 0 {
+		return nil, fmt.Errorf("RESULT_CACHE_SIZE must not be negative")
+	}
+	config.ResultCacheSize = resultCacheSize
+	config.ResultCacheFile = getEnv("RESULT_CACHE_FILE")
+	config.HistoryFile = getEnv("HISTORY_FILE")
+	config.JournalFile = getEnv("JOURNAL_FILE")
+	config.PromptFile = getEnv("PROMPT_FILE")
+
+	config.Theme = strings.ToLower(getEnv("THEME"))
+	switch config.Theme {
+	case "system", "light", "dark":
+	case "":
+		config.Theme = "system"
+	default:
+		return nil, fmt.Errorf("invalid value for THEME: %q", config.Theme)
+	}
+
+	// Logging destination (stderr unless configured)
+	config.LogDest = getEnv("LOG_DEST")
+	if config.LogDest == "" {
+		config.LogDest = "stderr"
+	}
+	config.LogFile = getEnv("LOG_FILE")
 
 	return config, nil
+}
+
+// defaultMaxImages returns the image limit for a provider's API URL
+//
+// OpenAI and Anthropic accept several images per message; other
+// OpenAI-compatible servers (e.g. local vision models) often handle only
+// one.
+func defaultMaxImages(apiURL string) int {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return 1
+	}
+	switch u.Host {
+	case "api.openai.com", "offline":
+		// The mock provider accepts as many images as OpenAI
+		return 10
+	case "api.anthropic.com":
+		return 20
+	default:
+		return 1
+	}
+}
+
+// mockAPIKey and mockAPIURL stand in for the credentials of the mock
+// provider, which never sends anything, so requests still validate
+const (
+	mockAPIKey = "mock"
+	mockAPIURL = "mock://offline"
+)
+
+// APIKey returns the API key of the selected provider
+func (c *Config) APIKey() string {
+	switch c.Provider {
+	case "anthropic":
+		return c.AnthropicAPIKey
+	case "mock":
+		return mockAPIKey
+	}
+	return c.OpenAIAPIKey
+}
+
+// APIURL returns the API endpoint of the selected provider
+func (c *Config) APIURL() string {
+	switch c.Provider {
+	case "anthropic":
+		return c.AnthropicAPIURL
+	case "mock":
+		return mockAPIURL
+	}
+	return c.OpenAIAPIURL
+}
+
+// ContentPath returns the response content path configured for an API URL
+//
+// Paths are looked up by the URL's host. Returns an empty string when no
+// path is configured, meaning the standard OpenAI response shape is used.
+func (c *Config) ContentPath(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	return c.ContentPaths[u.Host]
+}
+
+// parseContentPaths parses a comma-separated list of host=path pairs
+func parseContentPaths(value string) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		host, path, ok := strings.Cut(pair, "=")
+		host, path = strings.TrimSpace(host), strings.TrimSpace(path)
+		if !ok || host == "" || path == "" {
+			return nil, fmt.Errorf("invalid value for RESPONSE_CONTENT_PATHS: %q", pair)
+		}
+		paths[host] = path
+	}
+	return paths, nil
+}
+
+// getEnvBool reads a boolean environment variable
+//
+// Returns the default value when the variable is unset or empty. Accepts
+// the values understood by strconv.ParseBool (1, true, 0, false, etc.).
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := getEnv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %q", key, value)
+	}
+	return parsed, nil
+}
+
+// parseLanguage reads the LANGUAGE setting
+//
+// Many Linux desktops already set LANGUAGE to a gettext locale list such
+// as "en_US:en". Only the first entry is used, without an encoding
+// suffix, and English locales are treated as unset.
+func parseLanguage(value string) string {
+	language, _, _ := strings.Cut(strings.TrimSpace(value), ":")
+	language, _, _ = strings.Cut(language, ".")
+	if language == "en" || strings.HasPrefix(language, "en_") || strings.HasPrefix(language, "en-") {
+		return ""
+	}
+	return language
+}
+
+// getEnv returns the value of a setting
+//
+// The environment, including the .env file, wins over the config file.
+func getEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fileSetting(key)
+}
+
+// getEnvSecret reads a secret from an environment variable or a file
+//
+// The variable itself takes precedence. When it is empty, the file named
+// by the variable with a _FILE suffix (e.g. a Docker secret) is read
+// instead, with surrounding whitespace removed. Both are looked up in the
+// environment before the config file, so a key file set in the
+// environment wins over a key in the config file. Returns an empty
+// string when neither is set.
+func getEnvSecret(key string) (string, error) {
+	for _, lookup := range []func(string) string{os.Getenv, fileSetting} {
+		if value := lookup(key); value != "" {
+			return value, nil
+		}
+		if filename := lookup(key + "_FILE"); filename != "" {
+			return readSecretFile(key, filename)
+		}
+	}
+	return "", nil
+}
+
+// readSecretFile reads the secret for key from filename
+func readSecretFile(key, filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("%s_FILE %s is empty", key, filename)
+	}
+	return value, nil
+}
+
+// getEnvFloat reads a floating point environment variable
+//
+// Returns the default value when the variable is unset or empty.
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value := getEnv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %q", key, value)
+	}
+	return parsed, nil
+}
+
+// getEnvOptionalFloat reads a floating point environment variable that
+// may be left unset
+//
+// Returns nil when the variable is unset or empty, so that an explicit
+// zero can be told apart from no value.
+func getEnvOptionalFloat(key string) (*float64, error) {
+	value := getEnv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %q", key, value)
+	}
+	return &parsed, nil
+}
+
+// getEnvInt reads an integer environment variable
+//
+// Returns the default value when the variable is unset or empty.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := getEnv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %q", key, value)
+	}
+	return parsed, nil
 }
\ No newline at end of file
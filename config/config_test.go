@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// clearEnv unsets the settings the tests below look at, including those
+// of a config file loaded earlier
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"PROVIDER", "OPENAI_API_KEY", "OPENAI_API_KEY_FILE", "OPENAI_MODEL",
+		"OPENAI_MAX_TOKENS", "ANTHROPIC_API_KEY", "ANTHROPIC_API_KEY_FILE",
+	} {
+		t.Setenv(name, "")
+	}
+	// Keep a config file of the user out of the tests
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	saved := fileSettings
+	fileSettings = nil
+	t.Cleanup(func() { fileSettings = saved })
+}
+
+// writeFile writes content to a new file in a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFileJSONOnly(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.json",
+		`{"openai_api_key": "sk-file", "OPENAI_MODEL": "gpt-file", "openai_max_tokens": 321}`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OpenAIAPIKey != "sk-file" || cfg.Model != "gpt-file" || cfg.MaxTokens != 321 {
+		t.Errorf("got key %q, model %q, max tokens %d", cfg.OpenAIAPIKey, cfg.Model, cfg.MaxTokens)
+	}
+	if value := os.Getenv("OPENAI_MODEL"); value != "" {
+		t.Errorf("OPENAI_MODEL leaked into the environment as %q", value)
+	}
+}
+
+func TestLoadEnvOnly(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv("OPENAI_MODEL", "gpt-env")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OpenAIAPIKey != "sk-env" || cfg.Model != "gpt-env" {
+		t.Errorf("got key %q, model %q", cfg.OpenAIAPIKey, cfg.Model)
+	}
+}
+
+func TestLoadFileMerged(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_MODEL", "gpt-env")
+	path := writeFile(t, "config.json",
+		`{"openai_api_key": "sk-file", "openai_model": "gpt-file", "openai_max_tokens": 321}`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Model != "gpt-env" {
+		t.Errorf("Model = %q, want the environment to win", cfg.Model)
+	}
+	if cfg.OpenAIAPIKey != "sk-file" || cfg.MaxTokens != 321 {
+		t.Errorf("got key %q, max tokens %d, want them from the file", cfg.OpenAIAPIKey, cfg.MaxTokens)
+	}
+}
+
+func TestLoadFileEnvKeyFileWinsOverFileKey(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_API_KEY_FILE", writeFile(t, "key", "sk-secret\n"))
+	path := writeFile(t, "config.json", `{"openai_api_key": "sk-file"}`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OpenAIAPIKey != "sk-secret" {
+		t.Errorf("OpenAIAPIKey = %q, want the key file from the environment", cfg.OpenAIAPIKey)
+	}
+}
+
+func TestGetEnvSecretDirect(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+
+	value, err := getEnvSecret("OPENAI_API_KEY")
+	if err != nil || value != "sk-env" {
+		t.Errorf("got %q, %v, want sk-env", value, err)
+	}
+}
+
+func TestGetEnvSecretFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_API_KEY_FILE", writeFile(t, "key", "  sk-secret\n"))
+
+	value, err := getEnvSecret("OPENAI_API_KEY")
+	if err != nil || value != "sk-secret" {
+		t.Errorf("got %q, %v, want sk-secret", value, err)
+	}
+}
+
+func TestGetEnvSecretDirectWinsOverFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv("OPENAI_API_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	value, err := getEnvSecret("OPENAI_API_KEY")
+	if err != nil || value != "sk-env" {
+		t.Errorf("got %q, %v, want sk-env without reading the file", value, err)
+	}
+}
+
+func TestGetEnvSecretFileErrors(t *testing.T) {
+	clearEnv(t)
+	for name, path := range map[string]string{
+		"missing": filepath.Join(t.TempDir(), "missing"),
+		"empty":   writeFile(t, "key", " \n"),
+	} {
+		t.Setenv("OPENAI_API_KEY_FILE", path)
+		if value, err := getEnvSecret("OPENAI_API_KEY"); err == nil {
+			t.Errorf("%s file: got %q, want an error", name, value)
+		}
+	}
+}
+
+func TestGetEnvSecretUnset(t *testing.T) {
+	clearEnv(t)
+
+	value, err := getEnvSecret("OPENAI_API_KEY")
+	if err != nil || value != "" {
+		t.Errorf("got %q, %v, want no secret", value, err)
+	}
+}
+
+func TestLoadMaxTokensBounds(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+		ok    bool
+	}{
+		{"", 1000, true},
+		{"1", 1, true},
+		{strconv.Itoa(MaxTokensLimit), MaxTokensLimit, true},
+		{strconv.Itoa(MaxTokensLimit + 1), 0, false},
+		{"0", 0, false},
+		{"-5", 0, false},
+	}
+	for _, tt := range tests {
+		clearEnv(t)
+		t.Setenv("OPENAI_API_KEY", "sk-env")
+		t.Setenv("OPENAI_MAX_TOKENS", tt.value)
+
+		cfg, err := Load()
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("OPENAI_MAX_TOKENS=%q accepted as %d", tt.value, cfg.MaxTokens)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("OPENAI_MAX_TOKENS=%q: %v", tt.value, err)
+		} else if cfg.MaxTokens != tt.want {
+			t.Errorf("OPENAI_MAX_TOKENS=%q: MaxTokens = %d, want %d", tt.value, cfg.MaxTokens, tt.want)
+		}
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"German", "German"},
+		{" Français ", "Français"},
+		{"de_DE:de", "de_DE"},
+		{"de_DE.UTF-8:de", "de_DE"},
+		{"fr", "fr"},
+		{"en_US:en", ""},
+		{"en_GB.UTF-8", ""},
+		{"en-AU", ""},
+		{"en", ""},
+	}
+	for _, tt := range tests {
+		if got := parseLanguage(tt.value); got != tt.want {
+			t.Errorf("parseLanguage(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLoadLanguage(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv("LANGUAGE", "de_DE:de")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Language != "de_DE" {
+		t.Errorf("Language = %q, want de_DE", cfg.Language)
+	}
+}
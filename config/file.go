@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// settingNames lists every environment variable a config file may set
+//
+// Keep in sync with Load and .env.example.
+var settingNames = []string{
+	"PROVIDER",
+	"OPENAI_API_KEY", "OPENAI_API_KEY_FILE", "OPENAI_API_URL", "OPENAI_MODEL",
+	"OPENAI_ORG", "OPENAI_PROJECT",
+	"ANTHROPIC_API_KEY", "ANTHROPIC_API_KEY_FILE", "ANTHROPIC_API_URL", "ANTHROPIC_MODEL",
+	"MOCK_RESPONSE",
+	"OPENAI_SYSTEM_PROMPT", "LANGUAGE", "OPENAI_MAX_TOKENS", "CHECK_CONNECTION",
+	"OPENAI_STREAM", "OPENAI_RETRY_MALFORMED_JSON", "RESPONSE_CONTENT_PATHS",
+	"OPENAI_RAW_BASE64_IMAGE", "OPENAI_IMAGE_DETAIL", "MIN_RESULT_LENGTH",
+	"STRIP_METADATA", "TEXT_ONLY_FALLBACK", "AUTO_RERUN", "FEATURE_BOXES",
+	"EXPERT_MODE", "RETRY_LOW_CONFIDENCE", "CALIBRATED_CONFIDENCE",
+	"MAX_DISPLAYED_EXCHANGES", "MAX_IMAGES_PER_REQUEST",
+	"MAX_IMAGE_MB", "MAX_IMAGE_MEGAPIXELS", "MAX_IMAGE_DIMENSION", "JPEG_QUALITY",
+	"SHRINK_MIN_QUALITY", "LETTERBOX_RATIO", "PANORAMA_MAX_RATIO", "PANORAMA_MODE",
+	"ENHANCE_DARK_THRESHOLD", "SHARPNESS_THRESHOLD",
+	"CLASSIFY_TIMEOUT_SECONDS", "CLASSIFY_WATCHDOG_SECONDS",
+	"API_PROXY", "API_CA_FILE", "HTTP_TIMEOUT_SECONDS",
+	"REQUESTS_PER_MINUTE", "BATCH_CONCURRENCY", "BATCH_DELAY_SECONDS",
+	"ENSEMBLE_SIZE", "OPENAI_TEMPERATURE", "OPENAI_TOP_P", "UNIT_SYSTEM",
+	"DEBUG", "QUEUE_FILE", "RESULT_CACHE_SIZE", "RESULT_CACHE_FILE",
+	"HISTORY_FILE", "JOURNAL_FILE", "PROMPT_FILE",
+	"THEME", "LOG_DEST", "LOG_FILE",
+}
+
+// DefaultFilePath returns the config file in the user's config directory
+func DefaultFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "mushroom-classifier", "config.json"), nil
+}
+
+// fileSettings holds the settings of the config file read by the last
+// LoadFile, by environment variable name
+//
+// They are kept apart from the process environment, which wins over
+// them (see getEnv).
+var fileSettings map[string]string
+
+// fileSetting returns the value of a setting in the config file
+func fileSetting(key string) string {
+	return fileSettings[key]
+}
+
+// loadFile reads the settings of a JSON config file
+//
+// The file holds one object whose keys are the names of the environment
+// variables, in any case (e.g. "openai_model"), and whose values are
+// strings, numbers or booleans. The settings are returned by variable
+// name; the environment and .env file win over them. Unknown keys are
+// reported as warnings. A missing file is an error only if required is
+// set.
+func loadFile(path string, required bool) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var settings map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	// Apply in a fixed order so warnings come out the same every time
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		name := strings.ToUpper(key)
+		if !knownSetting(name) {
+			warnUnknownSetting(key, path)
+			continue
+		}
+
+		value, err := settingValue(settings[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s in %s: %w", key, path, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// knownSetting reports whether name is a setting the config file may set
+func knownSetting(name string) bool {
+	for _, known := range settingNames {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// settingValue converts a JSON value to the text of an environment variable
+func settingValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case nil:
+		return "", nil
+	default:
+		return "", errors.New("expected a string, number or boolean")
+	}
+}
+
+// warnUnknownSetting logs an unknown key, suggesting a close known name
+func warnUnknownSetting(key, path string) {
+	name := strings.ToUpper(key)
+	best, bestDistance := "", 3
+	for _, known := range settingNames {
+		if d := editDistance(name, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+
+	if best != "" {
+		log.Printf("Warning: unknown setting %q in %s (did you mean %q?)", key, path, strings.ToLower(best))
+		return
+	}
+	log.Printf("Warning: unknown setting %q in %s", key, path)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,108 @@
+// Command classify is a runnable example of the openai package
+//
+// It classifies an image against a local test server that answers like
+// the OpenAI chat completions API, so it needs neither an API key nor a
+// network connection:
+//
+//	go run ./examples/classify [image]
+//
+// Without an image a small generated one is sent. Replace the server URL
+// and key with real ones to classify against the API.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+	"github.com/mushroom-classifier/mushroom-classifier-go/provider"
+)
+
+func main() {
+	data, err := loadImage(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load image: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(answer))
+	defer server.Close()
+
+	// Build the request as the GUI and command line mode do
+	req := &openai.Request{
+		APIKey:      "example-key",
+		APIURL:      server.URL + "/v1/chat/completions",
+		Model:       "gpt-4o-mini",
+		Prompt:      prompts.Mushroom(),
+		Base64Image: base64.EncodeData(data),
+		MimeType:    base64.DetectMimeType(data),
+		MaxTokens:   500,
+	}
+
+	resp, err := openai.AnalyzeImage(req)
+	if err != nil {
+		log.Fatalf("Request was cancelled: %v", err)
+	}
+	if err := resp.Err(); err != nil {
+		log.Fatalf("Analysis failed: %v", err)
+	}
+
+	fmt.Println(resp.Content)
+	fmt.Printf("\n%s · %d tokens\n", resp.Meta, resp.TotalTokens)
+}
+
+// loadImage reads the image named in args, or generates one
+func loadImage(args []string) ([]byte, error) {
+	if len(args) > 0 {
+		return base64.ReadImage(args[0])
+	}
+
+	// A small brown cap on a green background
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			c := color.RGBA{G: 140, A: 255}
+			if dx, dy := x-32, y-28; dx*dx+dy*dy*4 < 400 {
+				c = color.RGBA{R: 140, G: 90, B: 40, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// answer replies to a chat completion request with the mock sample
+func answer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model string `json:"model"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": {"message": "invalid request body"}}`, http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]any{
+		"model": req.Model,
+		"choices": []map[string]any{{
+			"message":       map[string]string{"role": "assistant", "content": provider.MockContent},
+			"finish_reason": "stop",
+		}},
+		"usage": map[string]int{"prompt_tokens": 850, "completion_tokens": 150, "total_tokens": 1000},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
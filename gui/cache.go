@@ -0,0 +1,32 @@
+package gui
+
+import (
+	"log"
+	"sync"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/cache"
+	"github.com/mushroom-classifier/mushroom-classifier-go/config"
+)
+
+// Result cache shared by all windows, loaded on first use
+var (
+	resultCacheOnce sync.Once
+	resultCache     *cache.Cache
+)
+
+// sharedResultCache returns the process-wide result cache
+//
+// Returns nil, which caches nothing, if the cache is disabled. A cache
+// file that cannot be read is reported and replaced by a cache kept in
+// memory, so the GUI still starts.
+func sharedResultCache(cfg *config.Config) *cache.Cache {
+	resultCacheOnce.Do(func() {
+		var err error
+		resultCache, err = cache.Open(cfg.ResultCacheFile, cfg.ResultCacheSize)
+		if err != nil {
+			log.Printf("Warning: %v; results are cached in memory only", err)
+			resultCache, _ = cache.Open("", cfg.ResultCacheSize)
+		}
+	})
+	return resultCache
+}
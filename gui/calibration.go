@@ -0,0 +1,143 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+)
+
+// probabilityTolerance allows for rounding in model-reported probabilities
+const probabilityTolerance = 0.05
+
+// Candidate is a possible species with the model's probability estimate
+type Candidate struct {
+	// Species name (common and scientific)
+	Name string `json:"name"`
+
+	// Calibrated probability between 0 and 1
+	Probability float64 `json:"probability"`
+}
+
+// UnmarshalJSON decodes a candidate, which must state its probability
+func (c *Candidate) UnmarshalJSON(data []byte) error {
+	type plain Candidate
+	var v struct {
+		plain
+		Probability *float64 `json:"probability"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Probability == nil {
+		return fmt.Errorf("candidate %q has no probability", v.Name)
+	}
+
+	*c = Candidate(v.plain)
+	c.Probability = *v.Probability
+	return nil
+}
+
+// StructuredResult is the JSON answer requested by the calibrated prompt
+type StructuredResult struct {
+	// Most likely species
+	Species string `json:"species"`
+
+	// Coarse confidence level (High/Medium/Low)
+	Confidence string `json:"confidence"`
+
+	// Calibrated probability that Species is correct
+	Probability float64 `json:"probability"`
+
+	// Top candidate species, most likely first
+	Candidates []Candidate `json:"candidates"`
+
+	// Edibility and safety notes
+	Summary string `json:"summary"`
+}
+
+// parseStructuredResult parses and validates a calibrated JSON answer
+//
+// Candidates are sorted by descending probability. Returns an error if
+// the JSON is invalid, a candidate lacks its probability or the
+// probabilities are inconsistent.
+func parseStructuredResult(content string) (*StructuredResult, error) {
+	var result StructuredResult
+	if err := json.Unmarshal([]byte(analysis.StripCodeFence(content)), &result); err != nil {
+		return nil, fmt.Errorf("invalid structured result JSON: %w", err)
+	}
+
+	if err := validateCandidates(result.Candidates); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result.Candidates, func(i, j int) bool {
+		return result.Candidates[i].Probability > result.Candidates[j].Probability
+	})
+
+	return &result, nil
+}
+
+// validateCandidates checks that candidate probabilities are plausible
+//
+// Each probability must lie in [0, 1] and together they may not exceed 1
+// (with a small tolerance for rounding). The sum may be below 1 since
+// the list only covers the top candidates.
+func validateCandidates(candidates []Candidate) error {
+	sum := 0.0
+	for _, c := range candidates {
+		if math.IsNaN(c.Probability) || c.Probability < 0 || c.Probability > 1 {
+			return fmt.Errorf("candidate %q has invalid probability %v", c.Name, c.Probability)
+		}
+		sum += c.Probability
+	}
+
+	if sum > 1+probabilityTolerance {
+		return fmt.Errorf("candidate probabilities sum to %.2f, more than 1", sum)
+	}
+
+	return nil
+}
+
+// formatStructuredResult renders a calibrated result as a ranked list
+func formatStructuredResult(result *StructuredResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Species: %s (%.0f%%)\n", result.Species, result.Probability*100)
+	fmt.Fprintf(&b, "Confidence: %s\n", result.Confidence)
+
+	if len(result.Candidates) > 0 {
+		b.WriteString("\nTop candidates:\n")
+		for i, c := range result.Candidates {
+			fmt.Fprintf(&b, "%d. %s — %.0f%%\n", i+1, c.Name, c.Probability*100)
+		}
+	}
+
+	if result.Summary != "" {
+		b.WriteString("\n")
+		b.WriteString(result.Summary)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// getCalibratedPrompt returns the JSON-mode prompt asking for probabilities
+func getCalibratedPrompt() string {
+	return `You are an expert mycologist. Analyze this image of a mushroom and return ONLY a JSON object, with no other text, in this form:
+
+{
+  "species": "Common name (Scientific name)",
+  "confidence": "High|Medium|Low",
+  "probability": 0.0,
+  "candidates": [{"name": "Common name (Scientific name)", "probability": 0.0}],
+  "summary": "Key features, edibility, safety warning and look-alikes"
+}
+
+"probability" is your calibrated probability (0 to 1) that "species" is correct. List your top 3 candidate species in "candidates", including the top species, with calibrated probabilities that sum to at most 1.
+
+IMPORTANT: Always err on the side of caution. If uncertain, give low probabilities. Never encourage consumption of wild mushrooms without expert verification.`
+}
@@ -0,0 +1,66 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStructuredResult(t *testing.T) {
+	content := "```json\n" + `{
+		"species": "Golden chanterelle (Cantharellus cibarius)",
+		"confidence": "High",
+		"probability": 0.7,
+		"candidates": [
+			{"name": "False chanterelle (Hygrophoropsis aurantiaca)", "probability": 0.2},
+			{"name": "Golden chanterelle (Cantharellus cibarius)", "probability": 0.7},
+			{"name": "Jack-o'-lantern (Omphalotus olearius)", "probability": 0.1}
+		],
+		"summary": "Edible when correctly identified."
+	}` + "\n```"
+
+	result, err := parseStructuredResult(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Species != "Golden chanterelle (Cantharellus cibarius)" || result.Probability != 0.7 {
+		t.Errorf("got %+v", result)
+	}
+
+	// Ranked by probability
+	want := []float64{0.7, 0.2, 0.1}
+	for i, c := range result.Candidates {
+		if c.Probability != want[i] {
+			t.Errorf("candidate %d = %+v, want probability %v", i, c, want[i])
+		}
+	}
+
+	if text := formatStructuredResult(result); !strings.Contains(text, "1. Golden chanterelle (Cantharellus cibarius) — 70%") {
+		t.Errorf("formatted result:\n%s", text)
+	}
+}
+
+func TestParseStructuredResultProbabilities(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates string
+		wantErr    string
+	}{
+		{"sum to 1", `[{"name": "A", "probability": 0.6}, {"name": "B", "probability": 0.4}]`, ""},
+		{"sum below 1", `[{"name": "A", "probability": 0.5}, {"name": "B", "probability": 0.2}]`, ""},
+		{"rounding", `[{"name": "A", "probability": 0.67}, {"name": "B", "probability": 0.34}]`, ""},
+		{"sum above 1", `[{"name": "A", "probability": 0.8}, {"name": "B", "probability": 0.5}]`, "more than 1"},
+		{"missing probability", `[{"name": "A", "probability": 0.6}, {"name": "B"}]`, `"B" has no probability`},
+		{"percentage string", `[{"name": "A", "probability": "60%"}]`, "invalid structured result JSON"},
+		{"above 1", `[{"name": "A", "probability": 60}]`, "invalid probability"},
+		{"negative", `[{"name": "A", "probability": -0.1}]`, "invalid probability"},
+	}
+	for _, tt := range tests {
+		_, err := parseStructuredResult(`{"species": "A", "candidates": ` + tt.candidates + `}`)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// errBusy is reported when an image is loaded or another request is
+// started while an analysis runs, as answers would otherwise be shown
+// for the wrong image or overwrite each other
+var errBusy = errors.New("an analysis is running; wait for it to finish or click Cancel")
+
+// requestCanceller tracks the running API call and holds its cancel
+// function
+//
+// Classification, follow-up questions, explanations, feature detection,
+// expert requests and the queue never run at the same time, so one slot
+// is enough. It is shared between the UI and the
+// goroutine performing the call.
+type requestCanceller struct {
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	running bool
+}
+
+// begin returns a context for a new API call
+//
+// Returns false if a call is already running, e.g. after a double click
+// on a button that was not disabled yet.
+func (r *requestCanceller) begin() (context.Context, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.running = true
+	return ctx, true
+}
+
+// abort cancels the running API call, if any
+//
+// The call counts as running until end is called.
+func (r *requestCanceller) abort() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// end releases the context of the finished API call
+func (r *requestCanceller) end() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	r.running = false
+}
+
+// busy reports whether an API call is running
+func (r *requestCanceller) busy() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// withOptionalTimeout is like context.WithTimeout but does not limit ctx
+// if timeout is not positive
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timedOut reports whether a call under ctx failed because its timeout
+// expired, as opposed to failing on its own or being cancelled
+func timedOut(ctx context.Context, resp *openai.Response, err error) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded) && (err != nil || !resp.Success)
+}
+
+// beginRequest starts a cancellable API call
+//
+// Enables the Cancel button and shows the progress spinner until
+// endRequest is called. Returns false, changing nothing, if another call
+// is still running.
+func (app *App) beginRequest() (context.Context, bool) {
+	ctx, ok := app.canceller.begin()
+	if !ok {
+		return nil, false
+	}
+	app.CancelButton.Enable()
+	app.StreamProgress.Hide()
+	app.Spinner.Show()
+	app.Spinner.Start()
+	return ctx, true
+}
+
+// endRequest releases the context of a finished API call and stops the
+// progress spinner, whether the call succeeded, failed or was cancelled
+func (app *App) endRequest() {
+	app.canceller.end()
+	app.CancelButton.Disable()
+	app.Spinner.Stop()
+	app.Spinner.Hide()
+}
+
+// onCancelClicked aborts the running API call
+func (app *App) onCancelClicked() {
+	app.CancelButton.Disable()
+	app.StatusLabel.SetText("Cancelling...")
+	app.canceller.abort()
+}
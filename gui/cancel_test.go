@@ -0,0 +1,82 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/provider"
+)
+
+func TestRequestCancellerRefusesSecondCall(t *testing.T) {
+	var r requestCanceller
+
+	ctx, ok := r.begin()
+	if !ok {
+		t.Fatal("first begin refused")
+	}
+	if _, ok := r.begin(); ok {
+		t.Fatal("second begin accepted while the first call runs")
+	}
+	if !r.busy() {
+		t.Error("busy = false while a call runs")
+	}
+
+	// A cancelled call still runs until its goroutine ends it
+	r.abort()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("ctx.Err() = %v after abort", ctx.Err())
+	}
+	if _, ok := r.begin(); ok {
+		t.Fatal("begin accepted before the aborted call ended")
+	}
+
+	r.end()
+	if r.busy() {
+		t.Error("busy = true after end")
+	}
+	if _, ok := r.begin(); !ok {
+		t.Error("begin refused after the call ended")
+	}
+}
+
+func TestTimedOut(t *testing.T) {
+	req := &openai.Request{APIKey: "mock", APIURL: "http://mock.invalid/v1/chat/completions", Prompt: "Identify this mushroom"}
+	slow := provider.Mock{Delay: time.Second}
+
+	// The timeout expires while the mock is still waiting
+	ctx, stop := withOptionalTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	start := time.Now()
+	resp, err := slow.AnalyzeImage(ctx, req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the deadline to expire", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("mock answered after %s, want it to stop at the timeout", elapsed)
+	}
+	if !timedOut(ctx, resp, err) {
+		t.Error("timedOut = false after the timeout expired")
+	}
+
+	// Cancelling is not a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp, err = slow.AnalyzeImage(ctx, req)
+	if timedOut(ctx, resp, err) {
+		t.Errorf("timedOut = true after cancelling (err %v)", err)
+	}
+
+	// No timeout without a limit, however slow the answer
+	ctx, stop = withOptionalTimeout(context.Background(), 0)
+	defer stop()
+	resp, err = provider.Mock{Delay: 30 * time.Millisecond}.AnalyzeImage(ctx, req)
+	if err != nil || !resp.Success {
+		t.Fatalf("got %+v, %v, want the mock answer", resp, err)
+	}
+	if timedOut(ctx, resp, err) {
+		t.Error("timedOut = true without a timeout")
+	}
+}
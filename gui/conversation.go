@@ -0,0 +1,177 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// exchange is one question and answer about the current image
+type exchange struct {
+	// Prompt sent to the model
+	Prompt string
+
+	// Question shown to the user (empty for the initial classification)
+	Question string
+
+	// Model answer as displayed
+	Answer string
+}
+
+// conversation holds the exchanges about the current image
+//
+// The full log is always kept (e.g. for export); only the rendered view
+// shown in the GUI is trimmed.
+type conversation struct {
+	exchanges []exchange
+}
+
+// reset discards all exchanges
+func (c *conversation) reset() {
+	c.exchanges = nil
+}
+
+// add appends an exchange
+func (c *conversation) add(e exchange) {
+	c.exchanges = append(c.exchanges, e)
+}
+
+// empty reports whether the conversation has no exchanges yet
+func (c *conversation) empty() bool {
+	return len(c.exchanges) == 0
+}
+
+// history returns the conversation as prior turns for a follow-up request
+func (c *conversation) history() []openai.Turn {
+	turns := make([]openai.Turn, 0, 2*len(c.exchanges))
+	for _, e := range c.exchanges {
+		turns = append(turns,
+			openai.Turn{Role: "user", Text: e.Prompt},
+			openai.Turn{Role: "assistant", Text: e.Answer},
+		)
+	}
+	return turns
+}
+
+// render formats the most recent exchanges for display
+//
+// At most limit exchanges are shown, newest last, below the initial
+// classification they refer to, which always stays on top. Exchanges in
+// between are replaced by a note saying how many were collapsed. A
+// non-positive limit renders the whole conversation. Shown exchanges are
+// never truncated.
+func (c *conversation) render(limit int) string {
+	var pinned []exchange
+	shown := c.exchanges
+	if len(shown) > 0 && shown[0].Question == "" {
+		pinned, shown = shown[:1], shown[1:]
+	}
+
+	collapsed := 0
+	if limit > 0 && len(shown) > limit {
+		collapsed = len(shown) - limit
+		shown = shown[collapsed:]
+	}
+
+	var parts []string
+	for _, e := range pinned {
+		parts = append(parts, renderExchange(e))
+	}
+	if collapsed > 0 {
+		noun := "exchanges"
+		if collapsed == 1 {
+			noun = "exchange"
+		}
+		parts = append(parts, fmt.Sprintf("[%d older %s collapsed; the full log is kept for export]", collapsed, noun))
+	}
+	for _, e := range shown {
+		parts = append(parts, renderExchange(e))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderExchange formats one exchange, with the question if there is one
+func renderExchange(e exchange) string {
+	if e.Question == "" {
+		return e.Answer
+	}
+	return fmt.Sprintf("You: %s\n\n%s", e.Question, e.Answer)
+}
+
+// onAskClicked sends a follow-up question about the current result
+func (app *App) onAskClicked() {
+	question := strings.TrimSpace(app.FollowUpEntry.Text)
+	if question == "" || app.conversation.empty() {
+		return
+	}
+
+	ctx, ok := app.beginRequest()
+	if !ok {
+		return
+	}
+
+	app.UploadButton.Disable()
+	app.ClassifyButton.Disable()
+	app.AskButton.Disable()
+	app.StatusLabel.SetText("Asking follow-up question...")
+
+	req := app.newRequest(app.currentParams(), question)
+	req.History = app.conversation.history()
+	req.MinContentLength = 0
+
+	go func() {
+		resp, err := app.provider.AnalyzeImage(ctx, req)
+		if err == nil && !resp.Success {
+			err = fmt.Errorf(resp.ErrorMessage)
+		}
+		app.endRequest()
+
+		if errors.Is(err, context.Canceled) {
+			app.StatusLabel.SetText("Follow-up cancelled")
+		} else if err != nil {
+			app.showError("Follow-up failed", err)
+			app.StatusLabel.SetText("Follow-up failed")
+		} else {
+			app.conversation.add(exchange{Prompt: question, Question: question, Answer: resp.Content})
+			app.refreshConversation()
+			app.FollowUpEntry.SetText("")
+			app.StatusLabel.SetText(withTruncationWarning("Answer received", resp))
+			app.showMeta(resp.Meta)
+		}
+
+		app.UploadButton.Enable()
+		app.ClassifyButton.Enable()
+		app.AskButton.Enable()
+	}()
+}
+
+// startConversation replaces the conversation with a new classification
+func (app *App) startConversation(prompt, answer string) {
+	app.conversation.reset()
+	app.conversation.add(exchange{Prompt: prompt, Answer: answer})
+	app.resultTime = time.Now()
+	app.refreshConversation()
+	app.AskButton.Enable()
+	app.JournalButton.Enable()
+	app.updateWhyButton(answer)
+	app.showRisk(answer)
+}
+
+// refreshConversation shows the trimmed conversation in the result view
+//
+// Measurements are annotated in the configured unit system; the stored
+// conversation keeps the model's original wording. A line describing the
+// image comes first.
+func (app *App) refreshConversation() {
+	text := convertUnits(app.conversation.render(app.Config.MaxDisplayedExchanges), app.Config.UnitSystem)
+	if header := app.resultHeader(); header != "" {
+		text = header + "\n\n" + text
+	}
+	app.setResultText(text)
+	app.CopyButton.Enable()
+	app.SaveButton.Enable()
+}
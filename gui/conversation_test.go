@@ -0,0 +1,64 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// followUps returns a conversation of a classification and n follow-ups
+func followUps(n int) *conversation {
+	c := &conversation{}
+	c.add(exchange{Prompt: "Identify this mushroom", Answer: "Chanterelle"})
+	for i := 1; i <= n; i++ {
+		question := fmt.Sprintf("Question %d?", i)
+		c.add(exchange{Prompt: question, Question: question, Answer: fmt.Sprintf("Answer %d.", i)})
+	}
+	return c
+}
+
+func TestConversationRenderCollapsesOldest(t *testing.T) {
+	got := followUps(5).render(2)
+
+	want := "Chanterelle\n\n" +
+		"[3 older exchanges collapsed; the full log is kept for export]\n\n" +
+		"You: Question 4?\n\nAnswer 4.\n\n" +
+		"You: Question 5?\n\nAnswer 5."
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConversationRenderWithinLimit(t *testing.T) {
+	for _, limit := range []int{0, 3, 10} {
+		got := followUps(3).render(limit)
+		if strings.Contains(got, "collapsed") {
+			t.Errorf("limit %d: collapsed within the limit:\n%s", limit, got)
+		}
+		for _, want := range []string{"Chanterelle", "You: Question 1?\n\nAnswer 1.", "You: Question 3?\n\nAnswer 3."} {
+			if !strings.Contains(got, want) {
+				t.Errorf("limit %d: %q missing:\n%s", limit, want, got)
+			}
+		}
+	}
+
+	if got := followUps(2).render(1); !strings.Contains(got, "[1 older exchange collapsed;") {
+		t.Errorf("singular note missing:\n%s", got)
+	}
+}
+
+func TestConversationHistoryKeepsFullLog(t *testing.T) {
+	c := followUps(5)
+	c.render(2)
+
+	turns := c.history()
+	if len(turns) != 12 {
+		t.Fatalf("got %d turns, want 12", len(turns))
+	}
+	if turns[0].Role != "user" || turns[0].Text != "Identify this mushroom" || turns[1].Text != "Chanterelle" {
+		t.Errorf("first turns = %+v, want the classification", turns[:2])
+	}
+	if last := turns[11]; last.Role != "assistant" || last.Text != "Answer 5." {
+		t.Errorf("last turn = %+v", last)
+	}
+}
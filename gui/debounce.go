@@ -0,0 +1,36 @@
+package gui
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer delays an action until a burst of triggers has settled
+//
+// Each call to trigger restarts the delay, so only the last action of a
+// rapid series runs, once no further trigger arrives within the delay.
+// The timer fires on its own goroutine, so the action is handed to run,
+// which should execute it where widgets may be touched.
+type debouncer struct {
+	mu    sync.Mutex
+	delay time.Duration
+	run   func(func())
+	timer *time.Timer
+}
+
+// newDebouncer creates a debouncer with the given settle delay whose
+// actions are executed by run
+func newDebouncer(delay time.Duration, run func(func())) *debouncer {
+	return &debouncer{delay: delay, run: run}
+}
+
+// trigger schedules fn to run after the delay, replacing any pending action
+func (d *debouncer) trigger(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, func() { d.run(fn) })
+}
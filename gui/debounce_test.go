@@ -0,0 +1,43 @@
+package gui
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncerRunsLastActionThroughRunner(t *testing.T) {
+	var dispatched atomic.Int32
+	done := make(chan int, 3)
+	d := newDebouncer(20*time.Millisecond, func(fn func()) {
+		dispatched.Add(1)
+		fn()
+	})
+
+	for i := 1; i <= 3; i++ {
+		i := i
+		d.trigger(func() { done <- i })
+	}
+
+	select {
+	case got := <-done:
+		if got != 3 {
+			t.Errorf("ran action %d, want the last one", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("action never ran")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := dispatched.Load(); n != 1 {
+		t.Errorf("runner called %d times, want 1", n)
+	}
+}
+
+func TestRunOnUIWithoutApp(t *testing.T) {
+	ran := false
+	runOnUI(nil, func() { ran = true })
+	if !ran {
+		t.Error("fn not run")
+	}
+}
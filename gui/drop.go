@@ -0,0 +1,41 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// onDropped loads an image file dragged onto the window
+//
+// Fyne delivers the dropped text/uri-list already parsed into URIs. Only
+// the first one is used; anything that is not a local file with an image
+// extension is rejected.
+func (app *App) onDropped(_ fyne.Position, uris []fyne.URI) {
+	if len(uris) == 0 {
+		return
+	}
+	uri := uris[0]
+
+	if uri.Scheme() != "file" {
+		app.showError("Cannot open dropped item", fmt.Errorf("%s is not a local file", uri.String()))
+		return
+	}
+	if !isImageExtension(uri.Extension()) {
+		app.showError("Cannot open dropped item", fmt.Errorf("%s is not an image file", uri.Name()))
+		return
+	}
+
+	app.openImage(uri.Path())
+}
+
+// isImageExtension reports whether ext is one of imageExtensions
+func isImageExtension(ext string) bool {
+	for _, known := range imageExtensions {
+		if strings.EqualFold(ext, known) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/provider"
+)
+
+// ensembleVote picks the species most answers agree on
+//
+// Each result is parsed for its scientific name and the most common one
+// wins. Agreement is the fraction of all results naming the winner.
+// When several species tie for the most votes there is no consensus:
+// the winner is empty and agreement is the tied share.
+func ensembleVote(results []string) (winner string, agreement float64) {
+	if len(results) == 0 {
+		return "", 0
+	}
+
+	votes := make(map[string]int)
+	for _, content := range results {
+		if species := analysis.ParseSpecies(content); species != "" {
+			votes[species]++
+		}
+	}
+
+	// Rank by votes, then by name for a stable order
+	ranked := make([]string, 0, len(votes))
+	for species := range votes {
+		ranked = append(ranked, species)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if votes[ranked[i]] != votes[ranked[j]] {
+			return votes[ranked[i]] > votes[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	if len(ranked) == 0 {
+		return "", 0
+	}
+
+	top := votes[ranked[0]]
+	agreement = float64(top) / float64(len(results))
+	if len(ranked) > 1 && votes[ranked[1]] == top {
+		return "", agreement
+	}
+	return ranked[0], agreement
+}
+
+// classifyEnsemble runs the request several times and reports the
+// consensus
+//
+// The returned Response holds the answer of a run that named the
+// winning species, preceded by a line stating the agreement level. It
+// fails only when every run failed.
+func classifyEnsemble(ctx context.Context, p provider.Provider, req *openai.Request, n int) (*openai.Response, error) {
+	// Cached answers would make every run agree with an earlier one
+	uncached := *req
+	uncached.Cache = nil
+	responses, err := provider.AnalyzeMulti(ctx, p, &uncached, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var succeeded []*openai.Response
+	var contents []string
+	for _, resp := range responses {
+		if resp.Success {
+			succeeded = append(succeeded, resp)
+			contents = append(contents, resp.Content)
+		}
+	}
+	if len(succeeded) == 0 {
+		return responses[0], nil
+	}
+
+	winner, agreement := ensembleVote(contents)
+
+	// Show the answer of a run that agrees with the vote
+	chosen := succeeded[0]
+	for _, resp := range succeeded {
+		if winner != "" && analysis.ParseSpecies(resp.Content) == winner {
+			chosen = resp
+			break
+		}
+	}
+
+	summary := fmt.Sprintf("Ensemble: no consensus across %d runs (top species at %.0f%% agreement)",
+		len(responses), agreement*100)
+	if winner != "" {
+		summary = fmt.Sprintf("Ensemble: %s in %.0f%% of %d runs",
+			winner, agreement*100, len(responses))
+	}
+
+	result := *chosen
+	result.Content = summary + "\n\n" + chosen.Content
+	return &result, nil
+}
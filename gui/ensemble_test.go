@@ -0,0 +1,35 @@
+package gui
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEnsembleVote(t *testing.T) {
+	const (
+		chanterelle = "Golden chanterelle (Cantharellus cibarius)"
+		falseChant  = "False chanterelle (Hygrophoropsis aurantiaca)"
+		flyAgaric   = "**Scientific name**: Amanita muscaria"
+	)
+
+	tests := []struct {
+		name      string
+		results   []string
+		winner    string
+		agreement float64
+	}{
+		{"unanimous", []string{chanterelle, chanterelle, chanterelle}, "Cantharellus cibarius", 1},
+		{"majority", []string{chanterelle, falseChant, chanterelle}, "Cantharellus cibarius", 2.0 / 3},
+		{"majority of all runs", []string{flyAgaric, flyAgaric, chanterelle, "No idea"}, "Amanita muscaria", 0.5},
+		{"tie", []string{chanterelle, falseChant}, "", 0.5},
+		{"three-way tie", []string{chanterelle, falseChant, flyAgaric}, "", 1.0 / 3},
+		{"no species", []string{"Unclear photo", "Cannot tell"}, "", 0},
+		{"no results", nil, "", 0},
+	}
+	for _, tt := range tests {
+		winner, agreement := ensembleVote(tt.results)
+		if winner != tt.winner || math.Abs(agreement-tt.agreement) > 1e-9 {
+			t.Errorf("%s: got %q at %.3f, want %q at %.3f", tt.name, winner, agreement, tt.winner, tt.agreement)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// expertTemplate is the messages array the expert editor starts with
+const expertTemplate = `[
+  {"role": "system", "content": "You are an expert mycologist."},
+  {"role": "user", "content": [
+    {"type": "text", "text": "Identify this mushroom."},
+    {"type": "image_url", "image_url": {"url": "` + openai.ImagePlaceholder + `"}}
+  ]}
+]`
+
+// onExpertClicked opens a window for sending a raw messages array
+//
+// The array is sent as-is with the current model and API settings; the
+// loaded image and added views are inserted wherever the placeholder
+// appears. Answers are shown in the window, not added to the
+// conversation.
+func (app *App) onExpertClicked() {
+	window := app.FyneApp.NewWindow("Expert Mode")
+	window.Resize(fyne.NewSize(700, 600))
+
+	messagesEntry := widget.NewMultiLineEntry()
+	messagesEntry.SetText(expertTemplate)
+	messagesEntry.SetMinRowsVisible(12)
+	messagesEntry.Validator = func(text string) error {
+		return openai.ValidateMessages([]byte(text))
+	}
+
+	answerView := widget.NewMultiLineEntry()
+	answerView.Wrapping = fyne.TextWrapWord
+	answerView.Disable()
+
+	status := widget.NewLabel(fmt.Sprintf("Use %s to insert the loaded image", openai.ImagePlaceholder))
+
+	var sendButton *widget.Button
+	sendButton = widget.NewButton("Send", func() {
+		data := []byte(messagesEntry.Text)
+		if err := openai.ValidateMessages(data); err != nil {
+			status.SetText(fmt.Sprintf("Invalid messages: %v", err))
+			return
+		}
+
+		ctx, ok := app.beginRequest()
+		if !ok {
+			status.SetText(fmt.Sprintf("Cannot send: %v", errBusy))
+			return
+		}
+
+		req := app.newRequest(app.currentParams(), "")
+
+		sendButton.Disable()
+		status.SetText("Sending...")
+		answerView.SetText("")
+
+		go func() {
+			defer sendButton.Enable()
+
+			resp, err := openai.AnalyzeMessagesContext(ctx, req, data)
+			if err == nil {
+				err = resp.Err()
+			}
+			app.endRequest()
+
+			if errors.Is(err, context.Canceled) {
+				status.SetText("Request cancelled")
+				return
+			}
+			if err != nil {
+				status.SetText(fmt.Sprintf("Request failed: %v", err))
+				return
+			}
+
+			answerView.SetText(resp.Content)
+			status.SetText(resp.Meta.String())
+		}()
+	})
+
+	editor := container.NewBorder(widget.NewLabel("Messages (JSON array):"), nil, nil, nil,
+		container.NewScroll(messagesEntry))
+	output := container.NewBorder(container.NewVBox(sendButton, status), nil, nil, nil,
+		container.NewScroll(answerView))
+
+	window.SetContent(container.NewPadded(container.NewVSplit(editor, output)))
+	window.Show()
+}
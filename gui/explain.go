@@ -0,0 +1,103 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// getExplanationPrompt returns the question asked by the "Why?" button
+//
+// The prompt is sent after the original exchange, so the model sees the
+// image and its own answer and can refer to both.
+func getExplanationPrompt(confidence analysis.Confidence) string {
+	level := "not high"
+	if confidence != analysis.Unknown {
+		level = fmt.Sprintf("%s rather than high", confidence)
+	}
+
+	return fmt.Sprintf(`Your confidence in this identification was %s. Explain briefly:
+
+1. **Limiting Factors**: What in this photo or description prevents a confident identification
+2. **Helpful Observations**: Which additional photos, measurements or tests (e.g. spore print, smell, habitat, cross-section) would most help confirm or rule out the candidates
+
+Keep the answer short and specific to this specimen.`, level)
+}
+
+// explanationRequest turns req into the "Why?" question about the last
+// answer of conv
+//
+// The exchanges so far are sent as prior turns ahead of the question, so
+// the model sees its own answer. A short answer is fine here.
+func explanationRequest(req *openai.Request, conv *conversation, confidence analysis.Confidence) *openai.Request {
+	req.Prompt = getExplanationPrompt(confidence)
+	req.History = conv.history()
+	req.MinContentLength = 0
+	return req
+}
+
+// clearExplanation hides the explanation of a previous result
+func (app *App) clearExplanation() {
+	app.ExplanationLabel.SetText("")
+	app.ExplanationAccordion.CloseAll()
+	app.ExplanationAccordion.Hide()
+	app.WhyButton.Disable()
+}
+
+// updateWhyButton offers an explanation when the result is not confident
+func (app *App) updateWhyButton(answer string) {
+	app.clearExplanation()
+
+	app.confidence = analysis.ParseConfidence(answer)
+	if app.confidence == analysis.High {
+		app.WhyButton.Disable()
+	} else {
+		app.WhyButton.Enable()
+	}
+}
+
+// onWhyClicked asks the model why it is not confident in the result
+func (app *App) onWhyClicked() {
+	if app.conversation.empty() {
+		return
+	}
+
+	ctx, ok := app.beginRequest()
+	if !ok {
+		app.showError("Cannot explain now", errBusy)
+		return
+	}
+
+	app.WhyButton.Disable()
+	app.StatusLabel.SetText("Asking why the identification is uncertain...")
+
+	req := explanationRequest(app.newRequest(app.currentParams(), ""), &app.conversation, app.confidence)
+
+	go func() {
+		resp, err := app.provider.AnalyzeImage(ctx, req)
+		if err == nil {
+			err = resp.Err()
+		}
+		app.endRequest()
+
+		if errors.Is(err, context.Canceled) {
+			app.StatusLabel.SetText("Explanation cancelled")
+			app.WhyButton.Enable()
+			return
+		}
+		if err != nil {
+			app.showError("Explanation failed", err)
+			app.StatusLabel.SetText("Explanation failed")
+			app.WhyButton.Enable()
+			return
+		}
+
+		app.ExplanationLabel.SetText(resp.Content)
+		app.ExplanationAccordion.Show()
+		app.ExplanationAccordion.Open(0)
+		app.StatusLabel.SetText("Explanation received")
+	}()
+}
@@ -0,0 +1,57 @@
+package gui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+func TestExplanationRequest(t *testing.T) {
+	var conv conversation
+	conv.add(exchange{Prompt: "Identify this mushroom", Answer: "**Species**: Russula emetica\n**Confidence**: Low"})
+
+	base := &openai.Request{APIKey: "test-key", APIURL: "http://example.com", MinContentLength: 200}
+	req := explanationRequest(base, &conv, analysis.Low)
+	if req.MinContentLength != 0 {
+		t.Errorf("MinContentLength = %d, want no minimum", req.MinContentLength)
+	}
+
+	body, err := openai.BuildRequestJSON(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatal(err)
+	}
+
+	// The classification, the prior answer and then the question
+	if len(sent.Messages) != 3 {
+		t.Fatalf("got %d messages, want 3: %s", len(sent.Messages), body)
+	}
+	text := func(i int) string { return sent.Messages[i].Content[0].Text }
+	if sent.Messages[1].Role != "assistant" || !strings.Contains(text(1), "Russula emetica") {
+		t.Errorf("message 1 = %+v, want the prior answer", sent.Messages[1])
+	}
+	question := sent.Messages[2]
+	if question.Role != "user" || !strings.Contains(text(2), "Low rather than high") ||
+		!strings.Contains(text(2), "Limiting Factors") {
+		t.Errorf("message 2 = %+v, want the explanation question", question)
+	}
+}
+
+func TestExplanationPromptUnknownConfidence(t *testing.T) {
+	if prompt := getExplanationPrompt(analysis.Unknown); !strings.Contains(prompt, "was not high") {
+		t.Errorf("prompt = %q", prompt)
+	}
+}
@@ -0,0 +1,107 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// onSaveClicked exports the displayed results to a Markdown or text file
+//
+// The file name defaults to the image's base name with an .md extension.
+func (app *App) onSaveClicked() {
+	document := formatExport(app.ImagePath, app.imageInfo, app.resultMeta, time.Now(), app.exportText())
+
+	fileDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			app.showError("Failed to open save dialog", err)
+			return
+		}
+		if writer == nil {
+			return
+		}
+
+		if _, err := writer.Write([]byte(document)); err != nil {
+			writer.Close()
+			app.showError("Failed to save results", err)
+			return
+		}
+		if err := writer.Close(); err != nil {
+			app.showError("Failed to save results", err)
+			return
+		}
+		app.StatusLabel.SetText(fmt.Sprintf("Saved: %s", writer.URI().Name()))
+	}, app.Window)
+
+	fileDialog.SetFileName(exportFileName(app.ImagePath))
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".md", ".txt"}))
+	fileDialog.Show()
+}
+
+// showMeta shows the API parameters of the displayed answer
+func (app *App) showMeta(meta openai.Meta) {
+	app.resultMeta = &meta
+	app.MetaLabel.SetText(meta.String())
+}
+
+// clearMeta removes the API parameters of a cleared answer
+func (app *App) clearMeta() {
+	app.resultMeta = nil
+	app.MetaLabel.SetText("")
+}
+
+// exportText returns the results to export
+//
+// Uses the full conversation, including exchanges collapsed in the view,
+// and falls back to the displayed text for results that are not part of
+// a conversation (e.g. a queue run).
+func (app *App) exportText() string {
+	if app.conversation.empty() {
+		return app.resultText
+	}
+	return convertUnits(app.conversation.render(0), app.Config.UnitSystem)
+}
+
+// exportFileName returns the suggested name of an export file
+func exportFileName(imagePath string) string {
+	if imagePath == "" {
+		return "mushroom.md"
+	}
+	base := filepath.Base(imagePath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + ".md"
+}
+
+// formatExport renders results as a document with a header naming the
+// image, its size if known (info may be nil), the API parameters that
+// produced the results if known (meta may be nil) and the time of export
+func formatExport(imagePath string, info *imageInfo, meta *openai.Meta, at time.Time, results string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", imageName(imagePath))
+	if imagePath != "" {
+		fmt.Fprintf(&b, "- **Image:** %s\n", imagePath)
+	}
+	if info != nil {
+		fmt.Fprintf(&b, "- **Size:** %s\n", info)
+	}
+	if meta != nil {
+		fmt.Fprintf(&b, "- **Model:** %s\n", meta.Model)
+		fmt.Fprintf(&b, "- **Max tokens:** %d\n", meta.MaxTokens)
+		if meta.Temperature != nil {
+			fmt.Fprintf(&b, "- **Temperature:** %g\n", *meta.Temperature)
+		}
+		if meta.TopP != nil {
+			fmt.Fprintf(&b, "- **Top P:** %g\n", *meta.TopP)
+		}
+	}
+	fmt.Fprintf(&b, "- **Date:** %s\n\n", at.Format("2006-01-02 15:04"))
+	b.WriteString(strings.TrimSpace(results))
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+func TestFormatExportMeta(t *testing.T) {
+	temperature := 0.4
+	meta := &openai.Meta{Model: "gpt-4o", MaxTokens: 800, Temperature: &temperature}
+	at := time.Date(2024, 9, 14, 10, 30, 0, 0, time.UTC)
+
+	document := formatExport("/photos/porcini.jpg", nil, meta, at, "**Species**: Boletus edulis\n")
+	for _, line := range []string{"- **Model:** gpt-4o\n", "- **Max tokens:** 800\n", "- **Temperature:** 0.4\n"} {
+		if !strings.Contains(document, line) {
+			t.Errorf("export lacks %q:\n%s", line, document)
+		}
+	}
+	if strings.Contains(document, "Top P") {
+		t.Errorf("export lists an unset top_p:\n%s", document)
+	}
+
+	if document := formatExport("", nil, nil, at, "text"); strings.Contains(document, "Model") {
+		t.Errorf("export without meta lists a model:\n%s", document)
+	}
+}
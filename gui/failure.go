@@ -0,0 +1,26 @@
+package gui
+
+// onRetryClicked sends the last classification again after a failure
+//
+// The request is resent unchanged, with the image that was loaded when
+// it failed, so a timeout or rate limit does not mean selecting the
+// image again.
+func (app *App) onRetryClicked() {
+	if app.lastRun == nil {
+		return
+	}
+	app.classify(app.lastRun)
+}
+
+// offerRetry shows the Retry button after a failure, or hides it
+//
+// It is hidden again once a classification starts or another image is
+// loaded.
+func (app *App) offerRetry(show bool) {
+	if show && app.lastRun != nil {
+		app.RetryButton.Show()
+		app.RetryButton.Enable()
+		return
+	}
+	app.RetryButton.Hide()
+}
@@ -0,0 +1,114 @@
+package gui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/annotate"
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+)
+
+// featureBoxResponse is the JSON shape requested by getFeatureBoxPrompt
+type featureBoxResponse struct {
+	Features []annotate.FeatureBox `json:"features"`
+}
+
+// onShowFeaturesClicked asks the model to locate key features and draws them
+func (app *App) onShowFeaturesClicked() {
+	if app.Base64Image == "" || app.SourceImage == nil {
+		app.showError("No image loaded", nil)
+		return
+	}
+	img := app.SourceImage
+
+	ctx, ok := app.beginRequest()
+	if !ok {
+		app.showError("Cannot locate features now", errBusy)
+		return
+	}
+
+	app.FeaturesButton.Disable()
+	app.StatusLabel.SetText("Locating features...")
+
+	req := app.newRequest(app.currentParams(), getFeatureBoxPrompt())
+	req.MaxTokens = 300
+	req.MinContentLength = 0
+	// Boxes refer to the displayed image only
+	req.Images = nil
+
+	go func() {
+		defer app.FeaturesButton.Enable()
+
+		resp, err := app.provider.AnalyzeImage(ctx, req)
+		if err == nil && !resp.Success {
+			err = fmt.Errorf(resp.ErrorMessage)
+		}
+		app.endRequest()
+
+		if errors.Is(err, context.Canceled) {
+			app.StatusLabel.SetText("Feature detection cancelled")
+			return
+		}
+		if err != nil {
+			app.showError("Feature detection failed", err)
+			app.StatusLabel.SetText("Feature detection failed")
+			return
+		}
+
+		boxes, err := parseFeatureBoxes(resp.Content)
+		if err != nil {
+			app.showError("Feature detection failed", err)
+			app.StatusLabel.SetText("Feature detection failed")
+			return
+		}
+
+		// Show the annotated copy in place of the original; boxes are
+		// normalized, so they can be drawn on the reduced display copy
+		app.ImageView.File = ""
+		app.ImageView.Image = annotate.DrawBoxes(base64.Downscale(img, displayMaxDimension), boxes)
+		app.ImageView.Refresh()
+		app.StatusLabel.SetText(fmt.Sprintf("Marked %d features", len(boxes)))
+	}()
+}
+
+// parseFeatureBoxes extracts feature boxes from the model's JSON answer
+//
+// Tolerates a surrounding Markdown code fence. Coordinates outside the
+// unit square are clamped, and boxes that end up empty are dropped.
+func parseFeatureBoxes(content string) ([]annotate.FeatureBox, error) {
+	content = analysis.StripCodeFence(content)
+
+	var resp featureBoxResponse
+	if err := json.Unmarshal([]byte(content), &resp); err != nil {
+		return nil, fmt.Errorf("invalid feature box JSON: %w", err)
+	}
+
+	boxes := make([]annotate.FeatureBox, 0, len(resp.Features))
+	for _, box := range resp.Features {
+		box = box.Clamp()
+		if box.Width == 0 || box.Height == 0 {
+			continue
+		}
+		box.Label = strings.ToLower(strings.TrimSpace(box.Label))
+		boxes = append(boxes, box)
+	}
+
+	if len(boxes) == 0 {
+		return nil, fmt.Errorf("no features found in response")
+	}
+
+	return boxes, nil
+}
+
+// getFeatureBoxPrompt returns the JSON-mode prompt for locating features
+func getFeatureBoxPrompt() string {
+	return `You are an expert mycologist. Locate the main parts of the mushroom in this image and return ONLY a JSON object, with no other text, in this form:
+
+{"features": [{"label": "cap", "x": 0.0, "y": 0.0, "width": 0.0, "height": 0.0}]}
+
+Use the labels "cap", "gills" and "stem". Coordinates are approximate and normalized to the image: x and y are the top-left corner of the box as a fraction of the image width and height (0 to 1), and width and height are fractions of the image size. Omit features that are not visible.`
+}
@@ -0,0 +1,59 @@
+package gui
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/annotate"
+)
+
+func TestParseFeatureBoxes(t *testing.T) {
+	content := "```json\n" + `{"features": [
+		{"label": " Cap ", "x": 0.2, "y": 0.1, "width": 0.5, "height": 0.3},
+		{"label": "gills", "x": -0.1, "y": 0.35, "width": 0.6, "height": 0.1},
+		{"label": "stem", "x": 0.4, "y": 0.5, "width": 0.2, "height": 0.9},
+		{"label": "volva", "x": 1.2, "y": 0.9, "width": 0.3, "height": 0.1}
+	]}` + "\n```"
+
+	boxes, err := parseFeatureBoxes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []annotate.FeatureBox{
+		{Label: "cap", X: 0.2, Y: 0.1, Width: 0.5, Height: 0.3},
+		// Moved inside the left edge and shortened by the part outside
+		{Label: "gills", X: 0, Y: 0.35, Width: 0.5, Height: 0.1},
+		// Cut off at the bottom edge
+		{Label: "stem", X: 0.4, Y: 0.5, Width: 0.2, Height: 0.5},
+		// The volva lies entirely outside and is dropped
+	}
+	if len(boxes) != len(want) {
+		t.Fatalf("got %d boxes, want %d: %+v", len(boxes), len(want), boxes)
+	}
+	for i, box := range boxes {
+		w := want[i]
+		if box.Label != w.Label || !near(box.X, w.X) || !near(box.Y, w.Y) ||
+			!near(box.Width, w.Width) || !near(box.Height, w.Height) {
+			t.Errorf("box %d = %+v, want %+v", i, box, w)
+		}
+	}
+}
+
+func TestParseFeatureBoxesInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"features": [{"label": "cap", "x": 0.2`,
+		"The cap is at the top left.",
+		`{"features": []}`,
+		`{"features": [{"label": "cap", "x": 1.5, "y": 1.5, "width": 0.2, "height": 0.2}]}`,
+	} {
+		if boxes, err := parseFeatureBoxes(content); err == nil {
+			t.Errorf("parseFeatureBoxes(%q) = %+v, want an error", content, boxes)
+		}
+	}
+}
+
+// near reports whether two coordinates are equal up to rounding
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -2,21 +2,32 @@
 package gui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"image"
 	"log"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
 	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
 	"github.com/mushroom-classifier/mushroom-classifier-go/config"
+	"github.com/mushroom-classifier/mushroom-classifier-go/history"
+	"github.com/mushroom-classifier/mushroom-classifier-go/journal"
 	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+	"github.com/mushroom-classifier/mushroom-classifier-go/provider"
+	"github.com/mushroom-classifier/mushroom-classifier-go/queue"
 )
 
 // App contains all GUI widgets and application state
@@ -30,46 +41,280 @@ type App struct {
 	// Image display widget for showing the selected mushroom photo
 	ImageView *canvas.Image
 
+	// Zoom controls for the image view
+	ZoomInButton  *widget.Button
+	ZoomOutButton *widget.Button
+	ZoomFitButton *widget.Button
+
+	// Label showing the zoom level
+	ZoomLabel *widget.Label
+
 	// Button to trigger file selection dialog
 	UploadButton *widget.Button
 
+	// Button to load an image from the clipboard
+	PasteButton *widget.Button
+
+	// Button to classify an image hosted online
+	URLButton *widget.Button
+
+	// Button to enter field observations such as the spore print color
+	ObservationsButton *widget.Button
+
 	// Button to start classification process
 	ClassifyButton *widget.Button
 
-	// Text widget for displaying classification results
-	ResultView *widget.Entry
+	// Button to send a failed classification again, shown after a failure
+	RetryButton *widget.Button
+
+	// Button to abort a running classification or follow-up
+	CancelButton *widget.Button
+
+	// Button to attach another view of the specimen
+	AddViewButton *widget.Button
+
+	// Button to add the loaded image to the classification queue
+	QueueButton *widget.Button
+
+	// Button to classify every queued image
+	RunQueueButton *widget.Button
+
+	// Button to mark key features on the image
+	FeaturesButton *widget.Button
+
+	// Button to copy the displayed results to the clipboard
+	CopyButton *widget.Button
+
+	// Button to export the displayed results to a file
+	SaveButton *widget.Button
+
+	// Button to append the result to the foraging journal
+	JournalButton *widget.Button
+
+	// Button to open the raw messages editor (expert mode)
+	ExpertButton *widget.Button
+
+	// Button to edit the classification prompt
+	PromptButton *widget.Button
+
+	// Button to show past classifications
+	HistoryButton *widget.Button
+
+	// Button to open another independent window
+	NewWindowButton *widget.Button
+
+	// Model used for classification requests
+	ModelSelect *widget.SelectEntry
+
+	// Entry for the answer length limit, in tokens
+	MaxTokensEntry *widget.Entry
+
+	// Switch between the dark and light theme, shared by all windows
+	DarkModeCheck *widget.Check
+
+	// Text widget for displaying classification results, with risk
+	// keywords highlighted. Rich text cannot be selected, so CopyButton
+	// is the way to get the text out.
+	ResultView *widget.RichText
 
 	// Label showing current status/progress
 	StatusLabel *widget.Label
 
+	// Animated bar shown while a request is running
+	Spinner *widget.ProgressBarInfinite
+
+	// Bar estimating how much of a streamed answer has arrived
+	StreamProgress *widget.ProgressBar
+
+	// Label showing the model and parameters behind the displayed result
+	MetaLabel *widget.Label
+
+	// Entry for follow-up questions about the result
+	FollowUpEntry *widget.Entry
+
+	// Button to send a follow-up question
+	AskButton *widget.Button
+
 	// Path to the currently loaded image file
 	ImagePath string
 
 	// Base64 encoded image data
 	Base64Image string
 
+	// MIME type of the encoded image
+	MimeType string
+
+	// URL of an image hosted online, sent instead of image data
+	ImageURL string
+
+	// Decoded image as loaded, before preprocessing
+	SourceImage image.Image
+
+	// Zoom of the image view relative to its fitted size
+	zoom float64
+
+	// Additional views of the same specimen sent with the main image
+	ExtraImages []openai.ImageInput
+
+	// Whether the loaded image fell below the sharpness threshold
+	Blurry bool
+
+	// Whether the loaded image exceeded the panorama aspect ratio
+	Panorama bool
+
+	// Classify from the user's notes only, without sending the image
+	TextOnly bool
+
+	// User-supplied description used in text-only mode
+	Notes string
+
+	// Field observations added to the classification prompt; kept when
+	// another image is loaded, e.g. a second photo of the same find
+	Observations prompts.Observations
+
+	// Button asking why the result is not confident
+	WhyButton *widget.Button
+
+	// Expander showing the answer to "Why?"
+	ExplanationAccordion *widget.Accordion
+
+	// Explanation text inside ExplanationAccordion
+	ExplanationLabel *widget.Label
+
 	// Application configuration (API keys, etc.)
 	Config *config.Config
+
+	// Plain text shown in ResultView
+	resultText string
+
+	// Questions and answers about the current image
+	conversation conversation
+
+	// Confidence level stated in the displayed result
+	confidence analysis.Confidence
+
+	// Parameters that produced the displayed result (nil if none)
+	resultParams *requestParams
+
+	// Effective API parameters of the displayed answer, shown in MetaLabel
+	// and exported (nil if none)
+	resultMeta *openai.Meta
+
+	// Most recent classification sent, resent by Retry (nil if none)
+	lastRun *classifyRun
+
+	// Size of the loaded image, shown above results (nil in text-only
+	// mode)
+	imageInfo *imageInfo
+
+	// Time the displayed classification was received
+	resultTime time.Time
+
+	// Debounces automatic re-runs after parameter changes
+	rerunDebouncer *debouncer
+
+	// Cancels the running API call
+	canceller requestCanceller
+
+	// Classification queue shared with the other windows
+	queue *queue.Queue
+
+	// Record of past classifications (nil if unavailable)
+	history *history.Store
+
+	// Vision API requests are sent to
+	provider provider.Provider
+
+	// Foraging journal (nil if not configured)
+	journal *journal.Journal
+
+	// User-edited classification prompt (nil if unavailable)
+	prompt *prompts.Custom
+}
+
+// requestParams holds the user-adjustable classification parameters
+//
+// Compared against the parameters of the displayed result to decide
+// whether an automatic re-run is needed.
+type requestParams struct {
+	Model     string
+	MaxTokens int
+}
+
+// modelOptions lists the vision models offered in the model selector,
+// by provider
+var modelOptions = map[string][]string{
+	"openai":    {"gpt-4o", "gpt-4o-mini", "gpt-4-turbo"},
+	"anthropic": {"claude-3-5-sonnet-latest", "claude-3-5-haiku-latest", "claude-3-opus-latest"},
+	"mock":      {"mock"},
+}
+
+// displayMaxDimension is the longest side, in pixels, of the image shown
+// in the window
+const displayMaxDimension = 1600
+
+// rerunDelay is how long parameters must settle before an automatic re-run
+const rerunDelay = 750 * time.Millisecond
+
+// imageExtensions lists the file extensions offered in the open dialog
+//
+// Formats without a decoder in this build are still listed so they can be
+// classified in text-only mode.
+var imageExtensions = []string{
+	".jpg", ".jpeg", ".png", ".gif", ".webp", ".heic", ".heif",
+	".JPG", ".JPEG", ".PNG", ".GIF", ".WEBP", ".HEIC", ".HEIF",
 }
 
+// errUnsupportedFormat is returned when no decoder exists for an image
+var errUnsupportedFormat = base64.ErrUnsupportedFormat
+
 // NewApp creates a new App instance with initialized Fyne widgets
+//
+// NewApp may be called several times; each call opens an independent
+// window with its own state, sharing the Fyne application and config.
+// The application quits when the last window is closed.
 func NewApp(cfg *config.Config) (*App, error) {
-	// Create Fyne application
-	fyneApp := app.New()
+	// Get the shared Fyne application
+	fyneApp := sharedFyneApp()
 
 	// Create main window
 	window := fyneApp.NewWindow("Mushroom Classifier")
 	window.Resize(fyne.NewSize(800, 600))
 
+	p, err := provider.FromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	app := &App{
-		FyneApp: fyneApp,
-		Window:  window,
-		Config:  cfg,
+		FyneApp:        fyneApp,
+		Window:         window,
+		Config:         cfg,
+		rerunDebouncer: newDebouncer(rerunDelay, func(fn func()) { runOnUI(fyneApp, fn) }),
+		queue:          sharedQueue(cfg.QueueFile),
+		history:        sharedHistory(cfg.HistoryFile),
+		journal:        sharedJournal(cfg.JournalFile),
+		prompt:         sharedPrompt(cfg.PromptFile),
+		provider:       p,
 	}
 
 	// Create UI components
 	app.createUI()
 
+	// Track the window so the application only exits with the last one
+	first := windows.add(app) == 1
+	window.SetOnClosed(app.onWindowClosed)
+
+	// Catch a bad API key or URL before the first classification
+	if first && app.Config.CheckConnection {
+		app.checkConnection()
+	}
+
+	// Offer to finish jobs left over from a previous run
+	if first {
+		app.offerResume()
+	}
+
 	return app, nil
 }
 
@@ -84,54 +329,174 @@ func (app *App) createUI() {
 	app.ImageView = &canvas.Image{
 		FillMode: canvas.ImageFillContain,
 	}
-	app.ImageView.SetMinSize(fyne.NewSize(400, 300))
+	app.ImageView.SetMinSize(imageViewSize)
 	
-	// Wrap image in a bordered container
+	// Create zoom controls
+	app.ZoomOutButton = widget.NewButton("-", app.onZoomOutClicked)
+	app.ZoomInButton = widget.NewButton("+", app.onZoomInClicked)
+	app.ZoomFitButton = widget.NewButton("Fit", app.onZoomFitClicked)
+	app.ZoomLabel = widget.NewLabel("")
+	app.setZoom(1)
+
+	zoomContainer := container.NewHBox(layout.NewSpacer(),
+		app.ZoomOutButton, app.ZoomLabel, app.ZoomInButton, app.ZoomFitButton)
+
+	// Wrap image in a scrolled container so a zoomed image can be panned
+	imageScroll := container.NewScroll(container.NewCenter(app.ImageView))
+	imageScroll.SetMinSize(imageViewSize)
 	imageContainer := container.NewBorder(
-		nil, nil, nil, nil,
-		container.NewCenter(app.ImageView),
+		nil, zoomContainer, nil, nil,
+		imageScroll,
 	)
 
 	// Create buttons
 	app.UploadButton = widget.NewButton("Select Image", app.onUploadClicked)
+	app.PasteButton = widget.NewButton("Paste", app.onPasteClicked)
+	app.URLButton = widget.NewButton("From URL", app.onURLClicked)
 	app.ClassifyButton = widget.NewButton("Classify Mushroom", app.onClassifyClicked)
 	app.ClassifyButton.Disable()
+	app.RetryButton = widget.NewButton("Retry", app.onRetryClicked)
+	app.RetryButton.Hide()
+	app.CancelButton = widget.NewButton("Cancel", app.onCancelClicked)
+	app.CancelButton.Disable()
+	app.AddViewButton = widget.NewButton("Add View", app.onAddViewClicked)
+	app.AddViewButton.Disable()
+	app.ObservationsButton = widget.NewButton("", app.onObservationsClicked)
+	app.updateObservationsButton()
+	app.FeaturesButton = widget.NewButton("Show Features", app.onShowFeaturesClicked)
+	app.FeaturesButton.Disable()
+	if !app.Config.FeatureBoxes {
+		app.FeaturesButton.Hide()
+	}
+	app.QueueButton = widget.NewButton("Add to Queue", app.onQueueClicked)
+	app.RunQueueButton = widget.NewButton("Run Queue", app.onRunQueueClicked)
+	app.updateQueueButtons()
+	app.CopyButton = widget.NewButton("Copy Results", app.onCopyClicked)
+	app.CopyButton.Disable()
+	app.SaveButton = widget.NewButton("Save Results", app.onSaveClicked)
+	app.SaveButton.Disable()
+	app.JournalButton = widget.NewButton("Add to Journal", app.onJournalClicked)
+	app.JournalButton.Disable()
+	if app.journal == nil {
+		app.JournalButton.Hide()
+	}
+	app.ExpertButton = widget.NewButton("Expert", app.onExpertClicked)
+	// Custom messages use the OpenAI format
+	if !app.Config.ExpertMode || app.Config.Provider != "openai" {
+		app.ExpertButton.Hide()
+	}
+	app.PromptButton = widget.NewButton("Edit Prompt", app.onEditPromptClicked)
+	if app.prompt == nil {
+		app.PromptButton.Hide()
+	}
+	app.HistoryButton = widget.NewButton("History", app.onHistoryClicked)
+	app.NewWindowButton = widget.NewButton("New Window", app.onNewWindowClicked)
 
 	buttonContainer := container.New(layout.NewHBoxLayout(),
 		app.UploadButton,
+		app.PasteButton,
+		app.URLButton,
+		app.AddViewButton,
+		app.ObservationsButton,
 		app.ClassifyButton,
+		app.RetryButton,
+		app.CancelButton,
+		app.FeaturesButton,
+		app.QueueButton,
+		app.RunQueueButton,
+		app.CopyButton,
+		app.SaveButton,
+		app.JournalButton,
+		layout.NewSpacer(),
+		app.ExpertButton,
+		app.PromptButton,
+		app.HistoryButton,
+		app.NewWindowButton,
 	)
 
+	// Create parameter controls
+	app.ModelSelect = widget.NewSelectEntry(modelOptions[app.Config.Provider])
+	app.ModelSelect.SetText(app.Config.Model)
+	app.ModelSelect.OnChanged = func(string) { app.onParametersChanged() }
+
+	app.MaxTokensEntry = widget.NewEntry()
+	app.MaxTokensEntry.SetText(strconv.Itoa(app.Config.MaxTokens))
+	app.MaxTokensEntry.Validator = validateMaxTokens
+	app.MaxTokensEntry.OnChanged = func(string) { app.onParametersChanged() }
+
+	// Set before the handler so the initial state is not saved
+	app.DarkModeCheck = widget.NewCheck("Dark mode", nil)
+	app.DarkModeCheck.SetChecked(isDark(app.FyneApp, sharedTheme(app.FyneApp, app.Config.Theme)))
+	app.DarkModeCheck.OnChanged = app.onDarkModeChanged
+
+	settingsContainer := container.NewBorder(nil, nil, widget.NewLabel("Model:"),
+		container.NewHBox(widget.NewLabel("Max tokens:"), app.MaxTokensEntry, app.DarkModeCheck), app.ModelSelect)
+
 	// Create status label
 	app.StatusLabel = widget.NewLabel("Select an image to begin")
+	app.Spinner = widget.NewProgressBarInfinite()
+	app.Spinner.Stop()
+	app.Spinner.Hide()
+	app.StreamProgress = widget.NewProgressBar()
+	app.StreamProgress.Hide()
 
 	// Create results section
 	resultsLabel := widget.NewLabel("Results:")
 	resultsLabel.TextStyle = fyne.TextStyle{Bold: true}
 	
-	app.ResultView = widget.NewMultiLineEntry()
+	app.ResultView = widget.NewRichText()
 	app.ResultView.Wrapping = fyne.TextWrapWord
-	app.ResultView.Disable()
 	
 	resultScroll := container.NewScroll(app.ResultView)
 	resultScroll.SetMinSize(fyne.NewSize(0, 200))
 
+	app.MetaLabel = widget.NewLabel("")
+	app.MetaLabel.TextStyle = fyne.TextStyle{Italic: true}
+
+	// Create follow-up controls
+	app.FollowUpEntry = widget.NewEntry()
+	app.FollowUpEntry.SetPlaceHolder("Ask a follow-up question...")
+	app.FollowUpEntry.OnSubmitted = func(string) { app.onAskClicked() }
+	app.AskButton = widget.NewButton("Ask", app.onAskClicked)
+	app.AskButton.Disable()
+
+	app.WhyButton = widget.NewButton("Why?", app.onWhyClicked)
+	app.WhyButton.Disable()
+
+	followUpContainer := container.NewBorder(nil, nil, nil,
+		container.NewHBox(app.AskButton, app.WhyButton), app.FollowUpEntry)
+
+	// Create confidence explanation expander, shown once requested
+	app.ExplanationLabel = widget.NewLabel("")
+	app.ExplanationLabel.Wrapping = fyne.TextWrapWord
+	app.ExplanationAccordion = widget.NewAccordion(
+		widget.NewAccordionItem("Why is the identification uncertain?", app.ExplanationLabel))
+	app.ExplanationAccordion.Hide()
+
 	// Create main layout
 	content := container.NewVBox(
 		headerLabel,
 		widget.NewSeparator(),
 		imageContainer,
 		buttonContainer,
+		settingsContainer,
 		app.StatusLabel,
+		app.Spinner,
+		app.StreamProgress,
 		widget.NewSeparator(),
 		resultsLabel,
 		resultScroll,
+		app.MetaLabel,
+		app.ExplanationAccordion,
+		followUpContainer,
 	)
 
 	// Wrap in padded container
 	paddedContent := container.NewPadded(content)
 	
 	app.Window.SetContent(paddedContent)
+	app.Window.SetOnDropped(app.onDropped)
+	app.addShortcuts()
 	app.Window.CenterOnScreen()
 }
 
@@ -140,6 +505,26 @@ func (app *App) Run() {
 	app.Window.ShowAndRun()
 }
 
+// onNewWindowClicked opens another independent window
+func (app *App) onNewWindowClicked() {
+	other, err := NewApp(app.Config)
+	if err != nil {
+		app.showError("Failed to open window", err)
+		return
+	}
+	other.Window.Show()
+}
+
+// onWindowClosed unregisters the window and quits after the last one
+func (app *App) onWindowClosed() {
+	// Abandon any request still running for this window
+	app.canceller.abort()
+
+	if windows.remove(app) == 0 {
+		app.FyneApp.Quit()
+	}
+}
+
 // onUploadClicked handles the upload button click event
 func (app *App) onUploadClicked() {
 	// Create file open dialog
@@ -153,65 +538,260 @@ func (app *App) onUploadClicked() {
 		}
 		defer reader.Close()
 
-		// Get file path
-		filename := reader.URI().Path()
-		
-		// Load and display image
-		if err := app.loadImage(filename); err != nil {
-			app.showError("Failed to load image", err)
+		app.openImage(reader.URI().Path())
+	}, app.Window)
+
+	// Set file filter for images
+	fileDialog.SetFilter(storage.NewExtensionFileFilter(imageExtensions))
+	fileDialog.Show()
+}
+
+// openImage loads a user-selected image file and enables classification
+//
+// Errors are reported to the user. Unsupported formats offer the
+// text-only fallback when it is enabled.
+func (app *App) openImage(filename string) {
+	// Load and display image
+	if err := app.loadImage(filename); err != nil {
+		if errors.Is(err, errUnsupportedFormat) && app.Config.TextOnlyFallback {
+			app.offerTextOnly(filename)
+			return
+		}
+		if errors.Is(err, base64.ErrImageTooLarge) {
+			app.showError("Image too large", err)
 			return
 		}
+		app.showError("Failed to load image", err)
+		return
+	}
 
+	app.ImagePath = filename
+	app.setLoadedStatus(fmt.Sprintf("Loaded: %s", filepath.Base(filename)))
+	app.updateQueueButtons()
+	app.ClassifyButton.Enable()
+	app.FeaturesButton.Enable()
+	app.AddViewButton.Enable()
+}
+
+// offerTextOnly asks whether to classify an undecodable image from notes
+//
+// Shown when the selected file is not in a format this build can decode.
+// If the user agrees, the image is not sent and the query is built from
+// the filename and the notes they enter instead.
+func (app *App) offerTextOnly(filename string) {
+	message := widget.NewLabel(fmt.Sprintf(
+		"%s is not in a supported image format.\n"+
+			"You can still describe the mushroom and classify from your notes only.",
+		filepath.Base(filename)))
+	message.Wrapping = fyne.TextWrapWord
+
+	notesEntry := widget.NewMultiLineEntry()
+	notesEntry.SetPlaceHolder("Cap colour and shape, gills, stem, habitat...")
+	notesEntry.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(message, notesEntry)
+
+	dialog.ShowCustomConfirm("Unsupported Image Format", "Continue", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if app.canceller.busy() {
+			app.showError("Failed to load image", errBusy)
+			return
+		}
+
+		app.reset()
 		app.ImagePath = filename
-		app.StatusLabel.SetText(fmt.Sprintf("Loaded: %s", filepath.Base(filename)))
+		app.TextOnly = true
+		app.Notes = notesEntry.Text
+
+		app.ImageView.File = ""
+		app.ImageView.Image = nil
+		app.ImageView.Refresh()
+		app.setZoom(1)
+
+		app.StatusLabel.SetText(fmt.Sprintf("Text-only mode: %s", filepath.Base(filename)))
+		app.updateQueueButtons()
 		app.ClassifyButton.Enable()
+		app.FeaturesButton.Disable()
+		app.AddViewButton.Disable()
 	}, app.Window)
+}
 
-	// Set file filter for images
-	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".jpg", ".jpeg", ".png", ".JPG", ".JPEG", ".PNG"}))
-	fileDialog.Show()
+// onPasteClicked loads an image pasted as a data URL or base64 text
+func (app *App) onPasteClicked() {
+	data, _, err := base64.DecodeDataURL(app.Window.Clipboard().Content())
+	if err != nil {
+		app.showError("Clipboard does not contain an image", err)
+		return
+	}
+	if limit := app.Config.MaxImageBytes; limit > 0 && int64(len(data)) > limit {
+		app.showError("Image too large", &base64.SizeError{File: "Pasted image", Size: int64(len(data)), Limit: limit})
+		return
+	}
+
+	if err := app.loadImageData(data); err != nil {
+		app.showError("Failed to load image", err)
+		return
+	}
+
+	app.ImagePath = ""
+	app.setLoadedStatus("Loaded image from clipboard")
+	app.updateQueueButtons()
+	app.ClassifyButton.Enable()
+	app.FeaturesButton.Enable()
+	app.AddViewButton.Enable()
 }
 
 // onClassifyClicked handles the classify button click event
 func (app *App) onClassifyClicked() {
-	if app.Base64Image == "" {
+	if app.Base64Image == "" && app.ImageURL == "" && !app.TextOnly {
 		app.showError("No image loaded", nil)
 		return
 	}
 
+	// Build the prompt for the current mode
+	prompt := app.editedPrompt()
+	calibrated := false
+	if app.TextOnly {
+		prompt = getTextOnlyPrompt(filepath.Base(app.ImagePath), app.Notes)
+	} else if app.Config.CalibratedConfidence {
+		prompt = getCalibratedPrompt()
+		calibrated = true
+	}
+	prompt = prompts.WithObservations(prompt, app.Observations)
+	if !calibrated {
+		prompt = prompts.WithLanguage(prompt, app.Config.Language)
+	}
+
+	params := app.currentParams()
+	app.classify(&classifyRun{
+		prompt:     prompt,
+		calibrated: calibrated,
+		params:     params,
+		req:        app.newRequest(params, prompt),
+	})
+}
+
+// classifyRun is a classification request together with how to show it
+//
+// Kept after it is sent so a failed classification can be retried as is.
+type classifyRun struct {
+	prompt     string
+	calibrated bool
+	params     requestParams
+	req        *openai.Request
+}
+
+// classify sends a classification request and shows the answer
+func (app *App) classify(run *classifyRun) {
+	ctx, ok := app.beginRequest()
+	if !ok {
+		// Already analyzing; the buttons may not have been disabled yet
+		return
+	}
+	prompt, calibrated, params, req := run.prompt, run.calibrated, run.params, run.req
+	app.lastRun = run
+	app.offerRetry(false)
+
 	// Disable buttons during processing
 	app.UploadButton.Disable()
 	app.ClassifyButton.Disable()
+	app.clearRisk()
 	app.StatusLabel.SetText("Analyzing image...")
-	app.ResultView.SetText("Processing...")
+	app.setResultText("Processing...")
+	app.clearMeta()
+	app.AskButton.Disable()
+	app.CopyButton.Disable()
+	app.SaveButton.Disable()
+	app.JournalButton.Disable()
+	app.clearExplanation()
 
-	// Create OpenAI request
-	req := &openai.Request{
-		APIKey:      app.Config.OpenAIAPIKey,
-		APIURL:      app.Config.OpenAIAPIURL,
-		Model:       "gpt-4o",
-		Prompt:      getMushroomPrompt(),
-		Base64Image: app.Base64Image,
-		MaxTokens:   1000,
-	}
+	// Give up on slow answers, and guard against a classification that
+	// never returns even then
+	ctx, stopTimeout := withOptionalTimeout(ctx, app.Config.ClassifyTimeout)
+	wd := startWatchdog(app.Config.WatchdogTimeout, app.onClassifyStuck)
 
 	// Process in background
 	go func() {
-		// Analyze image
-		resp, err := openai.AnalyzeImage(req)
+		defer stopTimeout()
+
+		// Analyze image, showing the answer as it streams in
+		var resp *openai.Response
+		var err error
+		var progress *streamProgress
+		if app.Config.EnsembleSize > 1 && !app.TextOnly && !calibrated {
+			resp, err = classifyEnsemble(ctx, app.provider, req, app.Config.EnsembleSize)
+		} else if streamer, ok := app.provider.(provider.Streamer); ok && app.Config.Stream {
+			var streamed strings.Builder
+			progress = app.startStreamProgress(req.MaxTokens)
+			resp, err = streamer.AnalyzeImageStream(ctx, req, func(delta string) {
+				streamed.WriteString(delta)
+				app.setResultText(streamed.String())
+				progress.chunk()
+			})
+		} else {
+			resp, err = app.provider.AnalyzeImage(ctx, req)
+		}
+
+		// Take a closer look at low-confidence identifications
+		var retry *openai.Response
+		if err == nil && resp.Success && !app.TextOnly && !calibrated && app.Config.EnsembleSize <= 1 {
+			retry = app.retryDetailed(ctx, req, resp)
+		}
+
+		// Drop the result if the watchdog already reset the UI
+		if !wd.finish() {
+			log.Printf("Discarding result of abandoned classification")
+			return
+		}
+		app.endRequest()
+		progress.finish(err == nil && resp.Success)
 
 		// Update UI (Fyne is thread-safe)
-		if err != nil {
+		if errors.Is(err, context.Canceled) {
+			app.StatusLabel.SetText("Analysis cancelled")
+			app.setResultText("")
+		} else if timedOut(ctx, resp, err) {
+			app.StatusLabel.SetText("Analysis timed out.")
+			app.setResultText("")
+			app.offerRetry(true)
+		} else if err != nil {
 			app.showError("Analysis failed", err)
 			app.StatusLabel.SetText("Analysis failed")
-			app.ResultView.SetText("")
+			app.setResultText("")
+			app.offerRetry(true)
 		} else if !resp.Success {
 			app.showError("Analysis failed", fmt.Errorf(resp.ErrorMessage))
 			app.StatusLabel.SetText("Analysis failed")
-			app.ResultView.SetText("")
+			app.setResultText("")
+			app.offerRetry(true)
+		} else if calibrated {
+			app.showCalibratedResult(prompt, resp.Content)
+			app.showMeta(resp.Meta)
+			app.resultParams = &params
+			app.recordHistory(app.ImagePath, resp.Meta, resp.Content)
+		} else if retry != nil {
+			// Show both attempts; the closer look is the final answer
+			app.startConversation(prompt, resp.Content)
+			app.conversation.add(exchange{Prompt: app.detailedPrompt(), Question: retryQuestion, Answer: retry.Content})
+			app.refreshConversation()
+			app.updateWhyButton(retry.Content)
+			app.showRisk(retry.Content)
+			app.StatusLabel.SetText(withTruncationWarning("Analysis complete (looked again after low confidence)", retry))
+			app.showMeta(retry.Meta)
+			app.resultParams = &params
+			app.recordHistory(app.ImagePath, retry.Meta, retry.Content)
 		} else {
-			app.ResultView.SetText(resp.Content)
-			app.StatusLabel.SetText("Analysis complete")
+			status := "Analysis complete"
+			if resp.Cached {
+				status += " (cached result, no API call)"
+			}
+			app.startConversation(prompt, resp.Content)
+			app.StatusLabel.SetText(withTruncationWarning(status, resp))
+			app.showMeta(resp.Meta)
+			app.resultParams = &params
+			app.recordHistory(app.ImagePath, resp.Meta, resp.Content)
 		}
 
 		// Re-enable buttons
@@ -220,22 +800,219 @@ func (app *App) onClassifyClicked() {
 	}()
 }
 
+// showCalibratedResult displays a calibrated answer as a ranked list
+//
+// Falls back to the raw model output if it cannot be parsed or the
+// candidate probabilities fail validation.
+func (app *App) showCalibratedResult(prompt, content string) {
+	result, err := parseStructuredResult(content)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		app.startConversation(prompt, content)
+		app.StatusLabel.SetText("Analysis complete (could not read candidate probabilities)")
+		return
+	}
+
+	app.startConversation(prompt, formatStructuredResult(result))
+	app.StatusLabel.SetText("Analysis complete")
+}
+
+// newRequest builds an OpenAI request for the loaded image
+//
+// Applies the configured API settings and the given parameters. Callers
+// adjust the returned request for special queries (e.g. MaxTokens).
+func (app *App) newRequest(params requestParams, prompt string) *openai.Request {
+	return &openai.Request{
+		APIKey:             app.Config.APIKey(),
+		APIURL:             app.Config.APIURL(),
+		Organization:       app.Config.OpenAIOrganization,
+		Project:            app.Config.OpenAIProject,
+		Model:              params.Model,
+		Prompt:             prompt,
+		SystemPrompt:       app.Config.SystemPrompt,
+		Base64Image:        app.Base64Image,
+		MimeType:           app.MimeType,
+		ImageURL:           app.ImageURL,
+		Images:             app.ExtraImages,
+		MaxImages:          app.Config.MaxImagesPerRequest,
+		RawBase64Image:     app.Config.RawBase64Image,
+		ImageDetail:        app.Config.ImageDetail,
+		ShrinkMinQuality:   app.Config.ShrinkMinQuality,
+		MaxTokens:          params.MaxTokens,
+		Temperature:        app.Config.Temperature,
+		TopP:               app.Config.TopP,
+		MinContentLength:   app.Config.MinResultLength,
+		Timeout:            app.Config.HTTPTimeout,
+		Proxy:              app.Config.Proxy,
+		CAFile:             app.Config.CAFile,
+		RateLimiter:        sharedRateLimiter(app.Config.RequestsPerMinute),
+		Cache:              sharedResultCache(app.Config),
+		ContentPath:        app.Config.ContentPath(app.Config.APIURL()),
+		Debug:              app.Config.Debug,
+		RetryMalformedJSON: app.Config.RetryMalformedJSON,
+	}
+}
+
+// currentParams returns the parameters selected in the settings controls
+func (app *App) currentParams() requestParams {
+	model := strings.TrimSpace(app.ModelSelect.Text)
+	if model == "" {
+		model = app.Config.Model
+	}
+
+	return requestParams{
+		Model:     model,
+		MaxTokens: parseMaxTokens(app.MaxTokensEntry.Text, app.Config.MaxTokens),
+	}
+}
+
+// onParametersChanged re-runs classification after settings change
+//
+// Only active when AUTO_RERUN is enabled and a result is displayed. Rapid
+// changes are debounced, and nothing happens if the settled parameters
+// match those of the displayed result or a classification is running.
+func (app *App) onParametersChanged() {
+	if !app.Config.AutoRerun || app.resultParams == nil {
+		return
+	}
+
+	app.rerunDebouncer.trigger(func() {
+		if app.resultParams == nil || *app.resultParams == app.currentParams() {
+			return
+		}
+		if app.ClassifyButton.Disabled() {
+			return
+		}
+		app.onClassifyClicked()
+	})
+}
+
 // loadImage loads and displays an image file
 func (app *App) loadImage(filename string) error {
-	// Read image to base64
-	base64Image, err := base64.ReadImageToBase64(filename)
+	// Read image data
+	data, err := base64.ReadImageWithLimit(filename, app.Config.MaxImageBytes)
+	if err != nil {
+		return err
+	}
+
+	return app.loadImageData(data)
+}
+
+// reset forgets the loaded image and everything derived from it
+//
+// Clears the image data, results, status and retry state so nothing
+// from the previous image is shown or sent with the next one. Callers
+// then fill in the new image.
+func (app *App) reset() {
+	app.ImagePath = ""
+	app.Base64Image = ""
+	app.MimeType = ""
+	app.ImageURL = ""
+	app.SourceImage = nil
+	app.imageInfo = nil
+	app.ExtraImages = nil
+	app.Blurry = false
+	app.Panorama = false
+	app.TextOnly = false
+	app.Notes = ""
+	app.resultParams = nil
+	app.lastRun = nil
+	app.conversation.reset()
+
+	app.setResultText("")
+	app.clearMeta()
+	app.StreamProgress.Hide()
+	app.clearRisk()
+	app.StatusLabel.SetText("Select an image to begin")
+	app.AskButton.Disable()
+	app.CopyButton.Disable()
+	app.SaveButton.Disable()
+	app.JournalButton.Disable()
+	app.clearExplanation()
+	app.offerRetry(false)
+}
+
+// loadImageData loads and displays an image from memory
+func (app *App) loadImageData(data []byte) error {
+	if app.canceller.busy() {
+		return errBusy
+	}
+
+	// Detect undecodable formats before any processing
+	if err := base64.ValidateImage(data); err != nil {
+		return err
+	}
+
+	// Refuse huge bitmaps before decoding allocates memory for them
+	if err := base64.CheckDimensions(data, app.Config.MaxImagePixels); err != nil {
+		return err
+	}
+
+	// Decode for display, upright even if the camera was turned
+	img, _, err := base64.DecodeOriented(data)
 	if err != nil {
 		return err
 	}
-	app.Base64Image = base64Image
 
-	// Load image for display
-	app.ImageView.File = filename
-	app.ImageView.Refresh()
+	// Prepare the image for upload; tiles of a panorama after the first
+	// are sent as extra images
+	inputs, err := app.prepareImages(data)
+	if err != nil {
+		return err
+	}
+
+	// Forget the previous image only once the new one is usable
+	app.reset()
+	app.Base64Image = inputs[0].Data
+	app.MimeType = inputs[0].MimeType
+	app.SourceImage = img
+	app.imageInfo = &imageInfo{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+		Size:   int64(len(data)),
+	}
+	if len(inputs) > 1 {
+		app.ExtraImages = inputs[1:]
+	}
+	app.Panorama = app.Config.PanoramaMaxRatio > 0 &&
+		base64.AspectRatio(img) > app.Config.PanoramaMaxRatio
+	app.Blurry = app.Config.SharpnessThreshold > 0 &&
+		base64.EstimateSharpness(img) < app.Config.SharpnessThreshold
+
+	// Show a reduced copy; the full image only matters for upload
+	app.ImageView.File = ""
+	app.ImageView.Image = base64.Downscale(img, displayMaxDimension)
+	app.setZoom(1)
 
 	return nil
 }
 
+// setLoadedStatus reports a loaded image, warning if it looks blurry
+//
+// The warning is advisory; classification stays available.
+func (app *App) setLoadedStatus(text string) {
+	if app.Blurry {
+		text += " — Image may be too blurry for reliable identification"
+	}
+	if app.Panorama {
+		if len(app.ExtraImages) > 0 {
+			text += fmt.Sprintf(" — Very wide image split into %d overlapping parts", len(app.ExtraImages)+1)
+		} else {
+			text += " — Very wide image padded with bars"
+		}
+	}
+	app.StatusLabel.SetText(text)
+}
+
+// onCopyClicked copies the displayed results to the clipboard
+func (app *App) onCopyClicked() {
+	if app.resultText == "" {
+		return
+	}
+	app.Window.Clipboard().SetContent(app.resultText)
+	app.StatusLabel.SetText("Results copied to clipboard")
+}
+
 // showError displays an error message dialog
 func (app *App) showError(message string, err error) {
 	errorMsg := message
@@ -249,14 +1026,28 @@ func (app *App) showError(message string, err error) {
 
 // getMushroomPrompt returns the prompt for mushroom analysis
 func getMushroomPrompt() string {
-	return `You are an expert mycologist. Analyze this image of a mushroom and provide:
+	return prompts.Mushroom()
+}
+
+// getTextOnlyPrompt returns the prompt used when the image cannot be sent
+//
+// The model only sees the filename and the user's notes, so it is asked
+// to be explicit about how limited an identification from text alone is.
+func getTextOnlyPrompt(filename, notes string) string {
+	if strings.TrimSpace(notes) == "" {
+		notes = "(no notes provided)"
+	}
+
+	return fmt.Sprintf(`You are an expert mycologist. No photo is available; the image file "%s" could not be processed. Based only on the filename and the observer's notes below, provide:
 
-1. **Species Identification**: Common name and scientific name
-2. **Confidence Level**: How certain you are of the identification (High/Medium/Low)
-3. **Key Identifying Features**: What visual characteristics led to this identification
-4. **Edibility**: Whether this mushroom is edible, poisonous, or unknown
+1. **Possible Species**: Candidate common and scientific names
+2. **Confidence Level**: How certain you are (High/Medium/Low) given that no image was seen
+3. **Missing Information**: What observations would help narrow the identification
+4. **Edibility**: Whether the candidates are edible, poisonous, or unknown
 5. **Safety Warning**: Any important safety information
-6. **Similar Species**: Other mushrooms it might be confused with
 
-IMPORTANT: Always err on the side of caution. If uncertain, clearly state so. Never encourage consumption of wild mushrooms without expert verification.`
+Observer's notes:
+%s
+
+IMPORTANT: An identification without a photo is highly unreliable. Clearly state this and never encourage consumption of wild mushrooms without expert verification.`, filename, notes)
 }
\ No newline at end of file
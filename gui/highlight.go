@@ -0,0 +1,100 @@
+package gui
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// riskKeywords are phrases shown in bold red in the result view
+//
+// Matched case-insensitively as whole words. They take precedence over
+// safeKeywords where both match.
+var riskKeywords = []string{
+	"poisonous",
+	"toxic",
+	"deadly",
+	"lethal",
+	"fatal",
+	"do not eat",
+	"do not consume",
+	"not edible",
+	"inedible",
+}
+
+// safeKeywords are phrases shown in muted green in the result view
+var safeKeywords = []string{
+	"edible",
+}
+
+// plainStyle is the style of result text without markup or keywords
+var plainStyle = widget.RichTextStyle{Inline: true}
+
+// riskStyle returns base with the bold red of risk keywords
+func riskStyle(base widget.RichTextStyle) widget.RichTextStyle {
+	base.ColorName = theme.ColorNameError
+	base.TextStyle.Bold = true
+	return base
+}
+
+// safeStyle returns base with the muted green of safe keywords
+func safeStyle(base widget.RichTextStyle) widget.RichTextStyle {
+	base.ColorName = theme.ColorNameSuccess
+	return base
+}
+
+// keywordPattern returns a pattern matching any of the keywords as whole
+// words, longest first so that "not edible" wins over "edible"
+func keywordPattern(keywords []string) string {
+	sorted := append([]string(nil), keywords...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	quoted := make([]string, len(sorted))
+	for i, k := range sorted {
+		quoted[i] = regexp.QuoteMeta(k)
+	}
+	return `\b(?:` + strings.Join(quoted, "|") + `)\b`
+}
+
+// keywordRegexp matches risk keywords in its first group, or safe keywords
+//
+// Compiled once, as it runs over every displayed answer.
+var keywordRegexp = regexp.MustCompile(`(?i)(` + keywordPattern(riskKeywords) + `)|` + keywordPattern(safeKeywords))
+
+// highlightSegments splits text into styled rich text segments
+//
+// Risk keywords are bold red and safe keywords green; everything else
+// keeps the base style.
+func highlightSegments(text string, base widget.RichTextStyle) []widget.RichTextSegment {
+	var segments []widget.RichTextSegment
+	add := func(s string, style widget.RichTextStyle) {
+		if s != "" {
+			segments = append(segments, &widget.TextSegment{Text: s, Style: style})
+		}
+	}
+
+	last := 0
+	for _, m := range keywordRegexp.FindAllStringSubmatchIndex(text, -1) {
+		add(text[last:m[0]], base)
+		if m[2] >= 0 {
+			add(text[m[0]:m[1]], riskStyle(base))
+		} else {
+			add(text[m[0]:m[1]], safeStyle(base))
+		}
+		last = m[1]
+	}
+	add(text[last:], base)
+
+	return segments
+}
+
+// setResultText shows text in the result view, rendering its markdown
+// and highlighting keywords
+func (app *App) setResultText(text string) {
+	app.resultText = text
+	app.ResultView.Segments = markdownSegments(text)
+	app.ResultView.Refresh()
+}
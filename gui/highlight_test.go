@@ -0,0 +1,33 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestHighlightSegments(t *testing.T) {
+	segments := highlightSegments("Not edible, possibly deadly; its cousin is Edible.", plainStyle)
+
+	var text strings.Builder
+	styles := map[string]widget.RichTextStyle{}
+	for _, s := range segments {
+		seg := s.(*widget.TextSegment)
+		text.WriteString(seg.Text)
+		styles[seg.Text] = seg.Style
+	}
+	if text.String() != "Not edible, possibly deadly; its cousin is Edible." {
+		t.Fatalf("segments join to %q", text.String())
+	}
+
+	for _, risk := range []string{"Not edible", "deadly"} {
+		if style := styles[risk]; style.ColorName != theme.ColorNameError || !style.TextStyle.Bold {
+			t.Errorf("%q styled %+v, want bold red", risk, style)
+		}
+	}
+	if style := styles["Edible"]; style.ColorName != theme.ColorNameSuccess {
+		t.Errorf("%q styled %+v, want green", "Edible", style)
+	}
+}
@@ -0,0 +1,200 @@
+package gui
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+	"github.com/mushroom-classifier/mushroom-classifier-go/history"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/thumbnail"
+)
+
+// History list layout
+const (
+	// Longest side of a history thumbnail in pixels
+	thumbnailSize = 64
+
+	// Rows whose thumbnails are loaded ahead of the visible ones
+	thumbnailPrefetch = 10
+
+	// Rows whose thumbnails are kept in memory around the visible ones
+	thumbnailRetain = 50
+)
+
+// Classification history shared by all windows, opened on first use
+var (
+	historyOnce  sync.Once
+	historyStore *history.Store
+)
+
+// sharedHistory returns the process-wide history store
+//
+// Uses path when set, otherwise the default file in the user's config
+// directory. Returns nil if no location is available, in which case
+// results are not recorded.
+func sharedHistory(path string) *history.Store {
+	historyOnce.Do(func() {
+		if path == "" {
+			var err error
+			path, err = history.DefaultPath()
+			if err != nil {
+				log.Printf("Warning: history disabled: %v", err)
+				return
+			}
+		}
+		historyStore = history.New(path)
+	})
+	return historyStore
+}
+
+// recordHistory saves a successful classification with the parameters
+// that produced it
+func (app *App) recordHistory(imagePath string, meta openai.Meta, content string) {
+	if app.history == nil {
+		return
+	}
+
+	entry := history.Entry{
+		ImagePath:   imagePath,
+		Timestamp:   time.Now(),
+		Model:       meta.Model,
+		MaxTokens:   meta.MaxTokens,
+		Temperature: meta.Temperature,
+		TopP:        meta.TopP,
+		Content:     content,
+	}
+	if err := app.history.Save(entry); err != nil {
+		log.Printf("Warning: failed to record history: %v", err)
+	}
+}
+
+// onHistoryClicked opens a window listing past classifications
+//
+// Newest entries come first. Thumbnails are decoded lazily as rows
+// scroll into view.
+func (app *App) onHistoryClicked() {
+	if app.history == nil {
+		app.showError("History is not available", nil)
+		return
+	}
+
+	entries, err := app.history.Load()
+	if err != nil {
+		app.showError("Failed to load history", err)
+		return
+	}
+
+	// Newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	window := app.FyneApp.NewWindow("Classification History")
+	window.Resize(fyne.NewSize(800, 500))
+
+	detail := widget.NewMultiLineEntry()
+	detail.Wrapping = fyne.TextWrapWord
+	detail.Disable()
+
+	var list *widget.List
+	viewport := thumbnail.Viewport{Count: len(entries), Prefetch: thumbnailPrefetch}
+	retained := thumbnail.Viewport{Count: len(entries), Prefetch: thumbnailRetain}
+	loader := thumbnail.NewLoader(thumbnailSize, decodeImageFile, func(string) {
+		list.Refresh()
+	})
+
+	// Image paths of a range of rows
+	keys := func(r thumbnail.Range) []string {
+		var paths []string
+		for i := r.First; i < r.Last; i++ {
+			if entries[i].ImagePath != "" {
+				paths = append(paths, entries[i].ImagePath)
+			}
+		}
+		return paths
+	}
+
+	list = widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject {
+			thumb := &canvas.Image{FillMode: canvas.ImageFillContain}
+			thumb.SetMinSize(fyne.NewSize(thumbnailSize, thumbnailSize))
+			return container.NewBorder(nil, nil, thumb, nil, widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			entry := entries[id]
+			row := item.(*fyne.Container)
+			label := row.Objects[0].(*widget.Label)
+			thumb := row.Objects[1].(*canvas.Image)
+
+			label.SetText(historyTitle(entry))
+
+			// Load this row's neighbourhood and drop far-away thumbnails
+			shown := thumbnail.Range{First: id, Last: id + 1}
+			loader.Load(keys(viewport.Around(shown)))
+			loader.Retain(keys(retained.Around(shown)))
+
+			img, _ := loader.Get(entry.ImagePath)
+			thumb.Image = img
+			thumb.Refresh()
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		detail.SetText(historyDetail(entries[id]))
+	}
+
+	split := container.NewHSplit(list, container.NewScroll(detail))
+	split.Offset = 0.4
+	window.SetContent(split)
+	window.Show()
+}
+
+// historyTitle summarizes an entry for the history list
+func historyTitle(entry history.Entry) string {
+	name := "Pasted image"
+	if entry.ImagePath != "" {
+		name = filepath.Base(entry.ImagePath)
+	}
+
+	species := analysis.ParseSpecies(entry.Content)
+	if species == "" {
+		species = "Unknown species"
+	}
+
+	return fmt.Sprintf("%s\n%s · %s", name, species, entry.Timestamp.Format("2006-01-02 15:04"))
+}
+
+// historyDetail returns an entry's result headed by the parameters that
+// produced it
+func historyDetail(entry history.Entry) string {
+	meta := openai.Meta{
+		Model:       entry.Model,
+		MaxTokens:   entry.MaxTokens,
+		Temperature: entry.Temperature,
+		TopP:        entry.TopP,
+		Timestamp:   entry.Timestamp,
+	}
+	return meta.String() + "\n\n" + entry.Content
+}
+
+// decodeImageFile reads and decodes an image file
+func decodeImageFile(path string) (image.Image, error) {
+	data, err := base64.ReadImage(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := base64.DecodeOriented(data)
+	return img, err
+}
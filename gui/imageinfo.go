@@ -0,0 +1,45 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+)
+
+// imageInfo describes the loaded image
+//
+// Recorded when the image is loaded so results, exports and history can
+// name the source they belong to.
+type imageInfo struct {
+	// Dimensions in pixels, after EXIF orientation
+	Width, Height int
+
+	// Size of the image data as loaded, in bytes
+	Size int64
+}
+
+// String returns the dimensions and size, e.g. "4032×3024 px, 2.4 MB"
+func (info *imageInfo) String() string {
+	return fmt.Sprintf("%d×%d px, %s", info.Width, info.Height, base64.FormatBytes(info.Size))
+}
+
+// imageName returns the name the loaded image is shown under
+func imageName(imagePath string) string {
+	if imagePath == "" {
+		return "Pasted image"
+	}
+	return filepath.Base(imagePath)
+}
+
+// resultHeader returns the line shown above the classification result
+//
+// Names the image, its dimensions and size and when the result was
+// received. Empty in text-only mode, where no image was sent.
+func (app *App) resultHeader() string {
+	if app.imageInfo == nil || app.TextOnly {
+		return ""
+	}
+	return fmt.Sprintf("%s · %s · %s",
+		imageName(app.ImagePath), app.imageInfo, app.resultTime.Format("2006-01-02 15:04"))
+}
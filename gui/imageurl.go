@@ -0,0 +1,46 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// onURLClicked classifies an image hosted online, e.g. on iNaturalist
+//
+// The URL is passed to the API as is, so the image is neither downloaded
+// nor shown here.
+func (app *App) onURLClicked() {
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://...")
+	urlEntry.Validator = openai.CheckImageURL
+
+	items := []*widget.FormItem{widget.NewFormItem("Image URL", urlEntry)}
+	dialog.ShowForm("Classify Image from URL", "Load", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if app.canceller.busy() {
+			app.showError("Failed to load image", errBusy)
+			return
+		}
+
+		app.reset()
+		app.ImageURL = strings.TrimSpace(urlEntry.Text)
+
+		app.ImageView.File = ""
+		app.ImageView.Image = nil
+		app.ImageView.Refresh()
+		app.setZoom(1)
+
+		app.StatusLabel.SetText(fmt.Sprintf("Image URL: %s", app.ImageURL))
+		app.updateQueueButtons()
+		app.ClassifyButton.Enable()
+		app.FeaturesButton.Disable()
+		app.AddViewButton.Enable()
+	}, app.Window)
+}
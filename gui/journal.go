@@ -0,0 +1,69 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/journal"
+)
+
+// Foraging journal shared by all windows, opened on first use
+var (
+	journalOnce  sync.Once
+	journalStore *journal.Journal
+)
+
+// sharedJournal returns the process-wide journal, or nil if no journal
+// file is configured
+func sharedJournal(path string) *journal.Journal {
+	journalOnce.Do(func() {
+		if path != "" {
+			journalStore = journal.New(path)
+		}
+	})
+	return journalStore
+}
+
+// onJournalClicked appends the displayed classification to the journal
+//
+// Asks for optional notes first, prefilled with the notes entered for a
+// text-only classification.
+func (app *App) onJournalClicked() {
+	if app.journal == nil || app.conversation.empty() {
+		return
+	}
+	answer := app.conversation.exchanges[0].Answer
+	imagePath := app.ImagePath
+
+	notesEntry := widget.NewMultiLineEntry()
+	notesEntry.SetPlaceHolder("Location, habitat, smell...")
+	notesEntry.Wrapping = fyne.TextWrapWord
+	notesEntry.SetText(app.Notes)
+
+	items := []*widget.FormItem{widget.NewFormItem("Notes", notesEntry)}
+	dialog.ShowForm("Add to Journal", "Add", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		entry := journal.Entry{
+			Time:      time.Now(),
+			ImagePath: imagePath,
+			Species:   analysis.ParseSpecies(answer),
+			Edibility: analysis.ParseEdibility(answer),
+			Notes:     notesEntry.Text,
+		}
+		if err := app.journal.Append(entry); err != nil {
+			app.showError("Failed to add to journal", err)
+			return
+		}
+		app.StatusLabel.SetText(fmt.Sprintf("Added to %s", filepath.Base(app.journal.Path())))
+	}, app.Window)
+}
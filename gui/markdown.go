@@ -0,0 +1,92 @@
+package gui
+
+import (
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Markdown the models commonly use in their answers
+var (
+	// "# Title" to "###### Title"
+	headingRegexp = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	// "- item", "* item" or "+ item", possibly indented
+	bulletRegexp = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	// Bold, italic and code spans; unmatched markers are left as they are
+	emphasisRegexp = regexp.MustCompile("\\*\\*([^*\\n]+)\\*\\*|\\b__([^_\\n]+)__\\b|\\*([^*\\s][^*\\n]*)\\*|\\b_([^_\\n]+)_\\b|`([^`\\n]+)`")
+)
+
+// markdownSegments renders the markdown of an answer as rich text
+//
+// Only headings, bullet lists, bold, italic and code spans are
+// understood; any other markup, and markers that are not closed (as in
+// an answer still streaming in), are shown as written. Keywords are
+// highlighted within each piece.
+func markdownSegments(text string) []widget.RichTextSegment {
+	var segments []widget.RichTextSegment
+	for _, line := range strings.SplitAfter(text, "\n") {
+		body, newline := strings.CutSuffix(line, "\n")
+
+		base := plainStyle
+		if m := headingRegexp.FindStringSubmatch(body); m != nil {
+			base = headingStyle(len(m[1]))
+			body = m[2]
+		} else if m := bulletRegexp.FindStringSubmatch(body); m != nil {
+			body = m[1] + "• " + m[2]
+		}
+
+		segments = append(segments, inlineSegments(body, base)...)
+		if newline {
+			segments = append(segments, &widget.TextSegment{Text: "\n", Style: plainStyle})
+		}
+	}
+	return segments
+}
+
+// headingStyle returns the style of a heading of the given level
+//
+// Headings stay inline so the lines around them keep their spacing.
+func headingStyle(level int) widget.RichTextStyle {
+	style := plainStyle
+	style.TextStyle.Bold = true
+	switch level {
+	case 1:
+		style.SizeName = theme.SizeNameHeadingText
+	case 2:
+		style.SizeName = theme.SizeNameSubHeadingText
+	}
+	return style
+}
+
+// inlineSegments renders the bold, italic and code spans of one line
+func inlineSegments(line string, base widget.RichTextStyle) []widget.RichTextSegment {
+	var segments []widget.RichTextSegment
+	last := 0
+	for _, m := range emphasisRegexp.FindAllStringSubmatchIndex(line, -1) {
+		segments = append(segments, highlightSegments(line[last:m[0]], base)...)
+		last = m[1]
+
+		style := base
+		switch {
+		case m[2] >= 0:
+			style.TextStyle.Bold = true
+			segments = append(segments, highlightSegments(line[m[2]:m[3]], style)...)
+		case m[4] >= 0:
+			style.TextStyle.Bold = true
+			segments = append(segments, highlightSegments(line[m[4]:m[5]], style)...)
+		case m[6] >= 0:
+			style.TextStyle.Italic = true
+			segments = append(segments, highlightSegments(line[m[6]:m[7]], style)...)
+		case m[8] >= 0:
+			style.TextStyle.Italic = true
+			segments = append(segments, highlightSegments(line[m[8]:m[9]], style)...)
+		default:
+			// Code is shown as written, without keyword colors
+			code := widget.RichTextStyleCodeInline
+			segments = append(segments, &widget.TextSegment{Text: line[m[10]:m[11]], Style: code})
+		}
+	}
+	return append(segments, highlightSegments(line[last:], base)...)
+}
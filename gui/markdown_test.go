@@ -0,0 +1,89 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// describeSegments writes rich text segments back as text, wrapping
+// styled pieces in tags: b for bold, i for italic, c for code, with 1
+// or 2 added for the heading sizes
+func describeSegments(segments []widget.RichTextSegment) string {
+	var out strings.Builder
+	for _, s := range segments {
+		seg := s.(*widget.TextSegment)
+		var tag string
+		if seg.Style == widget.RichTextStyleCodeInline {
+			tag = "c"
+		} else {
+			if seg.Style.TextStyle.Bold {
+				tag += "b"
+			}
+			if seg.Style.TextStyle.Italic {
+				tag += "i"
+			}
+			switch seg.Style.SizeName {
+			case theme.SizeNameHeadingText:
+				tag += "1"
+			case theme.SizeNameSubHeadingText:
+				tag += "2"
+			}
+		}
+		if tag == "" {
+			out.WriteString(seg.Text)
+		} else {
+			out.WriteString("<" + tag + ">" + seg.Text + "</" + tag + ">")
+		}
+	}
+	return out.String()
+}
+
+func TestMarkdownSegments(t *testing.T) {
+	tests := []struct {
+		markdown string
+		want     string
+	}{
+		{"# Chanterelle", "<b1>Chanterelle</b1>"},
+		{"## Features\nOrange cap", "<b2>Features</b2>\nOrange cap"},
+		{"### Notes ###", "<b>Notes</b>"},
+		{"#hashtag", "#hashtag"},
+		{"- gills\n  * ridges\n+ spores", "• gills\n  • ridges\n• spores"},
+		{"- **Cap**: orange", "• <b>Cap</b>: orange"},
+		{"**Cantharellus** cibarius", "<b>Cantharellus</b> cibarius"},
+		{"__bold__, _italic_ and *also italic*", "<b>bold</b>, <i>italic</i> and <i>also italic</i>"},
+		{"Set `max_tokens` higher", "Set <c>max_tokens</c> higher"},
+
+		// Unclosed markers, as in a streaming answer, and markers that
+		// are not emphasis are shown as written
+		{"**Cantharel", "**Cantharel"},
+		{"snake_case_name", "snake_case_name"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+		{"line\n", "line\n"},
+	}
+	for _, tt := range tests {
+		if got := describeSegments(markdownSegments(tt.markdown)); got != tt.want {
+			t.Errorf("markdownSegments(%q) = %q, want %q", tt.markdown, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownSegmentsHighlightKeywords(t *testing.T) {
+	// Keywords keep their colors inside markup
+	var keywords []string
+	for _, s := range markdownSegments("## Deadly\n**Not edible**") {
+		seg := s.(*widget.TextSegment)
+		if strings.TrimSpace(seg.Text) == "" {
+			continue
+		}
+		keywords = append(keywords, seg.Text)
+		if seg.Style.ColorName != theme.ColorNameError || !seg.Style.TextStyle.Bold {
+			t.Errorf("%q styled %+v, want bold red", seg.Text, seg.Style)
+		}
+	}
+	if len(keywords) != 2 {
+		t.Errorf("keyword segments %q, want Deadly and Not edible", keywords)
+	}
+}
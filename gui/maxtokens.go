@@ -0,0 +1,44 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/config"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// truncationWarning is added to the status when an answer hit the limit
+const truncationWarning = "Response was truncated; increase max tokens."
+
+// withTruncationWarning appends truncationWarning to status when resp was
+// cut off
+func withTruncationWarning(status string, resp *openai.Response) string {
+	if !resp.Truncated {
+		return status
+	}
+	return status + ". " + truncationWarning
+}
+
+// validateMaxTokens checks the text of the max tokens entry
+func validateMaxTokens(text string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || n < 1 || n > config.MaxTokensLimit {
+		return fmt.Errorf("enter a number between 1 and %d", config.MaxTokensLimit)
+	}
+	return nil
+}
+
+// parseMaxTokens reads the max tokens entry
+//
+// Text that is not a number falls back to fallback, and numbers out of
+// range are clamped to 1..config.MaxTokensLimit, so a half-typed value
+// never fails a request.
+func parseMaxTokens(text string, fallback int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return fallback
+	}
+	return min(max(n, 1), config.MaxTokensLimit)
+}
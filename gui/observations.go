@@ -0,0 +1,69 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+)
+
+// Suggestions offered for the observations with a small set of usual
+// answers; anything else can still be typed
+var (
+	sporePrintColors = []string{"White", "Cream", "Yellow", "Pink", "Rusty brown", "Brown", "Purple-brown", "Black", "Green"}
+	seasons          = []string{"Spring", "Summer", "Autumn", "Winter"}
+)
+
+// onObservationsClicked asks for field observations that narrow the
+// identification
+//
+// They are added to every following classification prompt until changed;
+// clearing all fields stops sending them.
+func (app *App) onObservationsClicked() {
+	sporeEntry := widget.NewSelectEntry(sporePrintColors)
+	sporeEntry.SetPlaceHolder("Color of the spore print")
+	sporeEntry.SetText(app.Observations.SporePrintColor)
+
+	habitatEntry := widget.NewEntry()
+	habitatEntry.SetPlaceHolder("e.g. beech forest, meadow, lawn")
+	habitatEntry.SetText(app.Observations.Habitat)
+
+	substrateEntry := widget.NewEntry()
+	substrateEntry.SetPlaceHolder("e.g. dead hardwood, soil, dung")
+	substrateEntry.SetText(app.Observations.Substrate)
+
+	seasonEntry := widget.NewSelectEntry(seasons)
+	seasonEntry.SetPlaceHolder("Time of year found")
+	seasonEntry.SetText(app.Observations.Season)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Spore print", sporeEntry),
+		widget.NewFormItem("Habitat", habitatEntry),
+		widget.NewFormItem("Substrate", substrateEntry),
+		widget.NewFormItem("Season", seasonEntry),
+	}
+	dialog.ShowForm("Field Observations", "Save", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		app.Observations = prompts.Observations{
+			SporePrintColor: sporeEntry.Text,
+			Habitat:         habitatEntry.Text,
+			Substrate:       substrateEntry.Text,
+			Season:          seasonEntry.Text,
+		}
+		app.updateObservationsButton()
+	}, app.Window)
+}
+
+// updateObservationsButton shows how many observations will be sent
+func (app *App) updateObservationsButton() {
+	label := "Observations"
+	if n := app.Observations.Count(); n > 0 {
+		label = fmt.Sprintf("Observations (%d)", n)
+	}
+	app.ObservationsButton.SetText(label)
+}
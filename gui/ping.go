@@ -0,0 +1,54 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/provider"
+)
+
+// checkingStatus is shown while the startup connection check runs
+const checkingStatus = "Checking API connection..."
+
+// checkConnection tests the API key and URL in the background
+//
+// The outcome replaces the status once known, so a bad key or URL shows
+// up before an image is classified. A success is not reported if the
+// user has already moved on.
+func (app *App) checkConnection() {
+	req := app.newRequest(app.currentParams(), "")
+	app.StatusLabel.SetText(checkingStatus)
+
+	go func() {
+		err := provider.Ping(context.Background(), app.provider, req)
+		if err == nil && app.StatusLabel.Text != checkingStatus {
+			return
+		}
+		app.StatusLabel.SetText(connectionStatus(err))
+	}()
+}
+
+// connectionStatus describes the result of a connection check
+func connectionStatus(err error) string {
+	if err == nil {
+		return "Connected. Select an image to begin"
+	}
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Category == openai.CategoryAuth:
+			return "Invalid API key"
+		case apiErr.Category == openai.CategoryRateLimit:
+			return "Connected, but rate limited or out of quota"
+		case apiErr.Category == openai.CategoryTimeout:
+			return "The API did not respond in time; check the API URL and network"
+		case apiErr.StatusCode == http.StatusNotFound:
+			return "API URL not found; check the API URL"
+		}
+	}
+	return fmt.Sprintf("Connection check failed: %v", err)
+}
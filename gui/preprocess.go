@@ -0,0 +1,47 @@
+package gui
+
+import (
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// prepareOptions returns the configured preprocessing
+func (app *App) prepareOptions() base64.PrepareOptions {
+	return base64.PrepareOptions{
+		MaxPixels:      app.Config.MaxImagePixels,
+		StripMetadata:  app.Config.StripMetadata,
+		MaxDimension:   app.Config.MaxImageDimension,
+		JPEGQuality:    app.Config.JPEGQuality,
+		LetterboxRatio: app.Config.LetterboxRatio,
+		LetterboxColor: base64.LetterboxGrey,
+
+		MaxAspectRatio: app.Config.PanoramaMaxRatio,
+		SplitPanoramas: app.Config.PanoramaMode == "tile",
+		MaxTiles:       app.Config.MaxImagesPerRequest,
+
+		EnhanceDarkThreshold: app.Config.EnhanceDarkThreshold,
+	}
+}
+
+// prepareImage applies the configured preprocessing before upload
+func (app *App) prepareImage(data []byte) ([]byte, error) {
+	return base64.PrepareImage(data, app.prepareOptions())
+}
+
+// prepareImages is like prepareImage but splits panoramas into tiles
+// when configured, returning one input per image to send
+func (app *App) prepareImages(data []byte) ([]openai.ImageInput, error) {
+	prepared, err := base64.PrepareImages(data, app.prepareOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]openai.ImageInput, 0, len(prepared))
+	for _, p := range prepared {
+		inputs = append(inputs, openai.ImageInput{
+			Data:     base64.EncodeData(p),
+			MimeType: base64.DetectMimeType(p),
+		})
+	}
+	return inputs, nil
+}
@@ -0,0 +1,48 @@
+package gui
+
+// streamProgress estimates how far a streamed answer has come
+//
+// OpenAI sends roughly one token per chunk, so the number of chunks
+// received against the max tokens limit gives a rough fraction. Answers
+// usually end well before the limit; finish then snaps the bar to full.
+type streamProgress struct {
+	app       *App
+	maxTokens int
+	chunks    int
+}
+
+// startStreamProgress swaps the spinner for a progress bar at zero
+//
+// Returns nil, which keeps the spinner, if maxTokens gives no limit to
+// measure against.
+func (app *App) startStreamProgress(maxTokens int) *streamProgress {
+	if maxTokens <= 0 {
+		return nil
+	}
+	app.Spinner.Stop()
+	app.Spinner.Hide()
+	app.StreamProgress.SetValue(0)
+	app.StreamProgress.Show()
+	return &streamProgress{app: app, maxTokens: maxTokens}
+}
+
+// chunk records one more received chunk and advances the bar
+func (p *streamProgress) chunk() {
+	if p == nil {
+		return
+	}
+	p.chunks++
+	p.app.StreamProgress.SetValue(min(float64(p.chunks)/float64(p.maxTokens), 1))
+}
+
+// finish fills the bar if the answer arrived, or hides it otherwise
+func (p *streamProgress) finish(complete bool) {
+	if p == nil {
+		return
+	}
+	if complete {
+		p.app.StreamProgress.SetValue(1)
+		return
+	}
+	p.app.StreamProgress.Hide()
+}
@@ -0,0 +1,103 @@
+package gui
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+)
+
+// Edited classification prompt shared by all windows, opened on first use
+var (
+	promptOnce  sync.Once
+	promptStore *prompts.Custom
+)
+
+// sharedPrompt returns the process-wide prompt store
+//
+// Uses path when set, otherwise the default file in the user's config
+// directory. Returns nil if no location is available, in which case the
+// built-in prompt is always used and cannot be edited.
+func sharedPrompt(path string) *prompts.Custom {
+	promptOnce.Do(func() {
+		if path == "" {
+			var err error
+			path, err = prompts.DefaultCustomPath()
+			if err != nil {
+				log.Printf("Warning: prompt editing disabled: %v", err)
+				return
+			}
+		}
+		promptStore = prompts.NewCustom(path)
+	})
+	return promptStore
+}
+
+// mushroomPrompt returns the classification prompt, as edited by the user
+// and in the configured language
+func (app *App) mushroomPrompt() string {
+	return prompts.WithLanguage(app.editedPrompt(), app.Config.Language)
+}
+
+// editedPrompt returns the classification prompt as edited by the user
+//
+// Falls back to the built-in prompt if the edited one cannot be read.
+func (app *App) editedPrompt() string {
+	if app.prompt == nil {
+		return getMushroomPrompt()
+	}
+
+	text, err := app.prompt.Load()
+	if err != nil {
+		log.Printf("Warning: %v; using the built-in prompt", err)
+		return getMushroomPrompt()
+	}
+	return text
+}
+
+// onEditPromptClicked opens a window for editing the classification prompt
+//
+// Saved changes apply to the next classification in every window. "Reset
+// to Default" puts the built-in prompt back into the editor, to be saved
+// like any other change.
+func (app *App) onEditPromptClicked() {
+	if app.prompt == nil {
+		return
+	}
+
+	window := app.FyneApp.NewWindow("Classification Prompt")
+	window.Resize(fyne.NewSize(700, 500))
+
+	promptEntry := widget.NewMultiLineEntry()
+	promptEntry.Wrapping = fyne.TextWrapWord
+	promptEntry.SetText(app.editedPrompt())
+	promptEntry.SetMinRowsVisible(15)
+
+	status := widget.NewLabel(fmt.Sprintf("Saved to %s", app.prompt.Path()))
+
+	saveButton := widget.NewButton("Save", func() {
+		if err := app.prompt.Save(promptEntry.Text); err != nil {
+			status.SetText(fmt.Sprintf("Failed to save: %v", err))
+			return
+		}
+		status.SetText("Saved; used for the next classification")
+	})
+	resetButton := widget.NewButton("Reset to Default", func() {
+		promptEntry.SetText(getMushroomPrompt())
+		status.SetText("Built-in prompt restored; save to use it")
+	})
+	closeButton := widget.NewButton("Close", window.Close)
+
+	buttons := container.NewHBox(saveButton, resetButton, layout.NewSpacer(), closeButton)
+	window.SetContent(container.NewPadded(container.NewBorder(
+		widget.NewLabel("Prompt sent with each image:"),
+		container.NewVBox(status, buttons), nil, nil,
+		container.NewScroll(promptEntry))))
+	window.Show()
+}
@@ -0,0 +1,219 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+	"github.com/mushroom-classifier/mushroom-classifier-go/queue"
+)
+
+// Classification queue shared by all windows, opened on first use
+var (
+	jobQueueOnce sync.Once
+	jobQueue     *queue.Queue
+
+	// Set while a window is working through the queue
+	jobQueueRunning atomic.Bool
+)
+
+// sharedQueue returns the process-wide classification queue
+//
+// The queue is persisted to path when it is set. If the stored queue
+// cannot be read, a warning is logged and an empty in-memory queue is
+// used so the application still starts.
+func sharedQueue(path string) *queue.Queue {
+	jobQueueOnce.Do(func() {
+		q, err := queue.Open(path)
+		if err != nil {
+			log.Printf("Warning: %v; starting with an empty queue", err)
+			q, _ = queue.Open("")
+		}
+		jobQueue = q
+	})
+	return jobQueue
+}
+
+// offerResume asks whether to resume jobs left over from a previous run
+func (app *App) offerResume() {
+	pending := app.queue.Len()
+	if pending == 0 {
+		return
+	}
+
+	noun := "images were"
+	if pending == 1 {
+		noun = "image was"
+	}
+	message := fmt.Sprintf("%d queued %s not classified before the application closed. Resume now?", pending, noun)
+	dialog.ShowConfirm("Resume Queue", message, func(resume bool) {
+		if resume {
+			app.onRunQueueClicked()
+			return
+		}
+		if err := app.queue.Clear(); err != nil {
+			app.showError("Failed to clear queue", err)
+		}
+		app.updateQueueButtons()
+	}, app.Window)
+}
+
+// onQueueClicked adds the loaded image to the classification queue
+func (app *App) onQueueClicked() {
+	if app.ImagePath == "" || app.TextOnly {
+		app.showError("Only image files can be queued", nil)
+		return
+	}
+
+	job := queue.Job{
+		ImagePath: app.ImagePath,
+		Model:     app.currentParams().Model,
+	}
+	if err := app.queue.Enqueue(job); err != nil {
+		app.showError("Failed to queue image", err)
+		return
+	}
+
+	app.StatusLabel.SetText(fmt.Sprintf("Queued: %s", filepath.Base(job.ImagePath)))
+	app.updateQueueButtons()
+}
+
+// onRunQueueClicked classifies every queued image in turn
+//
+// Each job stays in the queue until its result is in, so jobs
+// interrupted by a crash are offered again on the next start. Results
+// are collected in the result view. Cancel stops after the current job.
+func (app *App) onRunQueueClicked() {
+	ctx, ok := app.beginRequest()
+	if !ok {
+		return
+	}
+	if !jobQueueRunning.CompareAndSwap(false, true) {
+		app.endRequest()
+		app.showError("The queue is already running in another window", nil)
+		return
+	}
+
+	app.UploadButton.Disable()
+	app.ClassifyButton.Disable()
+	app.QueueButton.Disable()
+	app.RunQueueButton.Disable()
+	app.AskButton.Disable()
+	app.CopyButton.Disable()
+	app.SaveButton.Disable()
+	app.JournalButton.Disable()
+	app.clearExplanation()
+	app.offerRetry(false)
+	// The queue results replace the displayed conversation
+	app.conversation.reset()
+	app.setResultText("")
+	app.clearRisk()
+
+	go func() {
+		var results strings.Builder
+		done := 0
+		for ctx.Err() == nil {
+			job, ok := app.queue.Peek()
+			if !ok {
+				break
+			}
+
+			name := filepath.Base(job.ImagePath)
+			app.StatusLabel.SetText(fmt.Sprintf("Classifying %s (%d left)...", name, app.queue.Len()))
+
+			content, err := app.classifyJob(ctx, job)
+			if errors.Is(err, context.Canceled) {
+				break
+			}
+			if err != nil {
+				content = fmt.Sprintf("Failed: %v", err)
+			}
+
+			if _, _, err := app.queue.Dequeue(); err != nil {
+				app.showError("Failed to update queue", err)
+				break
+			}
+			done++
+
+			fmt.Fprintf(&results, "=== %s ===\n\n%s\n\n", name, content)
+			app.setResultText(results.String())
+			app.CopyButton.Enable()
+			app.SaveButton.Enable()
+			app.updateQueueButtons()
+		}
+		app.endRequest()
+		jobQueueRunning.Store(false)
+
+		if remaining := app.queue.Len(); remaining > 0 {
+			app.StatusLabel.SetText(fmt.Sprintf("Queue stopped: %d classified, %d left", done, remaining))
+		} else {
+			app.StatusLabel.SetText(fmt.Sprintf("Queue finished: %d classified", done))
+		}
+
+		app.UploadButton.Enable()
+		if app.Base64Image != "" || app.ImageURL != "" || app.TextOnly {
+			app.ClassifyButton.Enable()
+		}
+		app.updateQueueButtons()
+	}()
+}
+
+// classifyJob reads a queued image and classifies it
+func (app *App) classifyJob(ctx context.Context, job queue.Job) (string, error) {
+	data, err := base64.ReadImageWithLimit(job.ImagePath, app.Config.MaxImageBytes)
+	if err != nil {
+		return "", err
+	}
+	if err := base64.ValidateImage(data); err != nil {
+		return "", err
+	}
+	inputs, err := app.prepareImages(data)
+	if err != nil {
+		return "", err
+	}
+
+	params := app.currentParams()
+	params.Model = job.Model
+	req := app.newRequest(params, app.mushroomPrompt())
+	req.Base64Image = ""
+	req.MimeType = ""
+	req.ImageURL = ""
+	req.Images = inputs
+
+	resp, err := app.provider.AnalyzeImage(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if err := resp.Err(); err != nil {
+		return "", err
+	}
+
+	app.recordHistory(job.ImagePath, resp.Meta, resp.Content)
+	return resp.Content, nil
+}
+
+// updateQueueButtons reflects the loaded image and queue length in the
+// queue buttons
+func (app *App) updateQueueButtons() {
+	if app.ImagePath != "" && !app.TextOnly {
+		app.QueueButton.Enable()
+	} else {
+		app.QueueButton.Disable()
+	}
+
+	pending := app.queue.Len()
+	app.RunQueueButton.SetText(fmt.Sprintf("Run Queue (%d)", pending))
+	if pending > 0 && !jobQueueRunning.Load() {
+		app.RunQueueButton.Enable()
+	} else {
+		app.RunQueueButton.Disable()
+	}
+}
@@ -0,0 +1,24 @@
+package gui
+
+import (
+	"sync"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/httpclient"
+)
+
+// API rate limiter shared by all windows, created on first use
+var (
+	rateLimiterOnce sync.Once
+	rateLimiter     *httpclient.RateLimiter
+)
+
+// sharedRateLimiter returns the process-wide rate limiter
+//
+// All windows draw on the same API quota, so they share one limiter.
+// Returns nil, which does not limit, if perMinute is 0.
+func sharedRateLimiter(perMinute int) *httpclient.RateLimiter {
+	rateLimiterOnce.Do(func() {
+		rateLimiter = httpclient.NewRateLimiter(perMinute)
+	})
+	return rateLimiter
+}
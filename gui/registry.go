@@ -0,0 +1,79 @@
+package gui
+
+import (
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+)
+
+// windowRegistry tracks the open application windows
+//
+// Every App registers itself when created and unregisters when its window
+// is closed. The Fyne application is only quit once the last registered
+// window has gone, so closing one of several windows leaves the others
+// running.
+type windowRegistry struct {
+	mu      sync.Mutex
+	windows map[*App]struct{}
+}
+
+// windows is the process-wide registry shared by all App instances
+var windows = &windowRegistry{}
+
+// Shared Fyne application, created on first use
+var (
+	fyneAppOnce sync.Once
+	fyneApp     fyne.App
+)
+
+// sharedFyneApp returns the single Fyne application for this process
+//
+// Fyne only supports one application instance, so every window created
+// through NewApp is attached to the same one.
+func sharedFyneApp() fyne.App {
+	fyneAppOnce.Do(func() {
+		fyneApp = app.New()
+	})
+	return fyneApp
+}
+
+// add registers a window and returns the number of open windows
+func (r *windowRegistry) add(a *App) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.windows == nil {
+		r.windows = make(map[*App]struct{})
+	}
+	r.windows[a] = struct{}{}
+	return len(r.windows)
+}
+
+// each calls fn for every open window
+//
+// fn runs without the registry locked, so it may open or close windows.
+func (r *windowRegistry) each(fn func(*App)) {
+	r.mu.Lock()
+	apps := make([]*App, 0, len(r.windows))
+	for a := range r.windows {
+		apps = append(apps, a)
+	}
+	r.mu.Unlock()
+
+	for _, a := range apps {
+		fn(a)
+	}
+}
+
+// remove unregisters a window and returns the number still open
+//
+// Removing a window that was never registered is a no-op, so a window
+// closed twice cannot push the count below the real number of windows.
+func (r *windowRegistry) remove(a *App) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.windows, a)
+	return len(r.windows)
+}
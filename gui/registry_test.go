@@ -0,0 +1,68 @@
+package gui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+func TestWindowRegistry(t *testing.T) {
+	var r windowRegistry
+	a, b := &App{}, &App{}
+
+	if n := r.add(a); n != 1 {
+		t.Errorf("add first = %d, want 1", n)
+	}
+	if n := r.add(b); n != 2 {
+		t.Errorf("add second = %d, want 2", n)
+	}
+	if n := r.add(b); n != 2 {
+		t.Errorf("adding a window twice = %d, want 2", n)
+	}
+
+	seen := 0
+	r.each(func(*App) { seen++ })
+	if seen != 2 {
+		t.Errorf("each visited %d windows, want 2", seen)
+	}
+
+	if n := r.remove(a); n != 1 {
+		t.Errorf("remove first = %d, want 1", n)
+	}
+	if n := r.remove(a); n != 1 {
+		t.Errorf("closing a window twice = %d, want 1", n)
+	}
+	if n := r.remove(b); n != 0 {
+		t.Errorf("remove last = %d, want 0", n)
+	}
+}
+
+// quitRecorder is a Fyne application that counts calls to Quit
+type quitRecorder struct {
+	fyne.App
+	quits int
+}
+
+func (q *quitRecorder) Quit() {
+	q.quits++
+}
+
+func TestOnWindowClosedQuitsAfterLastWindow(t *testing.T) {
+	saved := windows
+	windows = &windowRegistry{}
+	t.Cleanup(func() { windows = saved })
+
+	fyneApp := &quitRecorder{}
+	first, second := &App{FyneApp: fyneApp}, &App{FyneApp: fyneApp}
+	windows.add(first)
+	windows.add(second)
+
+	first.onWindowClosed()
+	if fyneApp.quits != 0 {
+		t.Error("quit with a window still open")
+	}
+	second.onWindowClosed()
+	if fyneApp.quits != 1 {
+		t.Errorf("quit %d times after the last window closed, want 1", fyneApp.quits)
+	}
+}
@@ -0,0 +1,60 @@
+package gui
+
+import (
+	"context"
+	"log"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+)
+
+// retryQuestion labels the automatic retry in the conversation
+const retryQuestion = "(Low confidence: looking again with high image detail)"
+
+// retryDetailed asks again about a low-confidence result
+//
+// Sends the same images at high detail with the closer-look prompt.
+// Returns nil if the retry is not needed or fails; failures are logged
+// and the first answer stands.
+func (app *App) retryDetailed(ctx context.Context, req *openai.Request, first *openai.Response) *openai.Response {
+	detailed := detailedRetry(req, first.Content, app.Config.RetryLowConfidence, app.detailedPrompt())
+	if detailed == nil {
+		return nil
+	}
+
+	app.StatusLabel.SetText("Low confidence, looking again in more detail...")
+
+	resp, err := app.provider.AnalyzeImage(ctx, detailed)
+	if err == nil {
+		err = resp.Err()
+	}
+	if err != nil {
+		log.Printf("Warning: detailed retry failed: %v", err)
+		return nil
+	}
+	return resp
+}
+
+// detailedRetry returns the closer-look request to send after answer,
+// or nil if the answer does not call for one
+//
+// Only answers of Low confidence are retried, and only when enabled. The
+// retry is a copy of req with prompt and high image detail.
+func detailedRetry(req *openai.Request, answer string, enabled bool, prompt string) *openai.Request {
+	if !enabled || analysis.ParseConfidence(answer) != analysis.Low {
+		return nil
+	}
+
+	detailed := *req
+	detailed.Prompt = prompt
+	detailed.ImageDetail = "high"
+	return &detailed
+}
+
+// detailedPrompt returns the closer-look prompt with the field
+// observations, in the configured language
+func (app *App) detailedPrompt() string {
+	prompt := prompts.WithObservations(prompts.MushroomDetailed(), app.Observations)
+	return prompts.WithLanguage(prompt, app.Config.Language)
+}
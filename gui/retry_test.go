@@ -0,0 +1,41 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+func TestDetailedRetry(t *testing.T) {
+	req := &openai.Request{Prompt: "Identify this mushroom", ImageDetail: "low", Base64Image: "AAAA"}
+
+	tests := []struct {
+		answer  string
+		enabled bool
+		retry   bool
+	}{
+		{"**Species**: Chanterelle\n**Confidence**: Low", true, true},
+		{"**Confidence**: low - the gills are not visible", true, true},
+		{"**Species**: Chanterelle\n**Confidence**: Low", false, false},
+		{"**Confidence**: Medium", true, false},
+		{"**Confidence**: High", true, false},
+		{"No confidence stated", true, false},
+	}
+	for _, tt := range tests {
+		detailed := detailedRetry(req, tt.answer, tt.enabled, "Look closer")
+		if (detailed != nil) != tt.retry {
+			t.Errorf("answer %q, enabled %v: retry = %v, want %v", tt.answer, tt.enabled, detailed != nil, tt.retry)
+			continue
+		}
+		if detailed == nil {
+			continue
+		}
+		if detailed.Prompt != "Look closer" || detailed.ImageDetail != "high" || detailed.Base64Image != "AAAA" {
+			t.Errorf("retry request = %+v", detailed)
+		}
+	}
+
+	if req.Prompt != "Identify this mushroom" || req.ImageDetail != "low" {
+		t.Errorf("original request changed: %+v", req)
+	}
+}
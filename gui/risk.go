@@ -0,0 +1,45 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+)
+
+// riskImportance picks the status color for a classification answer
+//
+// Red if the edibility statement mentions any risk keyword, green if it
+// calls the mushroom edible with high confidence, and amber for other
+// edible or unclear verdicts. Answers without an edibility statement
+// stay neutral.
+func riskImportance(answer string) widget.Importance {
+	edibility := analysis.ParseEdibility(answer)
+	if edibility == "" {
+		return widget.MediumImportance
+	}
+
+	safe := false
+	for _, m := range keywordRegexp.FindAllStringSubmatchIndex(edibility, -1) {
+		if m[2] >= 0 {
+			return widget.DangerImportance
+		}
+		safe = true
+	}
+
+	if safe && analysis.ParseConfidence(answer) == analysis.High {
+		return widget.SuccessImportance
+	}
+	return widget.WarningImportance
+}
+
+// showRisk colors the status line by the risk of the displayed answer
+func (app *App) showRisk(answer string) {
+	app.StatusLabel.Importance = riskImportance(answer)
+	app.StatusLabel.Refresh()
+}
+
+// clearRisk returns the status line to its neutral color
+func (app *App) clearRisk() {
+	app.StatusLabel.Importance = widget.MediumImportance
+	app.StatusLabel.Refresh()
+}
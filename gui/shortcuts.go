@@ -0,0 +1,43 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// addShortcuts binds keyboard shortcuts for the main actions
+//
+// Ctrl+O opens an image, Ctrl+Enter classifies it and Ctrl+Q quits.
+// Opening and classifying do nothing while their buttons are disabled,
+// e.g. during a classification.
+func (app *App) addShortcuts() {
+	canvas := app.Window.Canvas()
+
+	canvas.AddShortcut(shortcut(fyne.KeyO), func(fyne.Shortcut) {
+		whenEnabled(app.UploadButton, app.onUploadClicked)
+	})
+
+	classify := func(fyne.Shortcut) {
+		whenEnabled(app.ClassifyButton, app.onClassifyClicked)
+	}
+	canvas.AddShortcut(shortcut(fyne.KeyReturn), classify)
+	canvas.AddShortcut(shortcut(fyne.KeyEnter), classify)
+
+	canvas.AddShortcut(shortcut(fyne.KeyQ), func(fyne.Shortcut) {
+		app.FyneApp.Quit()
+	})
+}
+
+// shortcut returns the Ctrl (Cmd on macOS) shortcut for key
+func shortcut(key fyne.KeyName) *desktop.CustomShortcut {
+	return &desktop.CustomShortcut{KeyName: key, Modifier: fyne.KeyModifierShortcutDefault}
+}
+
+// whenEnabled calls action unless button is disabled
+func whenEnabled(button *widget.Button, action func()) {
+	if button.Disabled() {
+		return
+	}
+	action()
+}
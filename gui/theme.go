@@ -0,0 +1,137 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// Theme choices, as in the THEME setting
+const (
+	themeSystem = "system"
+	themeLight  = "light"
+	themeDark   = "dark"
+)
+
+// Theme picked for all windows, loaded on first use
+var (
+	themeOnce   sync.Once
+	themeChoice string
+)
+
+// variantTheme is the default theme locked to one variant
+//
+// Colors looked up by name, such as those of the edibility highlighting,
+// follow the variant, so they stay legible in both.
+type variantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+// Color returns the named color of the locked variant
+func (t *variantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, t.variant)
+}
+
+// sharedTheme returns the theme choice and applies it on first use
+//
+// A choice saved from the GUI wins over fallback, the THEME setting.
+func sharedTheme(fyneApp fyne.App, fallback string) string {
+	themeOnce.Do(func() {
+		themeChoice = loadThemeChoice(fallback)
+		applyTheme(fyneApp, themeChoice)
+	})
+	return themeChoice
+}
+
+// applyTheme switches the application to a theme choice
+func applyTheme(fyneApp fyne.App, choice string) {
+	switch choice {
+	case themeDark:
+		fyneApp.Settings().SetTheme(&variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantDark})
+	case themeLight:
+		fyneApp.Settings().SetTheme(&variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantLight})
+	default:
+		// The default theme follows the desktop
+		fyneApp.Settings().SetTheme(theme.DefaultTheme())
+	}
+}
+
+// isDark reports whether the application currently shows dark colors
+func isDark(fyneApp fyne.App, choice string) bool {
+	if choice == themeSystem {
+		return fyneApp.Settings().ThemeVariant() == theme.VariantDark
+	}
+	return choice == themeDark
+}
+
+// themeFilePath returns the file the theme choice is saved to
+func themeFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "mushroom-classifier", "theme.txt"), nil
+}
+
+// loadThemeChoice returns the saved theme choice, or fallback if none
+func loadThemeChoice(fallback string) string {
+	path, err := themeFilePath()
+	if err != nil {
+		return fallback
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fallback
+	}
+
+	switch choice := strings.TrimSpace(string(data)); choice {
+	case themeSystem, themeLight, themeDark:
+		return choice
+	default:
+		return fallback
+	}
+}
+
+// saveThemeChoice remembers the theme choice for the next start
+func saveThemeChoice(choice string) error {
+	path, err := themeFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(choice+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save theme: %w", err)
+	}
+	return nil
+}
+
+// onDarkModeChanged switches every window to the dark or light theme
+func (app *App) onDarkModeChanged(dark bool) {
+	choice := themeLight
+	if dark {
+		choice = themeDark
+	}
+	if choice == themeChoice {
+		// Already applied, e.g. when syncing another window's switch
+		return
+	}
+
+	themeChoice = choice
+	applyTheme(app.FyneApp, choice)
+	windows.each(func(other *App) {
+		other.DarkModeCheck.SetChecked(dark)
+	})
+
+	if err := saveThemeChoice(choice); err != nil {
+		app.showError("Failed to save theme", err)
+	}
+}
@@ -0,0 +1,24 @@
+package gui
+
+import "fyne.io/fyne/v2"
+
+// uiRunner is implemented by Fyne drivers that can run a function on
+// the goroutine handling UI events (fyne.Do in Fyne 2.6 and later)
+type uiRunner interface {
+	DoFromGoroutine(fn func(), wait bool)
+}
+
+// runOnUI runs fn on the UI goroutine, for callbacks from timers and
+// other goroutines that touch widgets or App state
+//
+// Drivers without such a call (Fyne before 2.6) allow widgets to be
+// updated from any goroutine, so fn is then called directly.
+func runOnUI(fyneApp fyne.App, fn func()) {
+	if fyneApp != nil {
+		if runner, ok := fyneApp.Driver().(uiRunner); ok {
+			runner.DoFromGoroutine(fn, false)
+			return
+		}
+	}
+	fn()
+}
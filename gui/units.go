@@ -0,0 +1,124 @@
+package gui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit systems accepted by convertUnits
+const (
+	unitsMetric   = "metric"
+	unitsImperial = "imperial"
+)
+
+// measurementPattern matches a number or range followed by a unit,
+// e.g. "3 inches", "5-10 cm" or "2 to 4 oz"
+var measurementPattern = regexp.MustCompile(
+	`(\d+(?:\.\d+)?)(?:\s*(?:-|–|to)\s*(\d+(?:\.\d+)?))?\s*` +
+		`(inches|inch|centimet(?:er|re)s?|cm|millimet(?:er|re)s?|mm|feet|foot|ft|met(?:er|re)s?|ounces?|oz|grams?|g|°F|°C)\b`)
+
+// unitConversion describes how to convert one unit to the other system
+type unitConversion struct {
+	// System the unit belongs to
+	system string
+
+	// Unit written after the converted value
+	target string
+
+	// Converts a value to the target unit
+	convert func(float64) float64
+}
+
+// conversions maps lower-cased unit spellings to their conversion
+var conversions = map[string]unitConversion{
+	"inch":       {unitsImperial, "cm", func(v float64) float64 { return v * 2.54 }},
+	"inches":     {unitsImperial, "cm", func(v float64) float64 { return v * 2.54 }},
+	"foot":       {unitsImperial, "m", func(v float64) float64 { return v * 0.3048 }},
+	"feet":       {unitsImperial, "m", func(v float64) float64 { return v * 0.3048 }},
+	"ft":         {unitsImperial, "m", func(v float64) float64 { return v * 0.3048 }},
+	"ounce":      {unitsImperial, "g", func(v float64) float64 { return v * 28.3495 }},
+	"ounces":     {unitsImperial, "g", func(v float64) float64 { return v * 28.3495 }},
+	"oz":         {unitsImperial, "g", func(v float64) float64 { return v * 28.3495 }},
+	"°f":         {unitsImperial, "°C", func(v float64) float64 { return (v - 32) * 5 / 9 }},
+	"cm":         {unitsMetric, "in", func(v float64) float64 { return v / 2.54 }},
+	"centimeter": {unitsMetric, "in", func(v float64) float64 { return v / 2.54 }},
+	"mm":         {unitsMetric, "in", func(v float64) float64 { return v / 25.4 }},
+	"millimeter": {unitsMetric, "in", func(v float64) float64 { return v / 25.4 }},
+	"meter":      {unitsMetric, "ft", func(v float64) float64 { return v / 0.3048 }},
+	"gram":       {unitsMetric, "oz", func(v float64) float64 { return v / 28.3495 }},
+	"g":          {unitsMetric, "oz", func(v float64) float64 { return v / 28.3495 }},
+	"°c":         {unitsMetric, "°F", func(v float64) float64 { return v*9/5 + 32 }},
+}
+
+// lookupUnit finds the conversion for a unit as written in the text
+func lookupUnit(unit string) (unitConversion, bool) {
+	key := strings.ToLower(unit)
+	key = strings.Replace(key, "metre", "meter", 1)
+	if strings.HasSuffix(key, "meters") {
+		key = strings.TrimSuffix(key, "s")
+	}
+	if key == "grams" {
+		key = "gram"
+	}
+	conv, ok := conversions[key]
+	return conv, ok
+}
+
+// convertUnits annotates measurements with their value in another system
+//
+// Every measurement written in the other unit system is followed by its
+// converted value in parentheses, e.g. "3 inches" becomes "3 inches
+// (7.6 cm)" when system is "metric". Measurements already in the target
+// system, or already paired with a parenthesized conversion, are left
+// alone. Any other system leaves the content unchanged.
+func convertUnits(content, system string) string {
+	if system != unitsMetric && system != unitsImperial {
+		return content
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range measurementPattern.FindAllStringSubmatchIndex(content, -1) {
+		start, end := m[0], m[1]
+		conv, ok := lookupUnit(content[m[6]:m[7]])
+		if !ok || conv.system == system || alreadyConverted(content, start, end) {
+			continue
+		}
+
+		converted := formatMeasurement(conv.convert(parseNumber(content[m[2]:m[3]])))
+		if m[4] >= 0 {
+			converted += "–" + formatMeasurement(conv.convert(parseNumber(content[m[4]:m[5]])))
+		}
+
+		b.WriteString(content[last:end])
+		fmt.Fprintf(&b, " (%s %s)", converted, conv.target)
+		last = end
+	}
+	b.WriteString(content[last:])
+
+	return b.String()
+}
+
+// alreadyConverted reports whether a measurement already has a
+// conversion next to it, either following it or wrapping it
+func alreadyConverted(content string, start, end int) bool {
+	if start > 0 && content[start-1] == '(' {
+		return true
+	}
+	rest := strings.TrimLeft(content[end:], " ")
+	return len(rest) > 1 && rest[0] == '(' && rest[1] >= '0' && rest[1] <= '9'
+}
+
+// parseNumber parses a number matched by measurementPattern
+func parseNumber(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// formatMeasurement formats a converted value with one decimal place,
+// dropping a trailing ".0"
+func formatMeasurement(v float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(v, 'f', 1, 64), ".0")
+}
@@ -0,0 +1,40 @@
+package gui
+
+import "testing"
+
+func TestConvertUnits(t *testing.T) {
+	tests := []struct {
+		content string
+		system  string
+		want    string
+	}{
+		{"Cap 3 inches wide", unitsMetric, "Cap 3 inches (7.6 cm) wide"},
+		{"Stem 2-4 inches tall", unitsMetric, "Stem 2-4 inches (5.1–10.2 cm) tall"},
+		{"Stem 2 to 4 in tall, 1 inch thick", unitsMetric, "Stem 2 to 4 in tall, 1 inch (2.5 cm) thick"},
+		{"Weighs 2 oz at 50 °F", unitsMetric, "Weighs 2 oz (56.7 g) at 50 °F (10 °C)"},
+		{"Cap 5–10 cm across", unitsImperial, "Cap 5–10 cm (2–3.9 in) across"},
+		{"Up to 2 metres tall", unitsImperial, "Up to 2 metres (6.6 ft) tall"},
+		{"Stem 15 centimeters long", unitsImperial, "Stem 15 centimeters (5.9 in) long"},
+		{"Spores 8 millimetres", unitsImperial, "Spores 8 millimetres (0.3 in)"},
+		{"About 100 grams", unitsImperial, "About 100 grams (3.5 oz)"},
+
+		// Already in the target system
+		{"Cap 5 cm wide", unitsMetric, "Cap 5 cm wide"},
+		{"Cap 3 inches wide", unitsImperial, "Cap 3 inches wide"},
+
+		// Already converted by the model, either way round
+		{"Cap 3 inches (7.6 cm) wide", unitsMetric, "Cap 3 inches (7.6 cm) wide"},
+		{"Cap 7.6 cm (3 inches) wide", unitsMetric, "Cap 7.6 cm (3 inches) wide"},
+
+		// Unknown or no system
+		{"Cap 3 inches wide", "", "Cap 3 inches wide"},
+		{"Cap 3 inches wide", "nautical", "Cap 3 inches wide"},
+
+		{"No measurements here", unitsMetric, "No measurements here"},
+	}
+	for _, tt := range tests {
+		if got := convertUnits(tt.content, tt.system); got != tt.want {
+			t.Errorf("convertUnits(%q, %q) = %q, want %q", tt.content, tt.system, got, tt.want)
+		}
+	}
+}
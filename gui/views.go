@@ -0,0 +1,75 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// imageCount returns the number of images the next request will carry
+func (app *App) imageCount() int {
+	n := len(app.ExtraImages)
+	if app.Base64Image != "" {
+		n++
+	}
+	if app.ImageURL != "" {
+		n++
+	}
+	return n
+}
+
+// onAddViewClicked attaches another photo of the same specimen
+//
+// Extra views (e.g. gills or stem) are sent alongside the main image.
+// Views beyond the configured per-request limit are refused here rather
+// than failing the classification later.
+func (app *App) onAddViewClicked() {
+	limit := app.Config.MaxImagesPerRequest
+	if limit > 0 && app.imageCount() >= limit {
+		app.showError("Cannot add view",
+			fmt.Errorf("at most %d images can be sent per request (MAX_IMAGES_PER_REQUEST)", limit))
+		return
+	}
+
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			app.showError("Failed to open file dialog", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		filename := reader.URI().Path()
+		data, err := base64.ReadImageWithLimit(filename, app.Config.MaxImageBytes)
+		if err != nil {
+			app.showError("Failed to load view", err)
+			return
+		}
+		if err := base64.ValidateImage(data); err != nil {
+			app.showError("Failed to load view", err)
+			return
+		}
+		data, err = app.prepareImage(data)
+		if err != nil {
+			app.showError("Failed to load view", err)
+			return
+		}
+
+		app.ExtraImages = append(app.ExtraImages, openai.ImageInput{
+			Data:     base64.EncodeData(data),
+			MimeType: base64.DetectMimeType(data),
+		})
+		app.StatusLabel.SetText(fmt.Sprintf("Added view: %s (%d images)", filepath.Base(filename), app.imageCount()))
+	}, app.Window)
+
+	fileDialog.SetFilter(storage.NewExtensionFileFilter(imageExtensions))
+	fileDialog.Show()
+}
@@ -0,0 +1,76 @@
+package gui
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// stackDumpSize bounds the goroutine dump logged when the watchdog fires
+const stackDumpSize = 64 * 1024
+
+// watchdog resets the UI if a classification never completes
+//
+// Exactly one of the normal completion path and the watchdog wins: the
+// first to claim the watchdog proceeds, and the other must back off so
+// a late result cannot overwrite the reset UI (or vice versa).
+type watchdog struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	done  bool
+}
+
+// startWatchdog runs onFire if finish is not called within limit
+//
+// A non-positive limit disables the timer; finish then always succeeds.
+func startWatchdog(limit time.Duration, onFire func()) *watchdog {
+	w := &watchdog{}
+	if limit > 0 {
+		w.timer = time.AfterFunc(limit, func() {
+			if w.claim() {
+				onFire()
+			}
+		})
+	}
+	return w
+}
+
+// finish records normal completion and cancels the timer
+//
+// Returns false if the watchdog already fired, in which case the caller
+// should discard its result.
+func (w *watchdog) finish() bool {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	return w.claim()
+}
+
+// claim marks the watchdog as resolved, reporting whether this call won
+func (w *watchdog) claim() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.done {
+		return false
+	}
+	w.done = true
+	return true
+}
+
+// onClassifyStuck returns the UI to idle after the watchdog fires
+func (app *App) onClassifyStuck() {
+	buf := make([]byte, stackDumpSize)
+	n := runtime.Stack(buf, true)
+	log.Printf("Warning: classification did not finish within %s, resetting UI\n%s",
+		app.Config.WatchdogTimeout, buf[:n])
+
+	app.endRequest()
+	app.StreamProgress.Hide()
+	app.StatusLabel.SetText("Analysis stopped responding")
+	app.setResultText("")
+	app.offerRetry(true)
+	app.UploadButton.Enable()
+	app.ClassifyButton.Enable()
+}
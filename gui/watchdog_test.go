@@ -0,0 +1,46 @@
+package gui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchdogFiresAfterLimit(t *testing.T) {
+	fired := make(chan time.Time, 1)
+	start := time.Now()
+	w := startWatchdog(30*time.Millisecond, func() { fired <- time.Now() })
+
+	select {
+	case at := <-fired:
+		if elapsed := at.Sub(start); elapsed < 30*time.Millisecond {
+			t.Errorf("fired after %s, before the limit", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchdog never fired")
+	}
+
+	if w.finish() {
+		t.Error("finish won after the watchdog fired")
+	}
+}
+
+func TestWatchdogFinishInTime(t *testing.T) {
+	fired := make(chan struct{}, 1)
+	w := startWatchdog(50*time.Millisecond, func() { fired <- struct{}{} })
+
+	if !w.finish() {
+		t.Fatal("finish lost before the limit")
+	}
+	select {
+	case <-fired:
+		t.Error("watchdog fired after finish")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	w := startWatchdog(0, func() { t.Error("disabled watchdog fired") })
+	if !w.finish() {
+		t.Error("finish lost without a limit")
+	}
+}
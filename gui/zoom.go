@@ -0,0 +1,70 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Zoom limits of the image view, relative to its fitted size
+const (
+	minZoom = 0.25
+	maxZoom = 4.0
+
+	// zoomStep is the factor applied by each zoom button press
+	zoomStep = 1.25
+)
+
+// imageViewSize is the size of the image view at 100% zoom; the image is
+// scaled to fit inside it
+var imageViewSize = fyne.NewSize(400, 300)
+
+// onZoomInClicked enlarges the image view by one step
+func (app *App) onZoomInClicked() {
+	app.setZoom(app.zoom * zoomStep)
+}
+
+// onZoomOutClicked shrinks the image view by one step
+func (app *App) onZoomOutClicked() {
+	app.setZoom(app.zoom / zoomStep)
+}
+
+// onZoomFitClicked returns the image view to its fitted size
+func (app *App) onZoomFitClicked() {
+	app.setZoom(1)
+}
+
+// setZoom resizes the image view to zoom times its fitted size
+//
+// zoom is clamped to minZoom..maxZoom. The view sits in a scroll
+// container, so an enlarged image can be panned. The displayed copy is
+// large enough (displayMaxDimension) to stay sharp at maxZoom.
+func (app *App) setZoom(zoom float64) {
+	app.zoom = min(max(zoom, minZoom), maxZoom)
+
+	scale := float32(app.zoom)
+	app.ImageView.SetMinSize(fyne.NewSize(imageViewSize.Width*scale, imageViewSize.Height*scale))
+	app.ImageView.Refresh()
+
+	app.ZoomLabel.SetText(fmt.Sprintf("%.0f%%", app.zoom*100))
+	app.updateZoomButtons()
+}
+
+// updateZoomButtons enables the zoom controls that apply to the current
+// image and zoom level
+func (app *App) updateZoomButtons() {
+	loaded := app.SourceImage != nil
+	setEnabled(app.ZoomInButton, loaded && app.zoom < maxZoom)
+	setEnabled(app.ZoomOutButton, loaded && app.zoom > minZoom)
+	setEnabled(app.ZoomFitButton, loaded && app.zoom != 1)
+}
+
+// setEnabled enables or disables button
+func setEnabled(button *widget.Button, enabled bool) {
+	if enabled {
+		button.Enable()
+	} else {
+		button.Disable()
+	}
+}
@@ -0,0 +1,164 @@
+// Package history records classification results across sessions
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is one recorded classification
+type Entry struct {
+	// Path of the classified image (empty if it was pasted)
+	ImagePath string `json:"image_path"`
+
+	// Time the result was received
+	Timestamp time.Time `json:"timestamp"`
+
+	// Model that produced the result
+	Model string `json:"model"`
+
+	// Maximum tokens allowed in the response (0 in older entries)
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// Sampling temperature sent (nil if the API default was used)
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// Nucleus sampling probability mass sent (nil if the API default was
+	// used)
+	TopP *float64 `json:"top_p,omitempty"`
+
+	// Classification text
+	Content string `json:"content"`
+}
+
+// Store is a history file holding one JSON entry per line
+//
+// Entries are appended, never rewritten, so a crash can at worst leave a
+// partial last line; Load skips such lines. Store is safe for concurrent
+// use within a process.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// New returns a store backed by the file at path
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the history file in the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "mushroom-classifier", "history.jsonl"), nil
+}
+
+// Path returns the file backing the store
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Save appends an entry to the history file
+//
+// The file and its directory are created if needed. Each entry is
+// written with a single write call, so entries from concurrent savers
+// never interleave.
+func (s *Store) Save(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+
+	if err := terminatePartialLine(f); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return f.Close()
+}
+
+// terminatePartialLine ends a truncated last line left by a crash, so the
+// next entry starts on a line of its own
+func terminatePartialLine(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil || last[0] == '\n' {
+		return nil
+	}
+
+	if _, err := f.Write([]byte{'\n'}); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Load reads all entries, oldest first
+//
+// A missing file yields no entries. Lines that cannot be parsed, such as
+// a last line cut short by a crash, are skipped with a warning rather
+// than failing the whole history.
+func (s *Store) Load() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []Entry
+	skipped := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if skipped > 0 {
+		log.Printf("Warning: skipped %d unreadable entries in %s", skipped, s.path)
+	}
+	return entries, nil
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	store := New(filepath.Join(t.TempDir(), "history", "history.jsonl"))
+
+	temperature, topP := 0.3, 0.8
+	want := []Entry{
+		{
+			ImagePath:   "/photos/chanterelle.jpg",
+			Timestamp:   time.Date(2024, 9, 14, 10, 30, 0, 0, time.UTC),
+			Model:       "gpt-4o",
+			MaxTokens:   1000,
+			Temperature: &temperature,
+			TopP:        &topP,
+			Content:     "**Species**: Cantharellus cibarius",
+		},
+		{
+			Timestamp: time.Date(2024, 9, 15, 8, 0, 0, 0, time.UTC),
+			Model:     "gpt-4o-mini",
+			Content:   "**Species**: Amanita muscaria",
+		},
+	}
+	for _, entry := range want {
+		if err := store.Save(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSaveAfterTruncatedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := New(path)
+
+	first := Entry{Model: "gpt-4o", Content: "Chanterelle", Timestamp: time.Date(2024, 9, 14, 0, 0, 0, 0, time.UTC)}
+	if err := store.Save(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash cut the second entry short
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"image_path":"/photos/b.jpg","timest`)
+	f.Close()
+
+	third := Entry{Model: "gpt-4o", Content: "Porcini", Timestamp: time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC)}
+	if err := store.Save(third); err != nil {
+		t.Fatal(err)
+	}
+
+	// The partial line is skipped and the next entry is intact
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Content != "Chanterelle" || got[1].Content != "Porcini" {
+		t.Errorf("Load() = %+v, want the first and third entries", got)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	entries, err := New(filepath.Join(t.TempDir(), "none.jsonl")).Load()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Load() = %+v, %v", entries, err)
+	}
+}
@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Certificate pools already loaded, by CA file path
+var caPools sync.Map
+
+// LoadCAFile returns the system roots extended with the certificates in
+// a PEM file
+//
+// Each file is read once per process; later calls return the same pool.
+// Fails if the file holds no certificates, so a wrong file is noticed
+// instead of silently trusting only the system roots.
+func LoadCAFile(path string) (*x509.CertPool, error) {
+	if pool, ok := caPools.Load(path); ok {
+		return pool.(*x509.CertPool), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// Not every platform exposes its roots; trust the file alone
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA file %s", path)
+	}
+
+	actual, _ := caPools.LoadOrStore(path, pool)
+	return actual.(*x509.CertPool), nil
+}
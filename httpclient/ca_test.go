@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCAFile writes the certificate of a TLS test server as a PEM file
+func writeCAFile(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTLSServer starts an HTTPS server answering {"ok":true}, with a
+// certificate of its own that only a CA file makes trusted
+func newTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoadCAFile(t *testing.T) {
+	path := writeCAFile(t, newTLSServer(t))
+	pool, err := LoadCAFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool == nil {
+		t.Fatal("got a nil pool")
+	}
+	// The file is read once; later calls share the pool
+	if again, err := LoadCAFile(path); err != nil || again != pool {
+		t.Errorf("second load = %p, %v; want the cached pool %p", again, err, pool)
+	}
+}
+
+func TestLoadCAFileInvalid(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "key.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, path := range map[string]string{
+		"not PEM": notPEM,
+		"missing": filepath.Join(dir, "missing.pem"),
+	} {
+		if _, err := LoadCAFile(path); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestPostJSONCAFile(t *testing.T) {
+	server := newTLSServer(t)
+
+	// The test server's certificate is not trusted by the system roots
+	if _, err := PostJSON(&Request{URL: server.URL, JSONBody: "{}"}); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("err = %v, want a certificate error without the CA file", err)
+	}
+
+	resp, err := PostJSON(&Request{URL: server.URL, JSONBody: "{}", CAFile: writeCAFile(t, server)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body) != `{"ok":true}` {
+		t.Errorf("got body %q", resp.Body)
+	}
+
+	// A CA file that cannot be used fails the request before it is sent
+	if _, err := PostJSON(&Request{URL: server.URL, JSONBody: "{}", CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("want an error for a missing CA file")
+	}
+}
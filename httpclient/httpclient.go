@@ -3,9 +3,13 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -22,6 +26,54 @@ type Request struct {
 
 	// JSON string to send as request body
 	JSONBody string
+
+	// Additional headers (optional), e.g. for APIs that do not use
+	// bearer authentication; they replace the default headers, including
+	// the User-Agent
+	Headers map[string]string
+
+	// Time limit for the request (DefaultTimeout when zero)
+	Timeout time.Duration
+
+	// Logger for the method, URL, status and duration of the request
+	// (optional); credentials are masked and the body is truncated
+	Logger *log.Logger
+
+	// Proxy URL, e.g. "http://proxy.example.com:3128" (optional); when
+	// empty the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+	// variables apply. SOCKS5 proxies are given as
+	// "socks5://host:port"
+	Proxy string
+
+	// PEM file of root certificates to trust besides the system ones
+	// (optional), e.g. the CA of a proxy that intercepts TLS
+	CAFile string
+
+	// Limiter the request waits on before it is sent (optional); the
+	// wait does not count towards Timeout
+	RateLimiter *RateLimiter
+}
+
+// DefaultTimeout is used for requests that do not set a timeout
+const DefaultTimeout = 30 * time.Second
+
+// Version is reported in the User-Agent header of every request
+//
+// Release builds set it with
+// -ldflags "-X github.com/mushroom-classifier/mushroom-classifier-go/httpclient.Version=1.2.0".
+var Version = "dev"
+
+// UserAgent returns the User-Agent sent unless a request overrides it
+func UserAgent() string {
+	return "mushroom-classifier-go/" + Version
+}
+
+// timeout returns the effective time limit for the request
+func (req *Request) timeout() time.Duration {
+	if req.Timeout > 0 {
+		return req.Timeout
+	}
+	return DefaultTimeout
 }
 
 // Response contains the response data from an HTTP request
@@ -31,50 +83,86 @@ type Response struct {
 
 	// HTTP status code
 	StatusCode int
+
+	// Media type the server declared for the body, e.g. "text/html"
+	ContentType string
 }
 
 // PostJSON performs an HTTP POST request with JSON payload
 //
 // Makes an HTTP POST request to the specified URL with the given JSON body.
-// Automatically sets Content-Type to application/json and includes
-// Bearer authentication if AuthToken is provided.
+// Automatically sets Content-Type to application/json and the
+// User-Agent, and includes Bearer authentication if AuthToken is
+// provided.
 func PostJSON(req *Request) (*Response, error) {
+	return PostJSONContext(context.Background(), req)
+}
+
+// PostJSONContext is like PostJSON but aborts the request when ctx is done
+//
+// A cancelled request returns an error wrapping ctx.Err().
+func PostJSONContext(ctx context.Context, req *Request) (*Response, error) {
+	return doJSON(ctx, http.MethodPost, req)
+}
+
+// GetJSON performs an HTTP GET request expecting a JSON response
+//
+// Behaves like PostJSON, with the same authentication, headers, timeout
+// and rate limit, but sends no body; JSONBody is ignored.
+func GetJSON(req *Request) (*Response, error) {
+	return GetJSONContext(context.Background(), req)
+}
+
+// GetJSONContext is like GetJSON but aborts the request when ctx is done
+func GetJSONContext(ctx context.Context, req *Request) (*Response, error) {
+	return doJSON(ctx, http.MethodGet, req)
+}
+
+// doJSON performs a buffered request with the given method
+func doJSON(ctx context.Context, method string, req *Request) (*Response, error) {
 	// Create HTTP client with timeout
+	transport, err := newTransport(req)
+	if err != nil {
+		return nil, err
+	}
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Transport: transport,
+		Timeout:   req.timeout(),
 	}
 
 	// Create request
-	httpReq, err := http.NewRequest("POST", req.URL, bytes.NewBufferString(req.JSONBody))
+	httpReq, err := newRequest(ctx, method, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	
-	// Add authorization header if token is provided
-	if req.AuthToken != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+req.AuthToken)
+	// Wait for a free slot under the rate limit
+	if err := req.RateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
 
 	// Perform request
+	logRequest(req.Logger, httpReq, requestBody(method, req))
+	start := time.Now()
 	resp, err := client.Do(httpReq)
 	if err != nil {
+		logResponse(req.Logger, httpReq, 0, time.Since(start), err)
 		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
+	logResponse(req.Logger, httpReq, resp.StatusCode, time.Since(start), err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Create response
 	response := &Response{
-		Body:       body,
-		StatusCode: resp.StatusCode,
+		Body:        body,
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
 	}
 
 	// Check for HTTP errors
@@ -83,4 +171,174 @@ func PostJSON(req *Request) (*Response, error) {
 	}
 
 	return response, nil
+}
+
+// StreamResponse contains an unread response from an HTTP request
+type StreamResponse struct {
+	// Response body, to be read incrementally and closed by the caller
+	Body io.ReadCloser
+
+	// HTTP status code
+	StatusCode int
+
+	// Media type the server declared for the body, e.g.
+	// "text/event-stream"
+	ContentType string
+}
+
+// PostJSONStream performs an HTTP POST request and returns the body unread
+//
+// Behaves like PostJSON but does not buffer the response, so callers can
+// process it as it arrives (e.g. server-sent events). The caller must
+// close the body. Only the wait for response headers is time-limited,
+// since a stream may legitimately take longer than a buffered request.
+// On HTTP errors the body is read in full, and the returned response
+// carries the status code with the buffered body, e.g. an API error
+// object or a proxy's error page.
+func PostJSONStream(req *Request) (*StreamResponse, error) {
+	return PostJSONStreamContext(context.Background(), req)
+}
+
+// PostJSONStreamContext is like PostJSONStream but aborts the request,
+// including reading the body, when ctx is done
+func PostJSONStreamContext(ctx context.Context, req *Request) (*StreamResponse, error) {
+	// Limit the wait for headers rather than the whole transfer
+	transport, err := newTransport(req)
+	if err != nil {
+		return nil, err
+	}
+	transport = transport.Clone()
+	transport.ResponseHeaderTimeout = req.timeout()
+	client := &http.Client{
+		Transport: transport,
+	}
+
+	// Create request
+	httpReq, err := newRequest(ctx, http.MethodPost, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for a free slot under the rate limit
+	if err := req.RateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("failed to perform request: %w", err)
+	}
+
+	// Perform request; the logged duration is the wait for headers
+	logRequest(req.Logger, httpReq, req.JSONBody)
+	start := time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		logResponse(req.Logger, httpReq, 0, time.Since(start), err)
+		return nil, fmt.Errorf("failed to perform request: %w", err)
+	}
+	logResponse(req.Logger, httpReq, resp.StatusCode, time.Since(start), nil)
+
+	// Check for HTTP errors
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return &StreamResponse{
+				Body:        io.NopCloser(bytes.NewReader(body)),
+				StatusCode:  resp.StatusCode,
+				ContentType: resp.Header.Get("Content-Type"),
+			},
+			fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
+	}
+
+	return &StreamResponse{
+		Body:        resp.Body,
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// newTransport returns the transport that routes req
+//
+// Requests without an explicit proxy or CA file share
+// http.DefaultTransport, which reuses connections and honors the proxy
+// environment variables. TLS to the API server works the same through
+// HTTP and SOCKS5 proxies.
+func newTransport(req *Request) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport)
+	if req.Proxy == "" && req.CAFile == "" {
+		return transport, nil
+	}
+	transport = transport.Clone()
+
+	if req.Proxy != "" {
+		proxyURL, err := url.Parse(req.Proxy)
+		if err != nil || proxyURL.Host == "" || !supportedProxyScheme(proxyURL.Scheme) {
+			// The URL may hold credentials, so it is not repeated
+			return nil, fmt.Errorf("invalid proxy URL: expected e.g. http://host:port or socks5://host:port")
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if req.CAFile != "" {
+		pool, err := LoadCAFile(req.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig := transport.TLSClientConfig.Clone()
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.RootCAs = pool
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// supportedProxyScheme reports whether proxies of a URL scheme can be used
+//
+// socks5h resolves host names on the proxy; Go does so for socks5 too.
+func supportedProxyScheme(scheme string) bool {
+	switch scheme {
+	case "http", "https", "socks5", "socks5h":
+		return true
+	default:
+		return false
+	}
+}
+
+// requestBody returns the body sent for a request with the given method
+func requestBody(method string, req *Request) string {
+	if method == http.MethodGet {
+		return ""
+	}
+	return req.JSONBody
+}
+
+// newRequest builds a JSON request with authentication headers
+//
+// GET requests have no body and ask for a JSON response instead.
+func newRequest(ctx context.Context, method string, req *Request) (*http.Request, error) {
+	var body io.Reader
+	if method != http.MethodGet {
+		body = bytes.NewBufferString(req.JSONBody)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, req.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	httpReq.Header.Set("User-Agent", UserAgent())
+	
+	// Add authorization header if token is provided
+	if req.AuthToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+req.AuthToken)
+	}
+
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	return httpReq, nil
 }
\ No newline at end of file
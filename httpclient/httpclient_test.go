@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostJSONTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := PostJSON(&Request{URL: server.URL, JSONBody: "{}", Timeout: 50 * time.Millisecond})
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatalf("got status %d, want a timeout", resp.StatusCode)
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("request took %s, want it cut off after the 50ms timeout", elapsed)
+	}
+}
+
+func TestPostJSONWithinTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	resp, err := PostJSON(&Request{URL: server.URL, JSONBody: "{}", Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != `{"ok":true}` || resp.ContentType != "application/json" {
+		t.Errorf("got %+v", resp)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	defer func(version string) { Version = version }(Version)
+	Version = "1.2.0"
+	if UserAgent() != "mushroom-classifier-go/1.2.0" {
+		t.Fatalf("UserAgent() = %q", UserAgent())
+	}
+
+	calls := map[string]func(req *Request) error{
+		"post": func(req *Request) error { _, err := PostJSON(req); return err },
+		"get":  func(req *Request) error { _, err := GetJSON(req); return err },
+		"stream": func(req *Request) error {
+			resp, err := PostJSONStream(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(&Request{URL: server.URL, JSONBody: "{}"}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != "mushroom-classifier-go/1.2.0" {
+			t.Errorf("%s: User-Agent = %q, want the default", name, got)
+		}
+
+		req := &Request{URL: server.URL, JSONBody: "{}", Headers: map[string]string{"User-Agent": "proxy-allowed/1.0"}}
+		if err := call(req); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != "proxy-allowed/1.0" {
+			t.Errorf("%s: User-Agent = %q, want the header override", name, got)
+		}
+	}
+}
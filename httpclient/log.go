@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLoggedBody bounds how much of a request body is logged, so base64
+// images do not flood the log
+const maxLoggedBody = 512
+
+// redacted replaces secrets in logged requests
+const redacted = "REDACTED"
+
+// secretHeaders are never logged in clear text
+var secretHeaders = map[string]bool{
+	"Authorization": true,
+	"X-Api-Key":     true,
+	"Api-Key":       true,
+}
+
+// secretParams are URL query parameters that carry credentials
+var secretParams = []string{"key", "api_key", "api-key", "access_token"}
+
+// logRequest logs an outgoing request when a logger is configured
+func logRequest(logger *log.Logger, httpReq *http.Request, body string) {
+	if logger == nil {
+		return
+	}
+	logger.Printf("HTTP %s %s headers: %s body: %s",
+		httpReq.Method, redactURL(httpReq.URL), formatHeaders(httpReq.Header), truncateBody(body))
+}
+
+// logResponse logs the outcome of a request when a logger is configured
+//
+// A zero statusCode means no response was received and err is logged
+// instead.
+func logResponse(logger *log.Logger, httpReq *http.Request, statusCode int, elapsed time.Duration, err error) {
+	if logger == nil {
+		return
+	}
+	outcome := fmt.Sprintf("status %d", statusCode)
+	if statusCode == 0 {
+		outcome = fmt.Sprintf("failed: %v", err)
+	}
+	logger.Printf("HTTP %s %s %s in %s",
+		httpReq.Method, redactURL(httpReq.URL), outcome, elapsed.Round(time.Millisecond))
+}
+
+// formatHeaders renders headers sorted by name with secrets masked
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if secretHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		parts = append(parts, name+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// redactURL renders u with credentials in user info or query removed
+func redactURL(u *url.URL) string {
+	clean := *u
+	query := clean.Query()
+	changed := false
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		clean.RawQuery = query.Encode()
+	}
+	return clean.Redacted()
+}
+
+// truncateBody shortens a request body to maxLoggedBody bytes
+func truncateBody(body string) string {
+	if len(body) <= maxLoggedBody {
+		return body
+	}
+	return fmt.Sprintf("%s... (%d more bytes)", body[:maxLoggedBody], len(body)-maxLoggedBody)
+}
@@ -0,0 +1,54 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostJSONThroughProxy(t *testing.T) {
+	// A forward proxy receives the absolute URL of the target and
+	// answers in its place
+	var gotURL, gotBody string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"proxied":true}`)
+	}))
+	defer proxy.Close()
+
+	resp, err := PostJSON(&Request{
+		URL:      "http://api.example.invalid/v1/chat/completions",
+		JSONBody: `{"model":"m"}`,
+		Proxy:    proxy.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotURL != "http://api.example.invalid/v1/chat/completions" {
+		t.Errorf("proxy saw URL %q, want the absolute target URL", gotURL)
+	}
+	if gotBody != `{"model":"m"}` {
+		t.Errorf("proxy saw body %q", gotBody)
+	}
+	if string(resp.Body) != `{"proxied":true}` {
+		t.Errorf("got body %q, want the proxy's answer", resp.Body)
+	}
+}
+
+func TestPostJSONInvalidProxy(t *testing.T) {
+	for _, proxy := range []string{"ftp://proxy:21", "proxy:3128", "http://user:secret@"} {
+		_, err := PostJSON(&Request{URL: "http://api.example.invalid/", JSONBody: "{}", Proxy: proxy})
+		if err == nil {
+			t.Errorf("proxy %q: want an error", proxy)
+			continue
+		}
+		if strings.Contains(err.Error(), "secret") {
+			t.Errorf("proxy %q: error %q repeats the credentials", proxy, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces requests to stay under a per-minute quota
+//
+// It is a token bucket holding a single token, refilled perMinute times a
+// minute, so requests are started at most once per interval without
+// bursts that would trip the limit. A nil *RateLimiter never waits. It is
+// safe for concurrent use and meant to be shared by all requests to an
+// API.
+type RateLimiter struct {
+	interval time.Duration
+
+	mu sync.Mutex
+	// Earliest time the next request may start
+	next time.Time
+}
+
+// NewRateLimiter returns a limiter allowing perMinute requests a minute
+//
+// Returns nil, which does not limit, if perMinute is not positive.
+func NewRateLimiter(perMinute int) *RateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+// Wait blocks until a request may start
+//
+// Returns ctx.Err() if ctx is done first; the slot is then given back
+// unless later callers have already queued behind it.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	// Reserve the next free slot
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		if l.next.Equal(slot.Add(l.interval)) {
+			l.next = slot
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,77 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacing(t *testing.T) {
+	// 1200 requests a minute start 50ms apart
+	limiter := NewRateLimiter(1200)
+	start := time.Now()
+	var starts []time.Duration
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		starts = append(starts, time.Since(start))
+	}
+
+	if starts[0] > 20*time.Millisecond {
+		t.Errorf("first request waited %s, want it to start at once", starts[0])
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i] - starts[i-1]; gap < 45*time.Millisecond {
+			t.Errorf("request %d started %s after the previous one, want about 50ms", i, gap)
+		}
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	for _, perMinute := range []int{0, -5} {
+		if limiter := NewRateLimiter(perMinute); limiter != nil {
+			t.Errorf("NewRateLimiter(%d) = %+v, want nil", perMinute, limiter)
+		}
+	}
+
+	var limiter *RateLimiter
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("nil limiter waited %s", elapsed)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	// One request a minute: the second would wait a full minute
+	limiter := NewRateLimiter(1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context's error", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled wait took %s", elapsed)
+	}
+
+	// The abandoned slot is given back rather than pushing later
+	// requests a further minute out
+	limiter.mu.Lock()
+	wait := time.Until(limiter.next)
+	limiter.mu.Unlock()
+	if wait > time.Minute {
+		t.Errorf("next slot is %s away, want at most one interval", wait)
+	}
+}
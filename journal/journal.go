@@ -0,0 +1,138 @@
+// Package journal appends classifications to a Markdown foraging journal
+package journal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one journal record
+type Entry struct {
+	// Time of the find or classification
+	Time time.Time
+
+	// Path of the photo (empty if it was pasted)
+	ImagePath string
+
+	// Identified species (empty if none was identified)
+	Species string
+
+	// Edibility statement from the classification
+	Edibility string
+
+	// Free-form notes, e.g. where the mushroom was found
+	Notes string
+}
+
+// Markdown formats the entry as a journal section
+//
+// The section starts with a level-two heading of the date and species,
+// followed by a bullet list of the image, species and edibility and,
+// if present, the notes as a paragraph. It ends with a blank line so
+// consecutive entries stay separate.
+func (e Entry) Markdown() string {
+	species := e.Species
+	if species == "" {
+		species = "Unidentified"
+	}
+	image := "(pasted image)"
+	if e.ImagePath != "" {
+		image = fmt.Sprintf("[%s](<%s>)", filepath.Base(e.ImagePath), e.ImagePath)
+	}
+	edibility := e.Edibility
+	if edibility == "" {
+		edibility = "Unknown"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s: %s\n\n", e.Time.Format("2006-01-02 15:04"), species)
+	fmt.Fprintf(&b, "- **Image:** %s\n", image)
+	fmt.Fprintf(&b, "- **Species:** %s\n", species)
+	fmt.Fprintf(&b, "- **Edibility:** %s\n", edibility)
+	if notes := strings.TrimSpace(e.Notes); notes != "" {
+		fmt.Fprintf(&b, "\n%s\n", notes)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Journal is a Markdown file entries are appended to
+//
+// Journal is safe for concurrent use within a process.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+}
+
+// New returns a journal backed by the file at path
+func New(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Path returns the file backing the journal
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append adds an entry to the end of the journal
+//
+// The file and its directory are created if absent. Existing content is
+// never rewritten; if it does not end with a blank line, one is added
+// first so the new heading starts a section of its own.
+func (j *Journal) Append(entry Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	text, err := separator(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	text += entry.Markdown()
+
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return f.Close()
+}
+
+// separator returns the newlines needed before a new entry so that it
+// follows the existing content after a blank line
+func separator(f *os.File) (string, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read journal: %w", err)
+	}
+	if info.Size() == 0 {
+		return "", nil
+	}
+
+	n := min(info.Size(), 2)
+	tail := make([]byte, n)
+	if _, err := f.ReadAt(tail, info.Size()-n); err != nil {
+		return "", fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	switch {
+	case string(tail) == "\n\n":
+		return "", nil
+	case tail[n-1] == '\n':
+		return "\n", nil
+	default:
+		return "\n\n", nil
+	}
+}
@@ -0,0 +1,62 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "journal.md")
+	j := New(path)
+
+	first := Entry{
+		Time:      time.Date(2026, 9, 14, 10, 30, 0, 0, time.UTC),
+		ImagePath: "/photos/IMG 0042.jpg",
+		Species:   "Chanterelle",
+		Edibility: "Edible",
+		Notes:     "  Under beech trees, after rain.  ",
+	}
+	second := Entry{Time: time.Date(2026, 9, 15, 16, 5, 0, 0, time.UTC)}
+	for _, entry := range []Entry{first, second} {
+		if err := j.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## 2026-09-14 10:30: Chanterelle\n\n" +
+		"- **Image:** [IMG 0042.jpg](</photos/IMG 0042.jpg>)\n" +
+		"- **Species:** Chanterelle\n" +
+		"- **Edibility:** Edible\n" +
+		"\nUnder beech trees, after rain.\n\n" +
+		"## 2026-09-15 16:05: Unidentified\n\n" +
+		"- **Image:** (pasted image)\n" +
+		"- **Species:** Unidentified\n" +
+		"- **Edibility:** Unknown\n\n"
+	if string(data) != want {
+		t.Errorf("journal holds:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestAppendSeparatesExistingContent(t *testing.T) {
+	for _, existing := range []string{"# Foraging 2026", "# Foraging 2026\n", "# Foraging 2026\n\n"} {
+		path := filepath.Join(t.TempDir(), "journal.md")
+		if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := New(path).Append(Entry{Species: "Morel"}); err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(data), "# Foraging 2026\n\n## ") {
+			t.Errorf("after %q the journal starts:\n%s", existing, data)
+		}
+	}
+}
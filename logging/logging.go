@@ -0,0 +1,72 @@
+// Package logging configures where application logs are written
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// Destination identifies a log output
+type Destination string
+
+const (
+	// Stderr writes logs to standard error (the default, for development)
+	Stderr Destination = "stderr"
+
+	// File appends logs to a file
+	File Destination = "file"
+
+	// Syslog sends logs to the system logger
+	Syslog Destination = "syslog"
+)
+
+// DefaultFile is the log file used when none is configured
+const DefaultFile = "mushroom-classifier.log"
+
+// NewWriter returns the writer for the given log destination
+//
+// An empty destination selects stderr. For the file destination the file
+// is created if needed and opened for appending; an empty filename uses
+// DefaultFile. If syslog is unavailable on this system, a warning is
+// printed and stderr is returned instead.
+func NewWriter(dest Destination, filename string) (io.Writer, error) {
+	switch Destination(strings.ToLower(string(dest))) {
+	case "", Stderr:
+		return os.Stderr, nil
+
+	case File:
+		if filename == "" {
+			filename = DefaultFile
+		}
+		f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file %s: %w", filename, err)
+		}
+		return f, nil
+
+	case Syslog:
+		w, err := openSyslog()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: syslog unavailable (%v), logging to stderr\n", err)
+			return os.Stderr, nil
+		}
+		return w, nil
+
+	default:
+		return nil, fmt.Errorf("unknown log destination %q (expected stderr, file or syslog)", dest)
+	}
+}
+
+// Configure directs the standard logger to the given destination
+func Configure(dest Destination, filename string) error {
+	w, err := NewWriter(dest, filename)
+	if err != nil {
+		return err
+	}
+
+	log.SetOutput(w)
+	return nil
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewWriterStderr(t *testing.T) {
+	for _, dest := range []Destination{"", Stderr, "STDERR"} {
+		w, err := NewWriter(dest, "ignored.log")
+		if err != nil || w != os.Stderr {
+			t.Errorf("NewWriter(%q) = %v, %v, want stderr", dest, w, err)
+		}
+	}
+}
+
+func TestNewWriterFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	for _, line := range []string{"first\n", "second\n"} {
+		w, err := NewWriter(File, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		w.(*os.File).Close()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("log file = %q, want both lines", data)
+	}
+}
+
+func TestNewWriterDefaultFile(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	w, err := NewWriter(File, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.(*os.File).Close()
+
+	if _, err := os.Stat(filepath.Join(dir, DefaultFile)); err != nil {
+		t.Errorf("default log file not created: %v", err)
+	}
+}
+
+func TestNewWriterErrors(t *testing.T) {
+	if _, err := NewWriter("papertrail", ""); err == nil || !strings.Contains(err.Error(), "papertrail") {
+		t.Errorf("unknown destination: err = %v", err)
+	}
+	if _, err := NewWriter(File, filepath.Join(t.TempDir(), "missing", "app.log")); err == nil {
+		t.Error("log file in a missing directory accepted")
+	}
+}
+
+func TestNewWriterSyslog(t *testing.T) {
+	// Falls back to stderr where there is no system logger
+	w, err := NewWriter(Syslog, "")
+	if err != nil || w == nil {
+		t.Errorf("NewWriter(syslog) = %v, %v, want a writer", w, err)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	saved := log.Writer()
+	t.Cleanup(func() { log.SetOutput(saved) })
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	if err := Configure(File, path); err != nil {
+		t.Fatal(err)
+	}
+	log.Print("configured")
+	log.Writer().(*os.File).Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "configured") {
+		t.Errorf("log file = %q, %v", data, err)
+	}
+	if err := Configure("nowhere", ""); err == nil {
+		t.Error("unknown destination accepted")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package logging
+
+import (
+	"errors"
+	"io"
+)
+
+// openSyslog reports that syslog is not supported on this platform
+func openSyslog() (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
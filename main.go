@@ -2,19 +2,94 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
+	"github.com/mushroom-classifier/mushroom-classifier-go/cache"
+	"github.com/mushroom-classifier/mushroom-classifier-go/cli"
 	"github.com/mushroom-classifier/mushroom-classifier-go/config"
 	"github.com/mushroom-classifier/mushroom-classifier-go/gui"
+	"github.com/mushroom-classifier/mushroom-classifier-go/logging"
 )
 
 func main() {
-	// Load configuration from .env file
-	cfg, err := config.Load()
+	// Parse command line; image arguments select command line mode
+	ascii := flag.Bool("ascii", false, "print only ASCII characters (command line mode)")
+	stdin := flag.Bool("stdin", false, "classify an image read from standard input")
+	dryRun := flag.Bool("dry-run", false, "print the API request for each image instead of sending it (command line mode)")
+	multi := flag.Bool("multi", false, "ask for species, edibility and habitat in one request and print each answer (command line mode)")
+	folder := flag.String("folder", "", "classify every image in this directory and write a report")
+	report := flag.String("report", "report.json", "report file for --folder; a .csv extension writes CSV")
+	configFile := flag.String("config", "", "JSON config file (default config.json in the user's config directory)")
+	clearCache := flag.Bool("clear-cache", false, "remove all results from RESULT_CACHE_FILE and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [--config file] [--ascii] [--dry-run] [--multi] [image ...]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s [--config file] [--ascii] [--dry-run] [--multi] --stdin < image\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s [--config file] [--ascii] --folder dir [--report file]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s [--config file] --clear-cache\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Without images the GUI is started; otherwise each image is classified and the results are printed.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Load configuration from the environment, .env and config file
+	cfg, err := config.LoadFile(*configFile)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Direct logs to the configured destination
+	if err := logging.Configure(logging.Destination(cfg.LogDest), cfg.LogFile); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+
+	// Forget cached results, e.g. after improving the prompt
+	if *clearCache {
+		results, err := cache.Open(cfg.ResultCacheFile, max(cfg.ResultCacheSize, 1))
+		if err == nil {
+			err = results.Clear()
+		}
+		if err != nil {
+			log.Fatalf("Failed to clear result cache: %v", err)
+		}
+		fmt.Println("Result cache cleared")
+		return
+	}
+
+	// A dry run never falls back to the GUI or sends a folder's images
+	if *dryRun && (*folder != "" || (!*stdin && flag.NArg() == 0)) {
+		log.Fatalf("--dry-run needs images on the command line or --stdin")
+	}
+
+	// Classify a whole folder unattended
+	if *folder != "" {
+		err := cli.RunFolder(cfg, *folder, *report, cli.Options{Out: os.Stdout, ASCII: *ascii})
+		if err != nil {
+			log.Fatalf("Batch classification failed: %v", err)
+		}
+		return
+	}
+
+	// Classify an image piped from another tool
+	if *stdin {
+		err := cli.RunReader(cfg, os.Stdin, cli.Options{Out: os.Stdout, ASCII: *ascii, DryRun: *dryRun, Multi: *multi})
+		if err != nil {
+			log.Fatalf("Classification failed: %v", err)
+		}
+		return
+	}
+
+	// Classify images given on the command line without the GUI
+	if flag.NArg() > 0 {
+		err := cli.Run(cfg, flag.Args(), cli.Options{Out: os.Stdout, ASCII: *ascii, DryRun: *dryRun, Multi: *multi})
+		if err != nil {
+			log.Fatalf("Classification failed: %v", err)
+		}
+		return
+	}
+
 	// Create and setup GUI
 	app, err := gui.NewApp(cfg)
 	if err != nil {
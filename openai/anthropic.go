@@ -0,0 +1,270 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/httpclient"
+)
+
+// anthropicVersion is the Messages API version requests are written for
+const anthropicVersion = "2023-06-01"
+
+// DefaultAnthropicModel is used for Anthropic requests without a model
+const DefaultAnthropicModel = "claude-3-5-sonnet-latest"
+
+// anthropicRequest represents the JSON structure of a Messages API request
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	TopP        *float64           `json:"top_p,omitempty"`
+}
+
+// anthropicMessage is one message of a Messages API conversation
+type anthropicMessage struct {
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+// anthropicContent is a text or image block of a message
+type anthropicContent struct {
+	Type   string                `json:"type"`
+	Text   string                `json:"text,omitempty"`
+	Source *anthropicImageSource `json:"source,omitempty"`
+}
+
+// anthropicImageSource holds the data of an image block
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// anthropicResponse represents the JSON structure of a Messages API response
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+	StopReason string `json:"stop_reason"`
+}
+
+// AnalyzeImageAnthropic is like AnalyzeImage but uses Anthropic's
+// Messages API
+//
+// req.APIURL must point at the Messages endpoint and req.APIKey hold an
+// Anthropic key. Images are sent as base64 image blocks. RawBase64Image,
+// ImageDetail, JSONResponse and ContentPath only apply to OpenAI and are
+// ignored.
+func AnalyzeImageAnthropic(req *Request) (*Response, error) {
+	return AnalyzeImageAnthropicContext(context.Background(), req)
+}
+
+// AnalyzeImageAnthropicContext is like AnalyzeImageAnthropic but can be
+// cancelled
+func AnalyzeImageAnthropicContext(ctx context.Context, req *Request) (*Response, error) {
+	// Work on a copy, so the caller's request keeps its settings
+	r := *req
+
+	// The OpenAI default model would be rejected
+	if r.Model == "" {
+		r.Model = DefaultAnthropicModel
+	}
+
+	// Images are converted from data: URLs
+	r.RawBase64Image = false
+
+	resp := analyze(&r, func(messages []message) *Response {
+		return sendAnthropic(ctx, &r, messages)
+	})
+	return contextResult(ctx, resp)
+}
+
+// sendAnthropic performs one Messages API call and parses the result
+func sendAnthropic(ctx context.Context, req *Request, messages []message) *Response {
+	system, converted, err := toAnthropic(messages)
+	if err != nil {
+		return errorResponse(CategoryImage, fmt.Sprintf("Failed to build request: %v", err))
+	}
+
+	apiReq := anthropicRequest{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		System:      system,
+		Messages:    converted,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+	}
+
+	jsonBody, err := encodeJSON(apiReq, elideAnthropicImages, req.Debug)
+	if err != nil {
+		return errorResponse(CategoryOther, fmt.Sprintf("Failed to marshal request: %v", err))
+	}
+
+	httpReq := &httpclient.Request{
+		URL:         req.APIURL,
+		JSONBody:    string(jsonBody),
+		Timeout:     req.Timeout,
+		Logger:      req.logger(),
+		Proxy:       req.Proxy,
+		CAFile:      req.CAFile,
+		RateLimiter: req.RateLimiter,
+		Headers: map[string]string{
+			"x-api-key":         req.APIKey,
+			"anthropic-version": anthropicVersion,
+		},
+	}
+
+	httpResp, err := httpclient.PostJSONContext(ctx, httpReq)
+	var apiResp anthropicResponse
+	var status int
+	if httpResp != nil {
+		status = httpResp.StatusCode
+		if !isJSONBody(httpResp.ContentType, httpResp.Body) {
+			return nonJSONResponse(status, httpResp.Body)
+		}
+		// Error statuses carry an error object worth reporting
+		if jsonErr := json.Unmarshal(httpResp.Body, &apiResp); jsonErr != nil && err == nil {
+			return errorResponse(CategoryParse, fmt.Sprintf("Failed to parse response: %v", jsonErr)).withStatus(status)
+		}
+	}
+
+	if apiResp.Error != nil {
+		category := categorizeAPIError(apiResp.Error.Type, "")
+		if category == CategoryOther && status != 0 {
+			category = categorizeStatus(status)
+		}
+		resp := errorResponse(category, fmt.Sprintf("Anthropic API error: %s", apiResp.Error.Message)).withStatus(status)
+		resp.RawJSON = httpResp.Body
+		return resp
+	}
+
+	if err != nil {
+		category := categorizeTransportError(err)
+		if status != 0 {
+			category = categorizeStatus(status)
+		}
+		return errorResponse(category, fmt.Sprintf("HTTP request failed: %v", err)).withStatus(status)
+	}
+
+	var text strings.Builder
+	for _, block := range apiResp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return errorResponse(CategoryParse, "No response from Anthropic API").withStatus(status)
+	}
+
+	resp := &Response{
+		Success:    true,
+		Content:    text.String(),
+		StatusCode: status,
+		Choices:    []string{text.String()},
+		RawJSON:    httpResp.Body,
+		// Anthropic's equivalent of OpenAI's "length" finish reason
+		Truncated: apiResp.StopReason == "max_tokens",
+	}
+	if apiResp.Usage != nil {
+		resp.addUsage(&usage{
+			PromptTokens:     apiResp.Usage.InputTokens,
+			CompletionTokens: apiResp.Usage.OutputTokens,
+			TotalTokens:      apiResp.Usage.InputTokens + apiResp.Usage.OutputTokens,
+		})
+	}
+	return resp
+}
+
+// toAnthropic converts chat messages to the Messages API shape
+//
+// System messages are joined into the separate system field, and images
+// are moved ahead of the text of their message, where Anthropic
+// recommends them.
+func toAnthropic(messages []message) (string, []anthropicMessage, error) {
+	var system []string
+	converted := make([]anthropicMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			for _, part := range msg.Content {
+				system = append(system, part.Text)
+			}
+			continue
+		}
+
+		var images, texts []anthropicContent
+		for _, part := range msg.Content {
+			if part.ImageURL == nil {
+				texts = append(texts, anthropicContent{Type: "text", Text: part.Text})
+				continue
+			}
+
+			source, err := anthropicImage(part.ImageURL.URL)
+			if err != nil {
+				return "", nil, err
+			}
+			images = append(images, anthropicContent{Type: "image", Source: source})
+		}
+
+		converted = append(converted, anthropicMessage{
+			Role:    msg.Role,
+			Content: append(images, texts...),
+		})
+	}
+
+	return strings.Join(system, "\n\n"), converted, nil
+}
+
+// anthropicImage converts a base64 data: URL or an http(s) URL to an
+// image source
+func anthropicImage(url string) (*anthropicImageSource, error) {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return &anthropicImageSource{Type: "url", URL: url}, nil
+	}
+
+	header, data, ok := strings.Cut(url, ",")
+	if !ok || !strings.HasPrefix(header, "data:") || !strings.HasSuffix(header, ";base64") {
+		return nil, fmt.Errorf("image is not a base64 data URL")
+	}
+
+	return &anthropicImageSource{
+		Type:      "base64",
+		MediaType: strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64"),
+		Data:      data,
+	}, nil
+}
+
+// elideAnthropicImages returns a copy of the request with image data
+// replaced by its length, for logging
+func elideAnthropicImages(apiReq anthropicRequest) anthropicRequest {
+	messages := make([]anthropicMessage, len(apiReq.Messages))
+	for i, msg := range apiReq.Messages {
+		parts := make([]anthropicContent, len(msg.Content))
+		for j, part := range msg.Content {
+			if part.Source != nil && part.Source.Data != "" {
+				source := *part.Source
+				source.Data = fmt.Sprintf("<%d bytes>", len(source.Data))
+				part.Source = &source
+			}
+			parts[j] = part
+		}
+		msg.Content = parts
+		messages[i] = msg
+	}
+	apiReq.Messages = messages
+	return apiReq
+}
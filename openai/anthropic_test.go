@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnalyzeImageAnthropicRequestShape(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Chanterelle"}],"stop_reason":"end_turn",`+
+			`"usage":{"input_tokens":10,"output_tokens":3}}`)
+	}))
+	defer server.Close()
+
+	req := &Request{
+		APIKey:       "sk-ant-test",
+		APIURL:       server.URL,
+		Prompt:       "Identify this mushroom",
+		SystemPrompt: "You are a careful mycologist.",
+		Base64Image:  "iVBORw0KGgo=",
+		MimeType:     "image/png",
+		MaxTokens:    500,
+	}
+	resp, err := AnalyzeImageAnthropic(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || resp.Content != "Chanterelle" || resp.TotalTokens != 13 {
+		t.Fatalf("got %+v", resp)
+	}
+	if req.Model != "" {
+		t.Errorf("caller's Model changed to %q", req.Model)
+	}
+
+	if header.Get("x-api-key") != "sk-ant-test" || header.Get("anthropic-version") != anthropicVersion {
+		t.Errorf("headers = %v", header)
+	}
+
+	var sent anthropicRequest
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Model != DefaultAnthropicModel || sent.MaxTokens != 500 || sent.System != "You are a careful mycologist." {
+		t.Errorf("model %q, max_tokens %d, system %q", sent.Model, sent.MaxTokens, sent.System)
+	}
+	if len(sent.Messages) != 1 || sent.Messages[0].Role != "user" {
+		t.Fatalf("messages = %+v", sent.Messages)
+	}
+
+	// The image block comes before the text
+	blocks := sent.Messages[0].Content
+	if len(blocks) != 2 || blocks[0].Type != "image" || blocks[1].Type != "text" {
+		t.Fatalf("content = %+v", blocks)
+	}
+	source := blocks[0].Source
+	if source == nil || source.Type != "base64" || source.MediaType != "image/png" || source.Data != "iVBORw0KGgo=" {
+		t.Errorf("image source = %+v", source)
+	}
+	if blocks[1].Text != "Identify this mushroom" {
+		t.Errorf("text = %q", blocks[1].Text)
+	}
+}
@@ -0,0 +1,86 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// encodeBody marshals a chat request for sending
+//
+// See encodeJSON.
+func encodeBody(chatReq chatCompletionRequest, debug bool) ([]byte, error) {
+	return encodeJSON(chatReq, elideImages, debug)
+}
+
+// encodeJSON marshals an API request for sending
+//
+// The wire form is compact JSON without HTML escaping, which would
+// otherwise expand characters such as '<' and '&' in prompts to six
+// bytes each. With debug set, an indented copy with image data elided by
+// elide is also logged, unescaped as well.
+func encodeJSON[T any](apiReq T, elide func(T) T, debug bool) ([]byte, error) {
+	wire, err := marshalUnescaped(apiReq, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if debug {
+		pretty, err := marshalUnescaped(elide(apiReq), "  ")
+		if err == nil {
+			log.Printf("Request body (%d bytes on the wire):\n%s", len(wire), pretty)
+		}
+	}
+
+	return wire, nil
+}
+
+// marshalUnescaped marshals v without HTML escaping, indented by indent
+// unless it is empty
+func marshalUnescaped(v any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// elideImages returns a copy of the request with image data replaced by
+// a short placeholder, for logging
+func elideImages(chatReq chatCompletionRequest) chatCompletionRequest {
+	messages := make([]message, len(chatReq.Messages))
+	for i, msg := range chatReq.Messages {
+		parts := make([]content, len(msg.Content))
+		for j, part := range msg.Content {
+			if part.ImageURL != nil {
+				part.ImageURL = &imageURL{
+					URL:    elideImageURL(part.ImageURL.URL),
+					Detail: part.ImageURL.Detail,
+				}
+			}
+			parts[j] = part
+		}
+		msg.Content = parts
+		messages[i] = msg
+	}
+	chatReq.Messages = messages
+	return chatReq
+}
+
+// elideImageURL shortens a data: URL or bare base64 string to its
+// header and length; http(s) URLs are kept
+func elideImageURL(url string) string {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		// Links are short and worth seeing
+		return url
+	}
+	if header, data, ok := strings.Cut(url, ","); ok && strings.HasPrefix(url, "data:") {
+		return fmt.Sprintf("%s,<%d bytes>", header, len(data))
+	}
+	return fmt.Sprintf("<%d bytes>", len(url))
+}
@@ -0,0 +1,57 @@
+package openai
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestEncodeBodyCompact(t *testing.T) {
+	req := testRequest("http://example.com")
+	req.Prompt = "Is it <Amanita> & \"edible\"?\nAnswer briefly."
+	req.Base64Image = strings.Repeat("A", 64)
+	req.MimeType = "image/png"
+	messages, failed := buildMessages(req)
+	if failed != nil {
+		t.Fatal(failed.ErrorMessage)
+	}
+
+	body, err := encodeBody(newChatRequest(req, messages), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.ContainsAny(body, "\n\t") || bytes.Contains(body, []byte(": ")) {
+		t.Errorf("body is not compact: %s", body)
+	}
+	if !bytes.Contains(body, []byte(`Is it <Amanita> & \"edible\"?\nAnswer briefly.`)) {
+		t.Errorf("prompt not sent verbatim: %s", body)
+	}
+	if bytes.Contains(body, []byte(`\u003c`)) || bytes.Contains(body, []byte(`\u0026`)) {
+		t.Errorf("HTML characters escaped: %s", body)
+	}
+}
+
+func TestEncodeBodyDebugElidesImages(t *testing.T) {
+	var logged bytes.Buffer
+	saved := log.Writer()
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(saved) })
+
+	req := testRequest("http://example.com")
+	req.Base64Image = strings.Repeat("A", 4096)
+	req.MimeType = "image/png"
+	messages, _ := buildMessages(req)
+
+	body, err := encodeBody(newChatRequest(req, messages), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(body, []byte(req.Base64Image)) {
+		t.Error("image data missing from the wire body")
+	}
+	if strings.Contains(logged.String(), req.Base64Image) || !strings.Contains(logged.String(), "data:image/png;base64,<4096 bytes>") {
+		t.Errorf("logged body does not elide the image:\n%s", logged.String())
+	}
+}
@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/cache"
+)
+
+// cacheKey identifies the answer to a request in the result cache
+//
+// The key is a SHA-256 hash over the API URL, model, sampling settings
+// and messages, so the image data, prompt and conversation all count:
+// changing any of them misses the cache. Returns "" if the request
+// cannot be encoded.
+func cacheKey(req *Request, messages []message) string {
+	if req.Cache == nil {
+		return ""
+	}
+
+	hash := sha256.New()
+	err := json.NewEncoder(hash).Encode(struct {
+		APIURL         string          `json:"api_url"`
+		Model          string          `json:"model"`
+		MaxTokens      int             `json:"max_tokens"`
+		Temperature    *float64        `json:"temperature"`
+		TopP           *float64        `json:"top_p"`
+		ResponseFormat *responseFormat `json:"response_format"`
+		Messages       []message       `json:"messages"`
+	}{req.APIURL, req.Model, req.MaxTokens, req.Temperature, req.TopP, req.responseFormat(), messages})
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Fingerprint identifies the contents of a request
+//
+// It is a SHA-256 hash over every exported field and the requested
+// response format, which includes the schema set by AnalyzeImageSchema,
+// so two requests have the same fingerprint only if they would be sent
+// alike.
+func (req *Request) Fingerprint() ([sha256.Size]byte, error) {
+	var key [sha256.Size]byte
+	hash := sha256.New()
+	err := json.NewEncoder(hash).Encode(struct {
+		Request        *Request        `json:"request"`
+		ResponseFormat *responseFormat `json:"response_format"`
+	}{req, req.responseFormat()})
+	if err != nil {
+		return key, err
+	}
+	copy(key[:], hash.Sum(nil))
+	return key, nil
+}
+
+// cachedResponse returns the cached answer stored under key, or nil
+func cachedResponse(c *cache.Cache, key string) *Response {
+	if key == "" {
+		return nil
+	}
+	data, ok := c.Get(key)
+	if !ok {
+		return nil
+	}
+
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		log.Printf("Warning: ignoring unreadable cached result: %v", err)
+		return nil
+	}
+	resp.Cached = true
+	resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens = 0, 0, 0
+	return &resp
+}
+
+// storeResponse adds a successful answer to the cache
+//
+// Failing to save the cache only loses the saving, so it is logged
+// rather than failing the request.
+func storeResponse(c *cache.Cache, key string, resp *Response) {
+	if key == "" || !resp.Success {
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err == nil {
+		err = c.Put(key, data)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to cache result: %v", err)
+	}
+}
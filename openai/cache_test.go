@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/cache"
+)
+
+func TestFingerprintIncludesSchema(t *testing.T) {
+	plain := testRequest("http://example.com")
+	same := testRequest("http://example.com")
+	structured := testRequest("http://example.com")
+	structured.schema = mushroomSchema
+
+	a, err := plain.Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := same.Fingerprint()
+	c, _ := structured.Fingerprint()
+
+	if a != b {
+		t.Error("identical requests have different fingerprints")
+	}
+	if a == c {
+		t.Error("a schema request has the fingerprint of a plain one")
+	}
+}
+
+func TestAnalyzeImageCache(t *testing.T) {
+	server, calls := newTestServer(t, reply{http.StatusOK, "application/json",
+		`{"choices":[{"message":{"content":"Chanterelle"},"finish_reason":"stop"}],"usage":{"total_tokens":42}}`})
+	results, err := cache.Open("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analyze := func(prompt string) *Response {
+		t.Helper()
+		req := testRequest(server.URL)
+		req.Prompt = prompt
+		req.Base64Image = "AAAA"
+		req.Cache = results
+		resp, err := AnalyzeImage(req)
+		if err != nil || !resp.Success {
+			t.Fatalf("got %+v, %v", resp, err)
+		}
+		return resp
+	}
+
+	first := analyze("Identify this mushroom")
+	if first.Cached || calls.Load() != 1 {
+		t.Fatalf("first request: cached %v after %d calls", first.Cached, calls.Load())
+	}
+
+	hit := analyze("Identify this mushroom")
+	if !hit.Cached || hit.Content != "Chanterelle" || hit.TotalTokens != 0 {
+		t.Errorf("repeated request: got %+v, want the cached answer without usage", hit)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("cache hit reached the server: %d calls", calls.Load())
+	}
+
+	miss := analyze("Identify this mushroom, briefly")
+	if miss.Cached || calls.Load() != 2 {
+		t.Errorf("changed prompt: cached %v after %d calls, want a new call", miss.Cached, calls.Load())
+	}
+}
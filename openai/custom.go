@@ -0,0 +1,217 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ImagePlaceholder marks where the request's images are inserted into
+// custom messages
+//
+// It may appear inside text, as the whole text of a content part, or as
+// the URL of an image_url part.
+const ImagePlaceholder = "{{image}}"
+
+// customMessage is one element of a user-supplied messages array
+//
+// Content is either a string or an array of content parts, as in the
+// chat completions API.
+type customMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// ValidateMessages checks a raw JSON messages array without sending it
+//
+// Returns an error describing the first problem found, e.g. an unknown
+// role or a content part without text. Image placeholders are accepted
+// whether or not images will be available when the array is sent.
+func ValidateMessages(data []byte) error {
+	stand := []content{{Type: "image_url", ImageURL: &imageURL{URL: ImagePlaceholder}}}
+	_, err := parseMessages(data, stand)
+	return err
+}
+
+// AnalyzeMessagesContext sends a custom messages array as-is
+//
+// Bypasses the standard message assembly: Prompt, SystemPrompt and
+// History are ignored, and the images of the request are only sent
+// where ImagePlaceholder appears in data. The array is validated first;
+// invalid arrays fail with CategoryOther like other invalid requests.
+// MinContentLength is not applied, since the shape of the answer is up
+// to the caller. Cancellation works as with AnalyzeImageContext.
+func AnalyzeMessagesContext(ctx context.Context, req *Request, data []byte) (*Response, error) {
+	resp := analyzeMessages(ctx, req, data)
+	return contextResult(ctx, resp)
+}
+
+// analyzeMessages validates a request with custom messages and sends it
+func analyzeMessages(ctx context.Context, req *Request, data []byte) *Response {
+	if req.APIKey == "" {
+		return errorResponse(CategoryAuth, "API key is required")
+	}
+
+	if req.APIURL == "" {
+		return errorResponse(CategoryOther, "API URL is required")
+	}
+
+	images := req.images()
+	if req.MaxImages > 0 && len(images) > req.MaxImages {
+		return errorResponse(CategoryImage, fmt.Sprintf(
+			"Too many images: %d attached, the limit is %d per request", len(images), req.MaxImages))
+	}
+
+	messages, err := parseMessages(data, imageBlocks(images, req.RawBase64Image, req.ImageDetail))
+	if err != nil {
+		return errorResponse(CategoryOther, fmt.Sprintf("Invalid custom messages: %v", err))
+	}
+
+	if req.Model == "" {
+		req.Model = "gpt-4o"
+	}
+
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = 1000
+	}
+
+	meta := newMeta(req)
+
+	resp := send(ctx, req, messages)
+	resp.Meta = meta
+	return resp
+}
+
+// imageBlocks builds one content block per image
+func imageBlocks(images []ImageInput, raw bool, detail string) []content {
+	blocks := make([]content, 0, len(images))
+	for _, img := range images {
+		blocks = append(blocks, imageContent(img, raw, detail))
+	}
+	return blocks
+}
+
+// errNoImages is returned when a placeholder is used without images
+var errNoImages = errors.New("messages contain " + ImagePlaceholder + " but no image is loaded")
+
+// parseMessages converts a raw JSON messages array into chat messages
+//
+// Every placeholder is replaced by the given image blocks. The array
+// must be non-empty, use only the system, user and assistant roles and
+// contain at least one user message.
+func parseMessages(data []byte, images []content) ([]message, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		return nil, errors.New("expected a JSON array of messages")
+	}
+
+	var raw []customMessage
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("the messages array is empty")
+	}
+
+	messages := make([]message, 0, len(raw))
+	hasUser := false
+	for i, m := range raw {
+		switch m.Role {
+		case "system", "assistant":
+		case "user":
+			hasUser = true
+		case "":
+			return nil, fmt.Errorf("message %d has no role", i+1)
+		default:
+			return nil, fmt.Errorf("message %d has unknown role %q", i+1, m.Role)
+		}
+
+		parts, err := parseContent(m.Content, images)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		messages = append(messages, message{Role: m.Role, Content: parts})
+	}
+
+	if !hasUser {
+		return nil, errors.New("at least one user message is required")
+	}
+
+	return messages, nil
+}
+
+// parseContent converts message content, a string or an array of parts,
+// into content blocks with placeholders replaced by images
+func parseContent(data json.RawMessage, images []content) ([]content, error) {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if strings.TrimSpace(text) == "" {
+			return nil, errors.New("content is empty")
+		}
+		return splitPlaceholders(text, images)
+	}
+
+	var parts []content
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return nil, errors.New("content must be a string or an array of parts")
+	}
+	if len(parts) == 0 {
+		return nil, errors.New("content is empty")
+	}
+
+	var blocks []content
+	for i, part := range parts {
+		switch part.Type {
+		case "text":
+			if part.Text == "" {
+				return nil, fmt.Errorf("text part %d has no text", i+1)
+			}
+			split, err := splitPlaceholders(part.Text, images)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, split...)
+		case "image_url":
+			if part.ImageURL == nil || part.ImageURL.URL == "" {
+				return nil, fmt.Errorf("image part %d has no URL", i+1)
+			}
+			if part.ImageURL.URL != ImagePlaceholder {
+				blocks = append(blocks, part)
+				continue
+			}
+			if len(images) == 0 {
+				return nil, errNoImages
+			}
+			blocks = append(blocks, images...)
+		default:
+			return nil, fmt.Errorf("part %d has unknown type %q", i+1, part.Type)
+		}
+	}
+
+	return blocks, nil
+}
+
+// splitPlaceholders turns text into text blocks with the images inserted
+// wherever ImagePlaceholder appears
+func splitPlaceholders(text string, images []content) ([]content, error) {
+	pieces := strings.Split(text, ImagePlaceholder)
+	if len(pieces) > 1 && len(images) == 0 {
+		return nil, errNoImages
+	}
+
+	var blocks []content
+	for i, piece := range pieces {
+		if i > 0 {
+			blocks = append(blocks, images...)
+		}
+		if strings.TrimSpace(piece) != "" {
+			blocks = append(blocks, content{Type: "text", Text: piece})
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,74 @@
+package openai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateMessages(t *testing.T) {
+	valid := []string{
+		`[{"role": "user", "content": "Identify this mushroom"}]`,
+		`[{"role": "system", "content": "You are a mycologist."},
+		  {"role": "user", "content": [
+		    {"type": "text", "text": "Identify this:"},
+		    {"type": "image_url", "image_url": {"url": "{{image}}"}}
+		  ]}]`,
+		`[{"role": "user", "content": "Compare {{image}} with the field guide"},
+		  {"role": "assistant", "content": "It looks like a chanterelle."},
+		  {"role": "user", "content": "Are you sure?"}]`,
+		`[{"role": "user", "content": [{"type": "image_url", "image_url": {"url": "https://example.com/a.jpg"}}]}]`,
+	}
+	for _, data := range valid {
+		if err := ValidateMessages([]byte(data)); err != nil {
+			t.Errorf("ValidateMessages(%s): %v", data, err)
+		}
+	}
+
+	invalid := []struct {
+		data, wantErr string
+	}{
+		{``, "expected a JSON array"},
+		{`{"role": "user"}`, "expected a JSON array"},
+		{`[{"role": "user", "content": "hi"}`, "invalid JSON"},
+		{`[]`, "array is empty"},
+		{`[{"role": "user", "content": "hi", "name": "x"}]`, "invalid JSON"},
+		{`[{"content": "hi"}]`, "message 1 has no role"},
+		{`[{"role": "tool", "content": "hi"}]`, `unknown role "tool"`},
+		{`[{"role": "system", "content": "Be careful."}]`, "at least one user message"},
+		{`[{"role": "user", "content": "  "}]`, "content is empty"},
+		{`[{"role": "user", "content": []}]`, "content is empty"},
+		{`[{"role": "user", "content": 42}]`, "string or an array of parts"},
+		{`[{"role": "user", "content": [{"type": "text"}]}]`, "text part 1 has no text"},
+		{`[{"role": "user", "content": [{"type": "image_url"}]}]`, "image part 1 has no URL"},
+		{`[{"role": "user", "content": [{"type": "audio"}]}]`, `unknown type "audio"`},
+	}
+	for _, tt := range invalid {
+		err := ValidateMessages([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateMessages(%s) = %v, want %q", tt.data, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseMessagesPlaceholders(t *testing.T) {
+	images := []content{
+		{Type: "image_url", ImageURL: &imageURL{URL: "data:image/jpeg;base64,AAAA"}},
+		{Type: "image_url", ImageURL: &imageURL{URL: "data:image/jpeg;base64,BBBB"}},
+	}
+
+	messages, err := parseMessages([]byte(`[{"role": "user", "content": "Cap: {{image}} Compare them."}]`), images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := messages[0].Content
+	if len(parts) != 4 || parts[0].Text != "Cap: " || parts[1].ImageURL != images[0].ImageURL ||
+		parts[2].ImageURL != images[1].ImageURL || parts[3].Text != " Compare them." {
+		t.Errorf("content = %+v", parts)
+	}
+
+	_, err = parseMessages([]byte(`[{"role": "user", "content": "{{image}}"}]`), nil)
+	if !errors.Is(err, errNoImages) {
+		t.Errorf("placeholder without images: err = %v, want errNoImages", err)
+	}
+}
@@ -0,0 +1,24 @@
+package openai
+
+import "fmt"
+
+// BuildRequestJSON returns the body AnalyzeImage would send for req,
+// without sending it
+//
+// The request is validated and completed with defaults like for
+// AnalyzeImage, and the body is byte for byte what would go over the
+// wire, image data included. Credentials travel in headers, so the API
+// key never appears in it. Re-asks for short answers, retries and
+// streaming are not reflected.
+func BuildRequestJSON(req *Request) ([]byte, error) {
+	messages, failed := buildMessages(req)
+	if failed != nil {
+		return nil, failed.Err()
+	}
+
+	body, err := encodeBody(newChatRequest(req, messages), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return body, nil
+}
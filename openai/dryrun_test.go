@@ -0,0 +1,66 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuildRequestJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("BuildRequestJSON sent a request")
+	}))
+	defer server.Close()
+
+	req := testRequest(server.URL)
+	req.APIKey = "sk-secret-key"
+	req.Model = "gpt-4o"
+	req.MaxTokens = 300
+	req.Base64Image = "AAAA"
+	req.MimeType = "image/png"
+	req.ImageDetail = "high"
+
+	body, err := BuildRequestJSON(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), req.APIKey) {
+		t.Errorf("body contains the API key: %s", body)
+	}
+
+	var sent struct {
+		Model     string    `json:"model"`
+		MaxTokens int       `json:"max_tokens"`
+		Messages  []message `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, body)
+	}
+	if sent.Model != "gpt-4o" || sent.MaxTokens != 300 {
+		t.Errorf("got model %q, max tokens %d", sent.Model, sent.MaxTokens)
+	}
+	if len(sent.Messages) != 1 || sent.Messages[0].Role != "user" {
+		t.Fatalf("messages = %+v, want one user message", sent.Messages)
+	}
+
+	parts := sent.Messages[0].Content
+	if len(parts) != 2 || parts[0].Type != "text" || parts[0].Text != req.Prompt {
+		t.Fatalf("content = %+v, want the prompt and the image", parts)
+	}
+	image := parts[1].ImageURL
+	if parts[1].Type != "image_url" || image == nil ||
+		image.URL != "data:image/png;base64,AAAA" || image.Detail != "high" {
+		t.Errorf("image part = %+v", parts[1])
+	}
+}
+
+func TestBuildRequestJSONInvalid(t *testing.T) {
+	req := testRequest("http://example.invalid")
+	req.APIKey = ""
+
+	if body, err := BuildRequestJSON(req); err == nil {
+		t.Errorf("request without an API key accepted: %s", body)
+	}
+}
@@ -0,0 +1,167 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrorCategory classifies why an analysis request failed
+type ErrorCategory string
+
+const (
+	// CategoryAuth covers missing, invalid or unauthorized API keys
+	CategoryAuth ErrorCategory = "auth"
+
+	// CategoryRateLimit covers rate limiting and exhausted quotas
+	CategoryRateLimit ErrorCategory = "rate-limit"
+
+	// CategoryTimeout covers requests that did not complete in time
+	CategoryTimeout ErrorCategory = "timeout"
+
+	// CategoryImage covers images that could not be read or were rejected
+	CategoryImage ErrorCategory = "image-error"
+
+	// CategoryParse covers responses that could not be interpreted
+	CategoryParse ErrorCategory = "parse-error"
+
+	// CategoryOther covers all remaining failures
+	CategoryOther ErrorCategory = "other"
+)
+
+// Error is a categorized analysis failure
+//
+// Returned by Response.Err so callers can use errors.As to inspect the
+// category of a failed request.
+type Error struct {
+	// Failure category
+	Category ErrorCategory
+
+	// Human-readable error message
+	Message string
+
+	// HTTP status code of the API response, 0 if the server was not reached
+	StatusCode int
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// errorResponse builds a failed Response with the given category
+func errorResponse(category ErrorCategory, message string) *Response {
+	return &Response{
+		Success:      false,
+		ErrorMessage: message,
+		Category:     category,
+	}
+}
+
+// withStatus records the HTTP status code the response was built from
+func (r *Response) withStatus(statusCode int) *Response {
+	r.StatusCode = statusCode
+	return r
+}
+
+// maxSnippetLength bounds how much of an unexpected body is reported
+const maxSnippetLength = 200
+
+// isJSONBody reports whether a response body can hold a JSON object
+//
+// Gateways and proxies in front of the API answer failures with HTML
+// pages; those are recognised by their Content-Type or, when the type is
+// missing or generic, by not starting with '{'.
+func isJSONBody(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return true
+		}
+		if mediaType == "text/html" {
+			return false
+		}
+	}
+	// An empty body is left to the parser, as it is usually truncated
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) == 0 || trimmed[0] == '{'
+}
+
+// nonJSONResponse builds the Response for a body that is not JSON
+//
+// The message names the HTTP status and quotes the start of the body
+// instead of a JSON syntax error such as "invalid character '<'".
+func nonJSONResponse(statusCode int, body []byte) *Response {
+	category := CategoryParse
+	if statusCode >= 400 {
+		category = categorizeStatus(statusCode)
+	}
+	message := fmt.Sprintf("Unexpected non-JSON response from the API (HTTP %d): %s", statusCode, bodySnippet(body))
+	return errorResponse(category, message).withStatus(statusCode)
+}
+
+// bodySnippet returns the start of body on one line for error messages
+func bodySnippet(body []byte) string {
+	snippet := strings.Join(strings.Fields(strings.ToValidUTF8(string(body), "")), " ")
+	if snippet == "" {
+		return "(empty body)"
+	}
+	if utf8.RuneCountInString(snippet) > maxSnippetLength {
+		snippet = string([]rune(snippet)[:maxSnippetLength]) + "..."
+	}
+	return snippet
+}
+
+// categorizeStatus maps an HTTP status code to an error category
+func categorizeStatus(statusCode int) ErrorCategory {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return CategoryAuth
+	case http.StatusTooManyRequests:
+		return CategoryRateLimit
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return CategoryTimeout
+	default:
+		return CategoryOther
+	}
+}
+
+// categorizeTransportError maps a failed HTTP round trip to a category
+func categorizeTransportError(err error) ErrorCategory {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CategoryTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return CategoryTimeout
+	}
+
+	return CategoryOther
+}
+
+// categorizeAPIError maps an error object in the response body to a category
+func categorizeAPIError(errType, code string) ErrorCategory {
+	switch code {
+	case "invalid_api_key":
+		return CategoryAuth
+	case "rate_limit_exceeded", "insufficient_quota":
+		return CategoryRateLimit
+	case "invalid_image", "invalid_image_format", "image_parse_error":
+		return CategoryImage
+	}
+
+	switch errType {
+	case "authentication_error", "permission_error":
+		return CategoryAuth
+	case "rate_limit_error", "insufficient_quota":
+		return CategoryRateLimit
+	}
+
+	return CategoryOther
+}
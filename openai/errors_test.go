@@ -0,0 +1,86 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCategorizeStatus(t *testing.T) {
+	tests := map[int]ErrorCategory{
+		http.StatusUnauthorized:        CategoryAuth,
+		http.StatusForbidden:           CategoryAuth,
+		http.StatusTooManyRequests:     CategoryRateLimit,
+		http.StatusRequestTimeout:      CategoryTimeout,
+		http.StatusGatewayTimeout:      CategoryTimeout,
+		http.StatusBadRequest:          CategoryOther,
+		http.StatusInternalServerError: CategoryOther,
+	}
+	for status, want := range tests {
+		if got := categorizeStatus(status); got != want {
+			t.Errorf("categorizeStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestCategorizeAPIError(t *testing.T) {
+	tests := []struct {
+		errType, code string
+		want          ErrorCategory
+	}{
+		{"invalid_request_error", "invalid_api_key", CategoryAuth},
+		{"requests", "rate_limit_exceeded", CategoryRateLimit},
+		{"insufficient_quota", "insufficient_quota", CategoryRateLimit},
+		{"invalid_request_error", "invalid_image_format", CategoryImage},
+		{"authentication_error", "", CategoryAuth},
+		{"permission_error", "", CategoryAuth},
+		{"rate_limit_error", "", CategoryRateLimit},
+		{"invalid_request_error", "context_length_exceeded", CategoryOther},
+		{"", "", CategoryOther},
+	}
+	for _, tt := range tests {
+		if got := categorizeAPIError(tt.errType, tt.code); got != tt.want {
+			t.Errorf("categorizeAPIError(%q, %q) = %s, want %s", tt.errType, tt.code, got, tt.want)
+		}
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCategorizeTransportError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorCategory
+	}{
+		{fmt.Errorf("post: %w", context.DeadlineExceeded), CategoryTimeout},
+		{fmt.Errorf("read: %w", timeoutError{}), CategoryTimeout},
+		{errors.New("connection refused"), CategoryOther},
+	}
+	for _, tt := range tests {
+		if got := categorizeTransportError(tt.err); got != tt.want {
+			t.Errorf("categorizeTransportError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestResponseErr(t *testing.T) {
+	if err := (&Response{Success: true}).Err(); err != nil {
+		t.Errorf("successful response has error %v", err)
+	}
+
+	err := errorResponse(CategoryRateLimit, "slow down").withStatus(http.StatusTooManyRequests).Err()
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Err() = %T, want *Error", err)
+	}
+	if apiErr.Category != CategoryRateLimit || apiErr.StatusCode != 429 || apiErr.Error() != "slow down" {
+		t.Errorf("got %+v", apiErr)
+	}
+}
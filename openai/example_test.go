@@ -0,0 +1,40 @@
+package openai_test
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+func ExampleAnalyzeImage() {
+	// A stand-in for the chat completions endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"**Species**: Cantharellus cibarius"},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":812,"completion_tokens":9,"total_tokens":821}}`)
+	}))
+	defer server.Close()
+
+	resp, err := openai.AnalyzeImage(&openai.Request{
+		APIKey:   "sk-example",
+		APIURL:   server.URL,
+		Model:    "gpt-4o",
+		Prompt:   "Identify this mushroom",
+		ImageURL: "https://example.com/chanterelle.jpg",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !resp.Success {
+		log.Fatal(resp.ErrorMessage)
+	}
+
+	fmt.Println(resp.Content)
+	fmt.Println(resp.TotalTokens, "tokens")
+	// Output:
+	// **Species**: Cantharellus cibarius
+	// 821 tokens
+}
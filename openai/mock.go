@@ -0,0 +1,29 @@
+package openai
+
+import "context"
+
+// AnalyzeImageMock is like AnalyzeImage but answers with content instead
+// of calling an API
+//
+// The request is validated and defaulted exactly as for a real call and
+// the Response carries Meta as usual, so demos and tests exercise the
+// same code paths without a network connection. No tokens are reported.
+func AnalyzeImageMock(req *Request, content string) (*Response, error) {
+	return AnalyzeImageMockContext(context.Background(), req, content)
+}
+
+// AnalyzeImageMockContext is like AnalyzeImageMock but returns ctx.Err()
+// if ctx is already done
+func AnalyzeImageMockContext(ctx context.Context, req *Request, content string) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp := analyze(req, func([]message) *Response {
+		return &Response{
+			Success: true,
+			Content: content,
+			Choices: []string{content},
+		}
+	})
+	return contextResult(ctx, resp)
+}
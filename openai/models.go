@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/httpclient"
+)
+
+// modelListResponse represents the JSON structure of a models list
+type modelListResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// ListModels returns the IDs of the models available to the API key
+//
+// Queries the models endpoint next to req.APIURL (e.g. /v1/models for
+// /v1/chat/completions) with the key, organization, project, timeout
+// and proxy of req. The IDs are sorted. Failures are returned as *Error
+// like Response.Err does.
+func ListModels(req *Request) ([]string, error) {
+	return ListModelsContext(context.Background(), req)
+}
+
+// ListModelsContext is like ListModels but can be cancelled
+func ListModelsContext(ctx context.Context, req *Request) ([]string, error) {
+	modelsURL, err := modelsURL(req.APIURL)
+	if err != nil {
+		return nil, errorResponse(CategoryOther, err.Error()).Err()
+	}
+
+	httpReq := &httpclient.Request{
+		URL:         modelsURL,
+		AuthToken:   req.APIKey,
+		Headers:     req.headers(),
+		Timeout:     req.Timeout,
+		Logger:      req.logger(),
+		Proxy:       req.Proxy,
+		CAFile:      req.CAFile,
+		RateLimiter: req.RateLimiter,
+	}
+
+	httpResp, err := httpclient.GetJSONContext(ctx, httpReq)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, httpErrorResponse(httpResp, err).Err()
+	}
+	if !isJSONBody(httpResp.ContentType, httpResp.Body) {
+		return nil, nonJSONResponse(httpResp.StatusCode, httpResp.Body).Err()
+	}
+
+	var list modelListResponse
+	if err := json.Unmarshal(httpResp.Body, &list); err != nil {
+		return nil, errorResponse(CategoryParse, fmt.Sprintf("Failed to parse models list: %v", err)).withStatus(httpResp.StatusCode).Err()
+	}
+
+	models := make([]string, 0, len(list.Data))
+	for _, model := range list.Data {
+		if model.ID != "" {
+			models = append(models, model.ID)
+		}
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// modelsURL returns the models endpoint belonging to a chat completions URL
+func modelsURL(apiURL string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid API URL: %q", apiURL)
+	}
+
+	base, ok := strings.CutSuffix(strings.TrimSuffix(u.Path, "/"), "/chat/completions")
+	if !ok {
+		return "", fmt.Errorf("cannot derive the models URL from %q", apiURL)
+	}
+	u.Path = base + "/models"
+	return u.String(), nil
+}
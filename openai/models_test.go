@@ -0,0 +1,81 @@
+package openai
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListModels(t *testing.T) {
+	var method, path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, auth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"object":"list","data":[{"id":"gpt-4o-mini"},{"id":""},{"id":"gpt-4o"},{"id":"dall-e-3"}]}`)
+	}))
+	defer server.Close()
+
+	models, err := ListModels(testRequest(server.URL + "/v1/chat/completions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodGet || path != "/v1/models" || auth != "Bearer test-key" {
+		t.Errorf("got %s %s with Authorization %q, want an authenticated GET /v1/models", method, path, auth)
+	}
+	want := []string{"dall-e-3", "gpt-4o", "gpt-4o-mini"}
+	if len(models) != len(want) {
+		t.Fatalf("models = %q, want %q", models, want)
+	}
+	for i := range want {
+		if models[i] != want[i] {
+			t.Errorf("models = %q, want %q", models, want)
+			break
+		}
+	}
+}
+
+func TestListModelsErrors(t *testing.T) {
+	tests := map[string]struct {
+		reply    reply
+		category ErrorCategory
+	}{
+		"unauthorized": {reply{http.StatusUnauthorized, "application/json",
+			`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`}, CategoryAuth},
+		"html page": {reply{http.StatusOK, "text/html", "<html><body>Sign in</body></html>"}, CategoryParse},
+		"malformed": {reply{http.StatusOK, "application/json", `{"data":[{"id":`}, CategoryParse},
+	}
+	for name, tt := range tests {
+		server, _ := newTestServer(t, tt.reply)
+		_, err := ListModels(testRequest(server.URL + "/v1/chat/completions"))
+		var apiErr *Error
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%s: err = %v, want an *Error", name, err)
+			continue
+		}
+		if apiErr.Category != tt.category {
+			t.Errorf("%s: category %q, want %q", name, apiErr.Category, tt.category)
+		}
+	}
+}
+
+func TestModelsURL(t *testing.T) {
+	tests := []struct {
+		apiURL string
+		want   string
+		ok     bool
+	}{
+		{"https://api.openai.com/v1/chat/completions", "https://api.openai.com/v1/models", true},
+		{"https://api.openai.com/v1/chat/completions/", "https://api.openai.com/v1/models", true},
+		{"http://localhost:11434/chat/completions", "http://localhost:11434/models", true},
+		{"https://api.openai.com/v1/responses", "", false},
+		{"not a url", "", false},
+	}
+	for _, tt := range tests {
+		got, err := modelsURL(tt.apiURL)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("modelsURL(%q) = %q, %v; want %q, ok %v", tt.apiURL, got, err, tt.want, tt.ok)
+		}
+	}
+}
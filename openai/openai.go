@@ -2,9 +2,15 @@
 package openai
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/mushroom-classifier/mushroom-classifier-go/cache"
 	"github.com/mushroom-classifier/mushroom-classifier-go/httpclient"
 )
 
@@ -22,11 +28,127 @@ type Request struct {
 	// Text prompt describing what to analyze
 	Prompt string
 
+	// Instructions sent as a separate system message before everything
+	// else (optional), e.g. the persona the model should adopt
+	SystemPrompt string
+
+	// Earlier turns of the conversation, sent before the prompt (optional)
+	History []Turn
+
 	// Base64 encoded image data (optional)
 	Base64Image string
 
+	// MIME type of the image (defaults to "image/jpeg")
+	MimeType string
+
+	// http(s) URL of an image hosted online (optional), e.g. on
+	// iNaturalist. It is sent as is for the API to download, before any
+	// other images.
+	ImageURL string
+
+	// Additional images sent after Base64Image (optional), e.g. the cap,
+	// gills and stem photographed from different angles
+	Images []ImageInput
+
+	// Maximum number of images per request (0 means no limit). Requests
+	// with more images fail with CategoryImage rather than being trimmed.
+	MaxImages int
+
+	// Resolution the model views images at: "low", "high" or "auto"
+	// (empty leaves the API default)
+	ImageDetail string
+
+	// Lowest JPEG quality (1-100) to retry at when the API rejects the
+	// request as too large (HTTP 413). Each retry sends the inline images
+	// smaller and at a lower quality, down to this floor (0 disables).
+	ShrinkMinQuality int
+
+	// Send images as bare base64 instead of a data: URL, for
+	// OpenAI-compatible providers that expect raw image data
+	RawBase64Image bool
+
 	// Maximum tokens in the response
 	MaxTokens int
+
+	// Sampling temperature; nil leaves the API default. Set to 0 for
+	// the most reproducible answers.
+	Temperature *float64
+
+	// Nucleus sampling probability mass; nil leaves the API default
+	TopP *float64
+
+	// Re-ask once for a full analysis when the answer is shorter than
+	// this many characters (0 disables)
+	MinContentLength int
+
+	// Time limit for each HTTP call (httpclient.DefaultTimeout when zero).
+	// For streamed requests it limits the wait for the first response.
+	Timeout time.Duration
+
+	// Where to find the answer in responses that do not follow the
+	// OpenAI shape, e.g. "output[0].text" (optional). Only consulted when
+	// the standard choices are missing or empty; when streaming, it is
+	// looked up in each chunk.
+	ContentPath string
+
+	// Ask for a JSON object via response_format; the prompt must mention
+	// JSON for the API to accept this
+	JSONResponse bool
+
+	// JSON schema the answer must follow, set by AnalyzeImageSchema;
+	// takes precedence over JSONResponse
+	schema *jsonSchema
+
+	// OpenAI organization and project the request is billed to
+	// (optional), sent as the OpenAI-Organization and OpenAI-Project
+	// headers
+	Organization string
+	Project      string
+
+	// Proxy URL for API calls (optional; the standard proxy environment
+	// variables apply when empty)
+	Proxy string
+
+	// PEM file of extra root certificates to trust (optional)
+	CAFile string
+
+	// Limiter shared by all calls to the API (optional); every call,
+	// including re-asks, waits for a free slot
+	RateLimiter *httpclient.RateLimiter
+
+	// Cache of earlier results (optional); a request for the same image,
+	// prompt and model is answered from it without an API call, and
+	// successful answers are added to it
+	Cache *cache.Cache
+
+	// Log each request body, indented and with image data elided, and
+	// the status and duration of each call (credentials are never logged)
+	Debug bool
+
+	// Retry once when the response body is not valid JSON (e.g. truncated
+	// by a proxy). Structurally valid error responses are never retried.
+	RetryMalformedJSON bool
+}
+
+// Turn is an earlier message in a multi-turn conversation
+type Turn struct {
+	// Message author: "user" or "assistant"
+	Role string
+
+	// Message text
+	Text string
+}
+
+// ImageInput is a single image attached to a request
+type ImageInput struct {
+	// Base64 encoded image data
+	Data string
+
+	// MIME type of the image (defaults to "image/jpeg")
+	MimeType string
+
+	// http(s) URL of the image, sent instead of Data when set
+	URL string
 }
 
 // Response contains the result from OpenAI API call
@@ -39,13 +161,152 @@ type Response struct {
 
 	// Success flag: true for success, false for failure
 	Success bool
+
+	// Failure category (valid if Success=false)
+	Category ErrorCategory
+
+	// Effective parameters used for the request
+	Meta Meta
+
+	// Tokens consumed by the prompt, as reported by the API
+	PromptTokens int
+
+	// Tokens generated in the answer, as reported by the API
+	CompletionTokens int
+
+	// Total tokens billed for the request
+	TotalTokens int
+
+	// HTTP status code of the API response, 0 if the server was not reached
+	StatusCode int
+
+	// True when the answer was cut off at the MaxTokens limit
+	Truncated bool
+
+	// Text of every choice the model returned, in order (valid if
+	// Success=true); Content holds the first
+	Choices []string
+
+	// Response body exactly as received from the API, for inspecting
+	// fields not otherwise exposed (valid if Success=true or the API
+	// returned an error object). For streamed responses it is a JSON
+	// array of the received chunks; nil for mock responses.
+	RawJSON []byte
+
+	// True when the answer was taken from Request.Cache; Meta then
+	// describes the original request and no tokens were used
+	Cached bool
+}
+
+// Meta records the effective parameters that produced a response
+//
+// Reflects the values actually sent after defaults were applied, so a
+// result can be reproduced later.
+type Meta struct {
+	// Model identifier
+	Model string
+
+	// Maximum tokens allowed in the response
+	MaxTokens int
+
+	// Sampling temperature (nil if the API default was used)
+	Temperature *float64
+
+	// Nucleus sampling probability mass (nil if the API default was used)
+	TopP *float64
+
+	// Time the request was sent
+	Timestamp time.Time
+}
+
+// String returns a compact one-line description of the parameters
+//
+// Temperature and top_p are only listed when they were set.
+func (m Meta) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s · max %d tokens", m.Model, m.MaxTokens)
+	if m.Temperature != nil {
+		fmt.Fprintf(&b, " · temperature %g", *m.Temperature)
+	}
+	if m.TopP != nil {
+		fmt.Fprintf(&b, " · top_p %g", *m.TopP)
+	}
+	b.WriteString(" · " + m.Timestamp.Format("2006-01-02 15:04:05"))
+	return b.String()
+}
+
+// newMeta records the effective parameters of req, sent now
+func newMeta(req *Request) Meta {
+	return Meta{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Timestamp:   time.Now(),
+	}
+}
+
+// Err returns the failure as a categorized *Error, or nil on success
+func (r *Response) Err() error {
+	if r.Success {
+		return nil
+	}
+	return &Error{
+		Category:   r.Category,
+		Message:    r.ErrorMessage,
+		StatusCode: r.StatusCode,
+	}
+}
+
+// logger returns the logger for HTTP calls, nil unless debugging
+func (req *Request) logger() *log.Logger {
+	if !req.Debug {
+		return nil
+	}
+	return log.Default()
+}
+
+// addUsage adds the token counts of u to the response
+func (r *Response) addUsage(u *usage) {
+	if u == nil {
+		return
+	}
+	r.PromptTokens += u.PromptTokens
+	r.CompletionTokens += u.CompletionTokens
+	r.TotalTokens += u.TotalTokens
+}
+
+// usage returns the token counts of the response
+func (r *Response) usage() *usage {
+	return &usage{
+		PromptTokens:     r.PromptTokens,
+		CompletionTokens: r.CompletionTokens,
+		TotalTokens:      r.TotalTokens,
+	}
 }
 
 // chatCompletionRequest represents the JSON structure for OpenAI API request
 type chatCompletionRequest struct {
-	Model    string    `json:"model"`
-	Messages []message `json:"messages"`
-	MaxTokens int      `json:"max_tokens"`
+	Model          string          `json:"model"`
+	Messages       []message       `json:"messages"`
+	MaxTokens      int             `json:"max_tokens"`
+	Temperature    *float64        `json:"temperature,omitempty"`
+	TopP           *float64        `json:"top_p,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *streamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// streamOptions asks for extra data in a streamed response
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// usage represents the token accounting block of an OpenAI API response
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // message represents a chat message in the OpenAI API
@@ -63,16 +324,23 @@ type content struct {
 
 // imageURL represents an image URL in the OpenAI API
 type imageURL struct {
-	URL string `json:"url"`
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
 }
 
+// finishReasonLength is the finish reason of an answer that reached the
+// token limit
+const finishReasonLength = "length"
+
 // chatCompletionResponse represents the JSON structure for OpenAI API response
 type chatCompletionResponse struct {
 	Choices []struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage *usage `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -80,31 +348,189 @@ type chatCompletionResponse struct {
 	} `json:"error"`
 }
 
+// headers returns the OpenAI headers to send besides authentication
+//
+// Returns nil when neither an organization nor a project is set.
+func (req *Request) headers() map[string]string {
+	var headers map[string]string
+	set := func(name, value string) {
+		if value == "" {
+			return
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = value
+	}
+	set("OpenAI-Organization", req.Organization)
+	set("OpenAI-Project", req.Project)
+	return headers
+}
+
+// images returns every image attached to the request, in order
+//
+// The single Base64Image field comes first so existing callers keep
+// working unchanged.
+func (req *Request) images() []ImageInput {
+	var images []ImageInput
+	if req.ImageURL != "" {
+		images = append(images, ImageInput{URL: req.ImageURL})
+	}
+	if req.Base64Image != "" {
+		images = append(images, ImageInput{Data: req.Base64Image, MimeType: req.MimeType})
+	}
+	for _, img := range req.Images {
+		if img.Data != "" || img.URL != "" {
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// CheckImageURL reports whether s is an image URL the API can download
+//
+// Only absolute http and https URLs are accepted.
+func CheckImageURL(s string) error {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid image URL %q: expected an http or https URL", s)
+	}
+	return nil
+}
+
+// imageContent builds an image block of the user message
+//
+// By default the image is embedded as a data: URL. When raw is set, the
+// bare base64 string is sent in the same field instead. Images given by
+// URL are sent as that URL. A non-empty detail sets the resolution the
+// model views the image at.
+func imageContent(img ImageInput, raw bool, detail string) content {
+	mimeType := img.MimeType
+	if mimeType == "" {
+		mimeType = "image/jpeg"
+	}
+
+	url := fmt.Sprintf("data:%s;base64,%s", mimeType, img.Data)
+	if img.URL != "" {
+		url = img.URL
+	} else if raw {
+		url = img.Data
+	}
+
+	return content{
+		Type: "image_url",
+		ImageURL: &imageURL{
+			URL:    url,
+			Detail: detail,
+		},
+	}
+}
+
 // AnalyzeImage sends an image along with a text prompt to OpenAI's API for analysis
 //
 // The function handles all API communication, request formatting, and
-// response parsing. If Base64Image is empty, only the text prompt is sent.
+// response parsing. Each image in Base64Image and Images is sent as its
+// own block; if there are none, only the text prompt is sent.
 func AnalyzeImage(req *Request) (*Response, error) {
+	return AnalyzeImageContext(context.Background(), req)
+}
+
+// AnalyzeImageContext is like AnalyzeImage but can be cancelled
+//
+// If ctx is done before the analysis completes, the HTTP call is
+// aborted and ctx.Err() is returned instead of a Response. An answer
+// that arrived in time is returned even if ctx is done by then.
+func AnalyzeImageContext(ctx context.Context, req *Request) (*Response, error) {
+	resp := analyzeShrinking(req, func(messages []message) *Response {
+		return send(ctx, req, messages)
+	})
+	return contextResult(ctx, resp)
+}
+
+// contextResult returns resp, or ctx.Err() if the call failed because
+// ctx is done
+//
+// A successful answer was billed, so it is kept even when the deadline
+// passes or ctx is cancelled right after it arrived.
+func contextResult(ctx context.Context, resp *Response) (*Response, error) {
+	if err := ctx.Err(); err != nil && !resp.Success {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// analyze validates a request, builds its messages and performs it
+//
+// The do function carries out a single chat completion call; it is
+// called again with the extended conversation when the answer is too
+// short and MinContentLength is set.
+func analyze(req *Request, do func(messages []message) *Response) *Response {
+	messages, failed := buildMessages(req)
+	if failed != nil {
+		return failed
+	}
+
+	meta := newMeta(req)
+
+	key := cacheKey(req, messages)
+	if resp := cachedResponse(req.Cache, key); resp != nil {
+		return resp
+	}
+
+	resp := do(messages)
+
+	// Re-ask once when the answer is too short to be a full analysis
+	if resp.Success && req.MinContentLength > 0 &&
+		len(strings.TrimSpace(resp.Content)) < req.MinContentLength {
+		messages = append(messages,
+			textMessage("assistant", resp.Content),
+			textMessage("user", fullAnalysisNudge),
+		)
+		retry := do(messages)
+		if retry.Success {
+			resp, retry = retry, resp
+		}
+		// Both calls are billed, so report their combined usage
+		resp.addUsage(retry.usage())
+	}
+
+	// Content filters and refusals can leave a successful answer blank
+	if resp.Success && strings.TrimSpace(resp.Content) == "" {
+		resp = emptyResponse(resp)
+	}
+
+	resp.Meta = meta
+	storeResponse(req.Cache, key, resp)
+	return resp
+}
+
+// buildMessages validates a request, applies its defaults and builds
+// the messages to send
+//
+// Returns a failed Response instead if the request is invalid.
+func buildMessages(req *Request) ([]message, *Response) {
 	// Validate request
 	if req.APIKey == "" {
-		return &Response{
-			Success:      false,
-			ErrorMessage: "API key is required",
-		}, nil
+		return nil, errorResponse(CategoryAuth, "API key is required")
 	}
 
 	if req.APIURL == "" {
-		return &Response{
-			Success:      false,
-			ErrorMessage: "API URL is required",
-		}, nil
+		return nil, errorResponse(CategoryOther, "API URL is required")
 	}
 
 	if req.Prompt == "" {
-		return &Response{
-			Success:      false,
-			ErrorMessage: "Prompt is required",
-		}, nil
+		return nil, errorResponse(CategoryOther, "Prompt is required")
+	}
+
+	if req.ImageURL != "" {
+		if err := CheckImageURL(req.ImageURL); err != nil {
+			return nil, errorResponse(CategoryImage, err.Error())
+		}
+	}
+
+	if images := req.images(); req.MaxImages > 0 && len(images) > req.MaxImages {
+		return nil, errorResponse(CategoryImage, fmt.Sprintf(
+			"Too many images: %d attached, the limit is %d per request", len(images), req.MaxImages))
 	}
 
 	// Set defaults
@@ -124,79 +550,206 @@ func AnalyzeImage(req *Request) (*Response, error) {
 		},
 	}
 
-	// Add image if provided
-	if req.Base64Image != "" {
-		messageContent = append(messageContent, content{
-			Type: "image_url",
-			ImageURL: &imageURL{
-				URL: fmt.Sprintf("data:image/jpeg;base64,%s", req.Base64Image),
-			},
-		})
+	// Add one block per image, if any
+	for _, img := range req.images() {
+		messageContent = append(messageContent, imageContent(img, req.RawBase64Image, req.ImageDetail))
+	}
+
+	// Replay earlier turns before the new user message
+	messages := make([]message, 0, len(req.History)+2)
+	if req.SystemPrompt != "" {
+		messages = append(messages, textMessage("system", req.SystemPrompt))
+	}
+	for _, turn := range req.History {
+		messages = append(messages, textMessage(turn.Role, turn.Text))
+	}
+	messages = append(messages, message{
+		Role:    "user",
+		Content: messageContent,
+	})
+	return messages, nil
+}
+
+// emptyResponse turns a successful but blank answer into a failure
+//
+// The usage and raw body are kept, as the call was still billed.
+func emptyResponse(resp *Response) *Response {
+	message := "Model returned an empty response"
+	if resp.Truncated {
+		message += " (the max tokens limit was reached before any text)"
 	}
 
-	// Build request
-	chatReq := chatCompletionRequest{
-		Model: req.Model,
-		Messages: []message{
+	empty := errorResponse(CategoryParse, message).withStatus(resp.StatusCode)
+	empty.Truncated = resp.Truncated
+	empty.RawJSON = resp.RawJSON
+	empty.addUsage(resp.usage())
+	return empty
+}
+
+// fullAnalysisNudge is the follow-up sent when an answer is too short
+const fullAnalysisNudge = "That answer is too brief. Please provide the full structured analysis covering every section requested above."
+
+// textMessage builds a chat message containing only text
+func textMessage(role, text string) message {
+	return message{
+		Role: role,
+		Content: []content{
 			{
-				Role:    "user",
-				Content: messageContent,
+				Type: "text",
+				Text: text,
 			},
 		},
-		MaxTokens: req.MaxTokens,
 	}
+}
 
+// send performs one chat completion call and parses the result
+//
+// Handles request marshaling, the HTTP round trip (with the optional
+// retry on malformed bodies) and extraction of the first choice.
+func send(ctx context.Context, req *Request, messages []message) *Response {
 	// Marshal to JSON
-	jsonBody, err := json.Marshal(chatReq)
+	chatReq := newChatRequest(req, messages)
+	jsonBody, err := encodeBody(chatReq, req.Debug)
 	if err != nil {
-		return &Response{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("Failed to marshal request: %v", err),
-		}, nil
+		return errorResponse(CategoryOther, fmt.Sprintf("Failed to marshal request: %v", err))
 	}
+	httpReq := req.httpRequest(jsonBody)
 
-	// Make HTTP request
-	httpReq := &httpclient.Request{
-		URL:       req.APIURL,
-		AuthToken: req.APIKey,
-		JSONBody:  string(jsonBody),
+	// Perform the request, allowing one extra attempt for malformed bodies
+	for attempt := 1; ; attempt++ {
+		httpResp, err := httpclient.PostJSONContext(ctx, httpReq)
+		if err != nil {
+			return httpErrorResponse(httpResp, err)
+		}
+
+		resp, err := parseCompletion(req, httpResp.StatusCode, httpResp.ContentType, httpResp.Body)
+		if err != nil {
+			// A body that fails to parse is usually a truncated transfer
+			if attempt < req.attempts() {
+				continue
+			}
+			return errorResponse(CategoryParse, fmt.Sprintf("Failed to parse response: %v", err)).withStatus(httpResp.StatusCode)
+		}
+		return resp
 	}
+}
 
-	httpResp, err := httpclient.PostJSON(httpReq)
-	if err != nil {
-		return &Response{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("HTTP request failed: %v", err),
-		}, nil
+// httpRequest builds the HTTP request carrying a chat completion body
+func (req *Request) httpRequest(jsonBody []byte) *httpclient.Request {
+	return &httpclient.Request{
+		URL:         req.APIURL,
+		AuthToken:   req.APIKey,
+		JSONBody:    string(jsonBody),
+		Timeout:     req.Timeout,
+		Logger:      req.logger(),
+		Proxy:       req.Proxy,
+		CAFile:      req.CAFile,
+		Headers:     req.headers(),
+		RateLimiter: req.RateLimiter,
 	}
+}
 
-	// Parse response
+// attempts returns how often a call is tried when the body is malformed
+func (req *Request) attempts() int {
+	if req.RetryMalformedJSON {
+		return 2
+	}
+	return 1
+}
+
+// parseCompletion interprets a buffered chat completion body
+//
+// Shared by plain and streamed calls, as servers may answer a stream
+// request with a complete body. Bodies that are not JSON, API error
+// objects and missing answers become failed Responses. Only a body that
+// looks like JSON but fails to parse is returned as an error, so the
+// caller can retry it.
+func parseCompletion(req *Request, status int, contentType string, body []byte) (*Response, error) {
+	if !isJSONBody(contentType, body) {
+		return nonJSONResponse(status, body), nil
+	}
 	var chatResp chatCompletionResponse
-	if err := json.Unmarshal(httpResp.Body, &chatResp); err != nil {
-		return &Response{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("Failed to parse response: %v", err),
-		}, nil
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return nil, err
 	}
 
 	// Check for API error
 	if chatResp.Error != nil {
-		return &Response{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("OpenAI API error: %s", chatResp.Error.Message),
-		}, nil
+		category := categorizeAPIError(chatResp.Error.Type, chatResp.Error.Code)
+		resp := errorResponse(category, fmt.Sprintf("OpenAI API error: %s", chatResp.Error.Message)).withStatus(status)
+		resp.RawJSON = body
+		return resp, nil
+	}
+
+	// Extract content from response, falling back to the custom path
+	var text string
+	var truncated bool
+	if len(chatResp.Choices) > 0 {
+		text = chatResp.Choices[0].Message.Content
+		truncated = chatResp.Choices[0].FinishReason == finishReasonLength
+	}
+	if text == "" && req.ContentPath != "" {
+		var err error
+		text, err = extractPath(body, req.ContentPath)
+		if err != nil {
+			return errorResponse(CategoryParse, fmt.Sprintf("Failed to extract content: %v", err)).withStatus(status), nil
+		}
+	}
+	if len(chatResp.Choices) == 0 && text == "" {
+		return errorResponse(CategoryParse, "No response from OpenAI API").withStatus(status), nil
+	}
+
+	// Keep the other candidates for callers that want them
+	choices := []string{text}
+	for _, choice := range chatResp.Choices[min(1, len(chatResp.Choices)):] {
+		choices = append(choices, choice.Message.Content)
 	}
 
-	// Extract content from response
-	if len(chatResp.Choices) == 0 {
-		return &Response{
-			Success:      false,
-			ErrorMessage: "No response from OpenAI API",
-		}, nil
+	resp := &Response{
+		Success:    true,
+		Content:    text,
+		StatusCode: status,
+		Truncated:  truncated,
+		Choices:    choices,
+		RawJSON:    body,
+	}
+	resp.addUsage(chatResp.Usage)
+	return resp, nil
+}
+
+// newChatRequest builds the chat completion request for messages
+func newChatRequest(req *Request, messages []message) chatCompletionRequest {
+	return chatCompletionRequest{
+		Model:          req.Model,
+		Messages:       messages,
+		MaxTokens:      req.MaxTokens,
+		Temperature:    req.Temperature,
+		TopP:           req.TopP,
+		ResponseFormat: req.responseFormat(),
+	}
+}
+
+// httpErrorResponse builds the Response for a failed HTTP request
+//
+// When the server answered, its status code is recorded and the message
+// of an API error object in the body is preferred over the raw body. A
+// body that is not JSON, e.g. a proxy's HTML error page, is quoted
+// briefly instead.
+func httpErrorResponse(httpResp *httpclient.Response, err error) *Response {
+	if httpResp == nil {
+		return errorResponse(categorizeTransportError(err), fmt.Sprintf("HTTP request failed: %v", err))
 	}
 
-	return &Response{
-		Success: true,
-		Content: chatResp.Choices[0].Message.Content,
-	}, nil
+	if !isJSONBody(httpResp.ContentType, httpResp.Body) {
+		return nonJSONResponse(httpResp.StatusCode, httpResp.Body)
+	}
+
+	var apiResp chatCompletionResponse
+	if json.Unmarshal(httpResp.Body, &apiResp) == nil && apiResp.Error != nil {
+		message := fmt.Sprintf("OpenAI API error (HTTP %d): %s", httpResp.StatusCode, apiResp.Error.Message)
+		resp := errorResponse(categorizeStatus(httpResp.StatusCode), message).withStatus(httpResp.StatusCode)
+		resp.RawJSON = httpResp.Body
+		return resp
+	}
+	return errorResponse(categorizeStatus(httpResp.StatusCode), fmt.Sprintf("HTTP request failed: %v", err)).withStatus(httpResp.StatusCode)
 }
\ No newline at end of file
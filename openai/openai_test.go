@@ -0,0 +1,535 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// reply is a canned HTTP response of a test server
+type reply struct {
+	status      int
+	contentType string
+	body        string
+}
+
+// newTestServer answers each call with the next reply, repeating the
+// last one, and counts the calls
+func newTestServer(t *testing.T, replies ...reply) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		reply := replies[min(n, len(replies))-1]
+		w.Header().Set("Content-Type", reply.contentType)
+		w.WriteHeader(reply.status)
+		fmt.Fprint(w, reply.body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// testRequest returns a minimal valid request to url
+func testRequest(url string) *Request {
+	return &Request{APIKey: "test-key", APIURL: url, Prompt: "Identify this mushroom"}
+}
+
+// badGatewayPage is a proxy error page, long enough to need shortening
+var badGatewayPage = "<html>\n<head><title>502 Bad Gateway</title></head>\n<body>\n<center><h1>502 Bad Gateway</h1></center>\n" +
+	strings.Repeat("<!-- padding -->\n", 100) + "</body>\n</html>"
+
+func TestAnalyzeImageBadGatewayHTML(t *testing.T) {
+	server, _ := newTestServer(t, reply{http.StatusBadGateway, "text/html", badGatewayPage})
+
+	analyzers := map[string]func(*Request) (*Response, error){
+		"plain":    AnalyzeImage,
+		"streamed": func(req *Request) (*Response, error) { return AnalyzeImageStream(req, nil) },
+	}
+	for name, analyze := range analyzers {
+		t.Run(name, func(t *testing.T) {
+			resp, err := analyze(testRequest(server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Success || resp.StatusCode != http.StatusBadGateway {
+				t.Fatalf("got %+v, want a failed 502", resp)
+			}
+			if !strings.Contains(resp.ErrorMessage, "non-JSON response") || !strings.Contains(resp.ErrorMessage, "502 Bad Gateway") {
+				t.Errorf("ErrorMessage = %q", resp.ErrorMessage)
+			}
+			if strings.Contains(resp.ErrorMessage, "</html>") {
+				t.Errorf("ErrorMessage quotes the whole page: %q", resp.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestAnalyzeImageAPIErrorObject(t *testing.T) {
+	server, _ := newTestServer(t, reply{http.StatusTooManyRequests, "application/json",
+		`{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`})
+
+	analyzers := map[string]func(*Request) (*Response, error){
+		"plain":    AnalyzeImage,
+		"streamed": func(req *Request) (*Response, error) { return AnalyzeImageStream(req, nil) },
+	}
+	for name, analyze := range analyzers {
+		t.Run(name, func(t *testing.T) {
+			resp, err := analyze(testRequest(server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Category != CategoryRateLimit || resp.ErrorMessage != "OpenAI API error (HTTP 429): Rate limit reached" {
+				t.Errorf("got category %q, message %q", resp.Category, resp.ErrorMessage)
+			}
+			if resp.RawJSON == nil {
+				t.Error("RawJSON not set")
+			}
+		})
+	}
+}
+
+func TestAnalyzeImageContextKeepsAnswerAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The re-ask for a longer answer is cancelled while in flight
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) > 1 {
+			io.Copy(io.Discard, r.Body)
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Chanterelle"}}]}`)
+	}))
+	defer server.Close()
+
+	req := testRequest(server.URL)
+	req.MinContentLength = 100
+	resp, err := AnalyzeImageContext(ctx, req)
+	if err != nil {
+		t.Fatalf("err = %v, want the first answer", err)
+	}
+	if resp.Content != "Chanterelle" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+}
+
+func TestAnalyzeImageContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	if _, err := AnalyzeImageContext(ctx, testRequest(server.URL)); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestAnalyzeImageMetaSamplingParameters(t *testing.T) {
+	server, _ := newTestServer(t, reply{http.StatusOK, "application/json",
+		`{"choices":[{"message":{"content":"Chanterelle"},"finish_reason":"stop"}]}`})
+
+	temperature, topP := 0.2, 0.9
+	req := testRequest(server.URL)
+	req.Temperature = &temperature
+	req.TopP = &topP
+	resp, err := AnalyzeImage(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := resp.Meta
+	if meta.Temperature == nil || *meta.Temperature != 0.2 || meta.TopP == nil || *meta.TopP != 0.9 {
+		t.Fatalf("Meta = %+v, want temperature 0.2 and top_p 0.9", meta)
+	}
+	if s := meta.String(); !strings.Contains(s, "temperature 0.2") || !strings.Contains(s, "top_p 0.9") {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestAnalyzeImageRetryMalformedJSON(t *testing.T) {
+	truncated := reply{http.StatusOK, "application/json", `{"choices":[{"message":{"content":"Chante`}
+	valid := reply{http.StatusOK, "application/json", `{"choices":[{"message":{"content":"Chanterelle"},"finish_reason":"stop"}]}`}
+
+	server, calls := newTestServer(t, truncated, valid)
+	req := testRequest(server.URL)
+	req.RetryMalformedJSON = true
+	resp, err := AnalyzeImage(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || resp.Content != "Chanterelle" || calls.Load() != 2 {
+		t.Errorf("got %+v after %d calls, want the valid answer after 2", resp, calls.Load())
+	}
+
+	// Without the retry the truncated body is a parse failure
+	server, calls = newTestServer(t, truncated, valid)
+	resp, err = AnalyzeImage(testRequest(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || resp.Category != CategoryParse || calls.Load() != 1 {
+		t.Errorf("got %+v after %d calls, want a parse failure after 1", resp, calls.Load())
+	}
+}
+
+func TestAnalyzeImageUsage(t *testing.T) {
+	server, _ := newTestServer(t, reply{http.StatusOK, "application/json",
+		`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o",` +
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"Chanterelle"},"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":1123,"completion_tokens":87,"total_tokens":1210}}`})
+
+	resp, err := AnalyzeImage(testRequest(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PromptTokens != 1123 || resp.CompletionTokens != 87 || resp.TotalTokens != 1210 {
+		t.Errorf("usage = %d/%d/%d, want 1123/87/1210", resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens)
+	}
+}
+
+func TestAnalyzeImageUsageAddsReAsk(t *testing.T) {
+	server, calls := newTestServer(t,
+		reply{http.StatusOK, "application/json",
+			`{"choices":[{"message":{"content":"Chanterelle"}}],"usage":{"prompt_tokens":100,"completion_tokens":5,"total_tokens":105}}`},
+		reply{http.StatusOK, "application/json",
+			`{"choices":[{"message":{"content":"Chanterelle (Cantharellus cibarius), edible"}}],"usage":{"prompt_tokens":120,"completion_tokens":15,"total_tokens":135}}`})
+
+	req := testRequest(server.URL)
+	req.MinContentLength = 20
+	resp, err := AnalyzeImage(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 || !strings.HasPrefix(resp.Content, "Chanterelle (") {
+		t.Fatalf("got %q after %d calls", resp.Content, calls.Load())
+	}
+	if resp.PromptTokens != 220 || resp.CompletionTokens != 20 || resp.TotalTokens != 240 {
+		t.Errorf("usage = %d/%d/%d, want both calls added", resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens)
+	}
+}
+
+func TestAnalyzeImageMaxImages(t *testing.T) {
+	server, calls := newTestServer(t, reply{http.StatusOK, "application/json",
+		`{"choices":[{"message":{"content":"Chanterelle"},"finish_reason":"stop"}]}`})
+
+	tests := []struct {
+		images    int
+		maxImages int
+		ok        bool
+	}{
+		{2, 3, true},
+		{3, 3, true},
+		{4, 3, false},
+		{12, 0, true},
+	}
+	for _, tt := range tests {
+		req := testRequest(server.URL)
+		req.MaxImages = tt.maxImages
+		for i := 0; i < tt.images; i++ {
+			req.Images = append(req.Images, ImageInput{Data: "AAAA", MimeType: "image/png"})
+		}
+
+		before := calls.Load()
+		resp, err := AnalyzeImage(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent := calls.Load() != before
+		if tt.ok && (!resp.Success || !sent) {
+			t.Errorf("%d of max %d images: got %+v, want it sent", tt.images, tt.maxImages, resp)
+		}
+		if !tt.ok && (resp.Success || sent || resp.Category != CategoryImage || !strings.Contains(resp.ErrorMessage, "Too many images")) {
+			t.Errorf("%d of max %d images: got %+v (sent %v), want an image error without a call", tt.images, tt.maxImages, resp, sent)
+		}
+	}
+}
+
+func TestAnalyzeImageOrganizationHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		// Valid both as a chat completion and as a models list
+		io.WriteString(w, `{"choices":[{"message":{"content":"Chanterelle"},"finish_reason":"stop"}],"data":[]}`)
+	}))
+	defer server.Close()
+
+	calls := map[string]func(req *Request) error{
+		"plain": func(req *Request) error { _, err := AnalyzeImage(req); return err },
+		"streamed": func(req *Request) error {
+			_, err := AnalyzeImageStream(req, nil)
+			return err
+		},
+		"models": func(req *Request) error { _, err := ListModels(req); return err },
+	}
+	for name, call := range calls {
+		req := testRequest(server.URL + "/v1/chat/completions")
+		req.Organization = "org-123"
+		req.Project = "proj_456"
+		if err := call(req); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.Get("OpenAI-Organization") != "org-123" || got.Get("OpenAI-Project") != "proj_456" {
+			t.Errorf("%s: headers %v, want the organization and project", name, got)
+		}
+		if got.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("%s: Authorization = %q, want the key next to the OpenAI headers", name, got.Get("Authorization"))
+		}
+
+		// Unset, the headers are left out rather than sent empty
+		if err := call(testRequest(server.URL + "/v1/chat/completions")); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, header := range []string{"OpenAI-Organization", "OpenAI-Project"} {
+			if len(got.Values(header)) > 0 {
+				t.Errorf("%s: %s sent without being configured", name, header)
+			}
+		}
+	}
+}
+
+// sentImages returns the image_url objects of the last message of a
+// recorded request body
+func sentImages(t *testing.T, sent map[string]any) []map[string]any {
+	t.Helper()
+	messages, _ := sent["messages"].([]any)
+	if len(messages) == 0 {
+		t.Fatalf("no messages in %v", sent)
+	}
+	last, _ := messages[len(messages)-1].(map[string]any)
+	parts, _ := last["content"].([]any)
+	var images []map[string]any
+	for _, part := range parts {
+		part, _ := part.(map[string]any)
+		if image, ok := part["image_url"].(map[string]any); ok {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+func TestAnalyzeImageRawBase64(t *testing.T) {
+	tests := []struct {
+		raw      bool
+		mimeType string
+		want     string
+	}{
+		{false, "image/png", "data:image/png;base64,iVBORw0K"},
+		{false, "", "data:image/jpeg;base64,iVBORw0K"},
+		{true, "image/png", "iVBORw0K"},
+	}
+	for _, tt := range tests {
+		server, sent := answerServer(t, "Chanterelle")
+		req := testRequest(server.URL)
+		req.Base64Image = "iVBORw0K"
+		req.MimeType = tt.mimeType
+		req.RawBase64Image = tt.raw
+		if _, err := AnalyzeImage(req); err != nil {
+			t.Fatal(err)
+		}
+
+		images := sentImages(t, *sent)
+		if len(images) != 1 || images[0]["url"] != tt.want {
+			t.Errorf("raw %v, type %q: sent %v, want url %q", tt.raw, tt.mimeType, images, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeImageTruncated(t *testing.T) {
+	tests := []struct {
+		finishReason string
+		truncated    bool
+	}{
+		{"length", true},
+		{"stop", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		body := `{"choices":[{"message":{"content":"Chanterelle (Cantharellus"},"finish_reason":"` + tt.finishReason + `"}]}`
+		server, _ := newTestServer(t, reply{http.StatusOK, "application/json", body})
+		resp, err := AnalyzeImage(testRequest(server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Success || resp.Truncated != tt.truncated {
+			t.Errorf("finish_reason %q: Success %v, Truncated %v; want Truncated %v",
+				tt.finishReason, resp.Success, resp.Truncated, tt.truncated)
+		}
+
+		events := `data: {"choices":[{"delta":{"content":"Chanterelle"},"finish_reason":"` + tt.finishReason + `"}]}` + "\n\ndata: [DONE]\n\n"
+		server, _ = streamServer(t, "text/event-stream", events)
+		resp, err = AnalyzeImageStream(testRequest(server.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Truncated != tt.truncated {
+			t.Errorf("streamed finish_reason %q: Truncated %v, want %v", tt.finishReason, resp.Truncated, tt.truncated)
+		}
+	}
+}
+
+func TestAnalyzeImageEmptyResponse(t *testing.T) {
+	tests := []struct {
+		content      string
+		finishReason string
+		wantMessage  string
+	}{
+		{"", "stop", "empty response"},
+		{" \n\t", "content_filter", "empty response"},
+		{"", "length", "max tokens limit"},
+	}
+	for _, tt := range tests {
+		content, _ := json.Marshal(tt.content)
+		server, _ := newTestServer(t, reply{http.StatusOK, "application/json",
+			`{"choices":[{"message":{"content":` + string(content) + `},"finish_reason":"` + tt.finishReason + `"}],` +
+				`"usage":{"prompt_tokens":900,"completion_tokens":0,"total_tokens":900}}`})
+
+		resp, err := AnalyzeImage(testRequest(server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Success || resp.Category != CategoryParse || !strings.Contains(resp.ErrorMessage, tt.wantMessage) {
+			t.Errorf("content %q: got %+v, want a parse error mentioning %q", tt.content, resp, tt.wantMessage)
+		}
+		// The call was still billed
+		if resp.TotalTokens != 900 || resp.StatusCode != http.StatusOK || len(resp.RawJSON) == 0 {
+			t.Errorf("content %q: usage %d, status %d, raw body %d bytes; want them kept",
+				tt.content, resp.TotalTokens, resp.StatusCode, len(resp.RawJSON))
+		}
+	}
+}
+
+func TestAnalyzeImageURL(t *testing.T) {
+	const photo = "https://static.inaturalist.org/photos/1234/large.jpg"
+	for _, raw := range []bool{false, true} {
+		server, sent := answerServer(t, "Chanterelle")
+		req := testRequest(server.URL)
+		req.ImageURL = photo
+		req.Base64Image = "iVBORw0K"
+		req.MimeType = "image/png"
+		req.RawBase64Image = raw
+		if _, err := AnalyzeImage(req); err != nil {
+			t.Fatal(err)
+		}
+
+		// The URL goes first and as is, never as a data URL
+		images := sentImages(t, *sent)
+		if len(images) != 2 || images[0]["url"] != photo {
+			t.Errorf("raw %v: sent %v, want %s first", raw, images, photo)
+		}
+	}
+
+	for _, invalid := range []string{"ftp://example.com/cap.jpg", "/home/me/cap.jpg", "https://"} {
+		server, calls := newTestServer(t, reply{http.StatusOK, "application/json", `{}`})
+		req := testRequest(server.URL)
+		req.ImageURL = invalid
+		resp, err := AnalyzeImage(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Success || resp.Category != CategoryImage || calls.Load() != 0 {
+			t.Errorf("%q: got %+v after %d calls, want an image error without a call", invalid, resp, calls.Load())
+		}
+	}
+}
+
+func TestAnalyzeImageSamplingParametersSent(t *testing.T) {
+	server, sent := answerServer(t, "Chanterelle")
+	temperature, topP := 0.0, 0.5
+	req := testRequest(server.URL)
+	req.Temperature = &temperature
+	req.TopP = &topP
+	if _, err := AnalyzeImage(req); err != nil {
+		t.Fatal(err)
+	}
+	// A zero temperature is sent rather than dropped as empty
+	if value, ok := (*sent)["temperature"]; !ok || value != 0.0 {
+		t.Errorf("temperature = %v (sent %v), want 0", value, ok)
+	}
+	if (*sent)["top_p"] != 0.5 {
+		t.Errorf("top_p = %v, want 0.5", (*sent)["top_p"])
+	}
+
+	// Unset, the API defaults apply
+	server, sent = answerServer(t, "Chanterelle")
+	if _, err := AnalyzeImage(testRequest(server.URL)); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"temperature", "top_p"} {
+		if value, ok := (*sent)[field]; ok {
+			t.Errorf("%s = %v sent without being set", field, value)
+		}
+	}
+}
+
+func TestAnalyzeImageSystemPrompt(t *testing.T) {
+	server, sent := answerServer(t, "Chanterelle")
+	req := testRequest(server.URL)
+	req.SystemPrompt = "You are a careful mycologist."
+	if _, err := AnalyzeImage(req); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, _ := (*sent)["messages"].([]any)
+	var roles, texts []string
+	for _, m := range messages {
+		m, _ := m.(map[string]any)
+		roles = append(roles, fmt.Sprint(m["role"]))
+		parts, _ := m["content"].([]any)
+		part, _ := parts[0].(map[string]any)
+		texts = append(texts, fmt.Sprint(part["text"]))
+	}
+	if strings.Join(roles, ",") != "system,user" {
+		t.Fatalf("roles = %q, want a system and a user message", roles)
+	}
+	if texts[0] != "You are a careful mycologist." || texts[1] != "Identify this mushroom" {
+		t.Errorf("texts = %q, want the system prompt apart from the prompt", texts)
+	}
+
+	// Without a system prompt only the user message is sent
+	server, sent = answerServer(t, "Chanterelle")
+	if _, err := AnalyzeImage(testRequest(server.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if messages, _ := (*sent)["messages"].([]any); len(messages) != 1 {
+		t.Errorf("sent %d messages, want 1", len(messages))
+	}
+}
+
+func TestAnalyzeImageDetail(t *testing.T) {
+	for _, detail := range []string{"low", "high", ""} {
+		server, sent := answerServer(t, "Chanterelle")
+		req := testRequest(server.URL)
+		req.Base64Image = "iVBORw0K"
+		req.ImageDetail = detail
+		if _, err := AnalyzeImage(req); err != nil {
+			t.Fatal(err)
+		}
+
+		images := sentImages(t, *sent)
+		if len(images) != 1 {
+			t.Fatalf("sent %v, want one image", images)
+		}
+		value, ok := images[0]["detail"]
+		if detail == "" && ok {
+			t.Errorf("detail %v sent without being set", value)
+		}
+		if detail != "" && value != detail {
+			t.Errorf("detail = %v, want %q", value, detail)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// extractPath returns the string found at a path in a JSON document
+//
+// The path uses dots for object keys and brackets for array indexes,
+// e.g. "choices[0].message.content" or "output[0].text".
+func extractPath(body []byte, path string) (string, error) {
+	var node any
+	if err := json.Unmarshal(body, &node); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	segments, err := splitPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, seg := range segments {
+		switch v := node.(type) {
+		case map[string]any:
+			if seg.key == "" {
+				return "", fmt.Errorf("path %q: expected an index, found an object", path)
+			}
+			child, ok := v[seg.key]
+			if !ok {
+				return "", fmt.Errorf("path %q: key %q not found", path, seg.key)
+			}
+			node = child
+		case []any:
+			if seg.key != "" {
+				return "", fmt.Errorf("path %q: expected key %q, found an array", path, seg.key)
+			}
+			if seg.index >= len(v) {
+				return "", fmt.Errorf("path %q: index %d out of range", path, seg.index)
+			}
+			node = v[seg.index]
+		default:
+			return "", fmt.Errorf("path %q: cannot descend into a %T", path, node)
+		}
+	}
+
+	text, ok := node.(string)
+	if !ok {
+		return "", fmt.Errorf("path %q: value is not a string", path)
+	}
+	return text, nil
+}
+
+// pathSegment is one step of a path: an object key or an array index
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// splitPath parses a path such as "output[0].text" into segments
+func splitPath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		key, rest, indexed := strings.Cut(part, "[")
+		if key == "" && !indexed {
+			return nil, fmt.Errorf("invalid path %q: empty segment", path)
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+
+		// Any number of trailing indexes, e.g. "a[0][1]"
+		for indexed {
+			digits, after, ok := strings.Cut(rest, "]")
+			index, err := strconv.Atoi(digits)
+			if !ok || err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: bad index in %q", path, part)
+			}
+			segments = append(segments, pathSegment{index: index})
+
+			if after != "" && after[0] != '[' {
+				return nil, fmt.Errorf("invalid path %q: unexpected %q", path, after)
+			}
+			rest, indexed = strings.CutPrefix(after, "[")
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractPath(t *testing.T) {
+	body := []byte(`{
+		"choices": [{"message": {"content": "Chanterelle"}}],
+		"output": [{"text": "first"}, {"text": "second"}],
+		"grid": [["a", "b"], ["c", "d"]],
+		"result": {"answer": {"text": "Porcini"}, "score": 0.9}
+	}`)
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: "choices[0].message.content", want: "Chanterelle"},
+		{path: "output[1].text", want: "second"},
+		{path: "grid[1][0]", want: "c"},
+		{path: "result.answer.text", want: "Porcini"},
+
+		{path: "result.missing", wantErr: `key "missing" not found`},
+		{path: "output[2].text", wantErr: "index 2 out of range"},
+		{path: "result[0]", wantErr: "expected an index, found an object"},
+		{path: "output.text", wantErr: `expected key "text", found an array`},
+		{path: "result.score", wantErr: "value is not a string"},
+		{path: "result.score.x", wantErr: "cannot descend into a float64"},
+		{path: "result..text", wantErr: "empty segment"},
+		{path: "output[x]", wantErr: "bad index"},
+		{path: "output[-1]", wantErr: "bad index"},
+		{path: "output[0", wantErr: "bad index"},
+		{path: "output[0]text", wantErr: "unexpected"},
+	}
+
+	for _, tt := range tests {
+		got, err := extractPath(body, tt.path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("extractPath(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("extractPath(%q): %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("extractPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExtractPathInvalidJSON(t *testing.T) {
+	if _, err := extractPath([]byte(`{"choices": [`), "choices[0]"); err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+		t.Errorf("error = %v, want invalid JSON", err)
+	}
+}
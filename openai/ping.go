@@ -0,0 +1,43 @@
+package openai
+
+import "context"
+
+// pingPrompt is the question sent to check a connection
+const pingPrompt = "Reply with OK."
+
+// PingRequest returns a minimal text-only copy of req
+//
+// Keeps the endpoint, key, model and timeout of req but drops images,
+// history and other extras, and limits the answer to a single token, so
+// the request costs next to nothing.
+func PingRequest(req *Request) *Request {
+	return &Request{
+		APIKey:       req.APIKey,
+		APIURL:       req.APIURL,
+		Organization: req.Organization,
+		Project:      req.Project,
+		Model:        req.Model,
+		Prompt:       pingPrompt,
+		MaxTokens:    1,
+		Timeout:      req.Timeout,
+		Proxy:        req.Proxy,
+		CAFile:       req.CAFile,
+		RateLimiter:  req.RateLimiter,
+		ContentPath:  req.ContentPath,
+		Debug:        req.Debug,
+	}
+}
+
+// Ping checks that the API accepts the key and URL of req
+//
+// Sends PingRequest(req) and returns nil if the API answered, or the
+// *Error of the failure, whose category tells e.g. an invalid key
+// (CategoryAuth) from an unreachable server. Returns ctx.Err() if ctx is
+// done first.
+func Ping(ctx context.Context, req *Request) error {
+	resp, err := AnalyzeImageContext(ctx, PingRequest(req))
+	if err != nil {
+		return err
+	}
+	return resp.Err()
+}
@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPingOK(t *testing.T) {
+	var sent map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"OK"},"finish_reason":"length"}]}`))
+	}))
+	defer server.Close()
+
+	req := testRequest(server.URL)
+	req.Base64Image = "AAAA"
+	if err := Ping(context.Background(), req); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if sent["max_tokens"] != 1.0 {
+		t.Errorf("max_tokens = %v, want 1", sent["max_tokens"])
+	}
+	if body, _ := json.Marshal(sent); strings.Contains(string(body), "image_url") {
+		t.Errorf("ping request carries the image: %s", body)
+	}
+}
+
+func TestPingUnauthorized(t *testing.T) {
+	server, calls := newTestServer(t, reply{http.StatusUnauthorized, "application/json",
+		`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`})
+
+	err := Ping(context.Background(), testRequest(server.URL))
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *Error", err)
+	}
+	if apiErr.Category != CategoryAuth || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got category %q, status %d, want auth and 401", apiErr.Category, apiErr.StatusCode)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server called %d times, want once", calls.Load())
+	}
+}
@@ -0,0 +1,119 @@
+package openai
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+)
+
+// maxShrinkAttempts bounds the retries with smaller images after the API
+// rejected a request as too large
+const maxShrinkAttempts = 3
+
+// shrinkStep is the factor the image sides and JPEG quality are reduced
+// by on each retry, so every attempt roughly halves the pixel count
+const shrinkStep = 0.7
+
+// shrinkStartQuality is the JPEG quality the retries are scaled down from
+const shrinkStartQuality = 85
+
+// analyzeShrinking is like analyze but retries with smaller images when
+// the request is too large
+//
+// With ShrinkMinQuality set, a request the API rejects with HTTP 413 is
+// sent again with every inline image re-encoded as JPEG at a lower
+// quality and size, up to maxShrinkAttempts times. Each attempt starts
+// from the original images, so the losses do not compound. Image URLs
+// are sent unchanged. The do function is reused for the retries; it
+// must take the images from the messages only.
+func analyzeShrinking(req *Request, do func(messages []message) *Response) *Response {
+	resp := analyze(req, do)
+	if req.ShrinkMinQuality <= 0 {
+		return resp
+	}
+
+	for attempt := 1; attempt <= maxShrinkAttempts && resp.StatusCode == http.StatusRequestEntityTooLarge; attempt++ {
+		factor := math.Pow(shrinkStep, float64(attempt))
+		quality := max(req.ShrinkMinQuality, int(shrinkStartQuality*factor))
+
+		shrunk, err := shrinkImages(req, factor, quality)
+		if err != nil {
+			if logger := req.logger(); logger != nil {
+				logger.Printf("Cannot shrink images after HTTP 413: %v", err)
+			}
+			return resp
+		}
+		if shrunk == nil {
+			// Nothing left that could be made smaller
+			return resp
+		}
+
+		if logger := req.logger(); logger != nil {
+			logger.Printf("Request too large, retrying with images at %.0f%% size and JPEG quality %d",
+				factor*100, quality)
+		}
+		resp = analyze(shrunk, do)
+	}
+	return resp
+}
+
+// shrinkImages returns a copy of req with its inline images scaled by
+// factor and re-encoded at the given JPEG quality
+//
+// Returns nil if the request has no inline images.
+func shrinkImages(req *Request, factor float64, quality int) (*Request, error) {
+	shrunk := *req
+	changed := false
+
+	if req.Base64Image != "" {
+		data, err := shrinkImage(req.Base64Image, factor, quality)
+		if err != nil {
+			return nil, err
+		}
+		shrunk.Base64Image = data
+		shrunk.MimeType = "image/jpeg"
+		changed = true
+	}
+
+	shrunk.Images = make([]ImageInput, len(req.Images))
+	for i, img := range req.Images {
+		if img.URL == "" && img.Data != "" {
+			data, err := shrinkImage(img.Data, factor, quality)
+			if err != nil {
+				return nil, fmt.Errorf("image %d: %w", i+1, err)
+			}
+			img.Data = data
+			img.MimeType = "image/jpeg"
+			changed = true
+		}
+		shrunk.Images[i] = img
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return &shrunk, nil
+}
+
+// shrinkImage scales base64 image data by factor and re-encodes it as
+// JPEG at the given quality
+func shrinkImage(data string, factor float64, quality int) (string, error) {
+	raw, err := base64.DecodeData(data)
+	if err != nil {
+		return "", err
+	}
+	img, _, err := base64.DecodeOriented(raw)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := img.Bounds()
+	maxDim := max(1, int(float64(max(bounds.Dx(), bounds.Dy()))*factor))
+	encoded, err := base64.EncodeJPEG(base64.Downscale(img, maxDim), quality)
+	if err != nil {
+		return "", err
+	}
+	return base64.EncodeData(encoded), nil
+}
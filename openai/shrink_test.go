@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+)
+
+// noisyImage returns a base64 PNG that does not compress well
+func noisyImage(t *testing.T) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * y), uint8(x ^ y), uint8(x + 3*y), 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return base64.EncodeData(buf.Bytes())
+}
+
+// shrinkServer rejects the first request as too large and answers the
+// others, recording the body of each
+func shrinkServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(`{"error":{"message":"Request too large"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"Morel"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func TestAnalyzeImageShrinksAfter413(t *testing.T) {
+	server, bodies := shrinkServer(t)
+
+	req := testRequest(server.URL)
+	req.Base64Image = noisyImage(t)
+	req.MimeType = "image/png"
+	req.ShrinkMinQuality = 40
+	resp, err := AnalyzeImage(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || resp.Content != "Morel" {
+		t.Fatalf("got %+v, want the answer to the smaller retry", resp)
+	}
+
+	if len(*bodies) != 2 {
+		t.Fatalf("server called %d times, want 2", len(*bodies))
+	}
+	first, retry := (*bodies)[0], (*bodies)[1]
+	if len(retry) >= len(first) {
+		t.Errorf("retry body is %d bytes, not smaller than the first %d", len(retry), len(first))
+	}
+	if !strings.Contains(retry, "data:image/jpeg;base64,") {
+		t.Error("retry does not send the image as JPEG")
+	}
+}
+
+func TestAnalyzeImageNoShrinkByDefault(t *testing.T) {
+	server, bodies := shrinkServer(t)
+
+	req := testRequest(server.URL)
+	req.Base64Image = noisyImage(t)
+	req.MimeType = "image/png"
+	resp, err := AnalyzeImage(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || resp.StatusCode != http.StatusRequestEntityTooLarge || len(*bodies) != 1 {
+		t.Errorf("got %+v after %d calls, want the 413 without a retry", resp, len(*bodies))
+	}
+}
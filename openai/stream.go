@@ -0,0 +1,240 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/httpclient"
+)
+
+// maxEventSize bounds a single server-sent event line
+const maxEventSize = 1024 * 1024
+
+// chatCompletionChunk represents one streamed chunk of a chat completion
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *usage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+	} `json:"error"`
+}
+
+// AnalyzeImageStream is like AnalyzeImage but streams the answer
+//
+// Requests a streamed completion and calls onDelta with each piece of
+// text as it arrives. The returned Response contains the full answer.
+// If the answer is re-asked because of MinContentLength, the deltas of
+// the second answer follow those of the first.
+func AnalyzeImageStream(req *Request, onDelta func(string)) (*Response, error) {
+	return AnalyzeImageStreamContext(context.Background(), req, onDelta)
+}
+
+// AnalyzeImageStreamContext is like AnalyzeImageStream but can be
+// cancelled
+//
+// If ctx is done before the stream ends, reading stops and ctx.Err() is
+// returned instead of a Response.
+func AnalyzeImageStreamContext(ctx context.Context, req *Request, onDelta func(string)) (*Response, error) {
+	resp := analyzeShrinking(req, func(messages []message) *Response {
+		return sendStream(ctx, req, messages, onDelta)
+	})
+	return contextResult(ctx, resp)
+}
+
+// sendStream performs one streamed chat completion call
+//
+// Reads server-sent events from the response body: each "data:" line
+// holds a JSON chunk, and "data: [DONE]" ends the stream. A server that
+// answers with a complete body instead is handled like send, with its
+// answer passed to onDelta at once. With RetryMalformedJSON, a malformed
+// chunk retries the call once, provided no text was passed on yet.
+func sendStream(ctx context.Context, req *Request, messages []message, onDelta func(string)) *Response {
+	// Build request
+	chatReq := newChatRequest(req, messages)
+	chatReq.Stream = true
+	// Usage is only reported in a final chunk when asked for
+	chatReq.StreamOptions = &streamOptions{IncludeUsage: true}
+
+	// Marshal to JSON
+	jsonBody, err := encodeBody(chatReq, req.Debug)
+	if err != nil {
+		return errorResponse(CategoryOther, fmt.Sprintf("Failed to marshal request: %v", err))
+	}
+	httpReq := req.httpRequest(jsonBody)
+
+	for attempt := 1; ; attempt++ {
+		httpResp, err := httpclient.PostJSONStreamContext(ctx, httpReq)
+		if err != nil {
+			return streamErrorResponse(httpResp, err)
+		}
+
+		var resp *Response
+		var malformed bool
+		if isEventStream(httpResp.ContentType) {
+			resp, malformed = readEvents(req, httpResp, onDelta)
+		} else {
+			resp, malformed = readCompletion(req, httpResp, onDelta)
+		}
+		httpResp.Body.Close()
+
+		if malformed && attempt < req.attempts() {
+			continue
+		}
+		return resp
+	}
+}
+
+// streamErrorResponse builds the Response for a failed stream request
+//
+// Error bodies are handled like those of plain calls, see
+// httpErrorResponse.
+func streamErrorResponse(httpResp *httpclient.StreamResponse, err error) *Response {
+	if httpResp == nil {
+		return httpErrorResponse(nil, err)
+	}
+	defer httpResp.Body.Close()
+
+	body, _ := io.ReadAll(httpResp.Body)
+	return httpErrorResponse(&httpclient.Response{
+		Body:        body,
+		StatusCode:  httpResp.StatusCode,
+		ContentType: httpResp.ContentType,
+	}, err)
+}
+
+// isEventStream reports whether a streamed response holds server-sent
+// events
+//
+// A missing media type is taken as a stream, as some compatible servers
+// do not declare one.
+func isEventStream(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// readCompletion reads a complete answer sent in reply to a stream
+// request
+//
+// Reports whether the body was malformed JSON and may be retried.
+func readCompletion(req *Request, httpResp *httpclient.StreamResponse, onDelta func(string)) (*Response, bool) {
+	status := httpResp.StatusCode
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return errorResponse(categorizeTransportError(err), fmt.Sprintf("Failed to read response: %v", err)).withStatus(status), false
+	}
+
+	resp, err := parseCompletion(req, status, httpResp.ContentType, body)
+	if err != nil {
+		return errorResponse(CategoryParse, fmt.Sprintf("Failed to parse response: %v", err)).withStatus(status), true
+	}
+	if resp.Success && onDelta != nil {
+		onDelta(resp.Content)
+	}
+	return resp, false
+}
+
+// readEvents reads the answer from server-sent events as they arrive
+//
+// Reports whether a chunk was malformed before any text was passed to
+// onDelta, in which case the call may be retried.
+func readEvents(req *Request, httpResp *httpclient.StreamResponse, onDelta func(string)) (*Response, bool) {
+	status := httpResp.StatusCode
+	var answer strings.Builder
+	var chunks []json.RawMessage
+	var tokens *usage
+	var truncated bool
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	// deliver adds a piece of the answer and passes it on
+	deliver := func(text string) {
+		answer.WriteString(text)
+		if onDelta != nil {
+			onDelta(text)
+		}
+	}
+
+	done := false
+	for !done && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			// Blank separators, comments and other fields
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return errorResponse(CategoryParse, fmt.Sprintf("Failed to parse stream chunk: %v", err)).withStatus(status),
+				answer.Len() == 0
+		}
+		chunks = append(chunks, json.RawMessage(data))
+
+		if chunk.Error != nil {
+			category := categorizeAPIError(chunk.Error.Type, chunk.Error.Code)
+			resp := errorResponse(category, fmt.Sprintf("OpenAI API error: %s", chunk.Error.Message)).withStatus(status)
+			resp.RawJSON = json.RawMessage(data)
+			return resp, false
+		}
+
+		if chunk.Usage != nil {
+			tokens = chunk.Usage
+		}
+
+		// Chunks in another shape carry their text at the custom path
+		if len(chunk.Choices) == 0 && req.ContentPath != "" {
+			if text, err := extractPath([]byte(data), req.ContentPath); err == nil && text != "" {
+				deliver(text)
+			}
+		}
+
+		for _, choice := range chunk.Choices {
+			// Only the last chunk of an answer carries a finish reason
+			if choice.FinishReason != "" {
+				truncated = choice.FinishReason == finishReasonLength
+			}
+			if choice.Delta.Content != "" {
+				deliver(choice.Delta.Content)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errorResponse(categorizeTransportError(err), fmt.Sprintf("Failed to read stream: %v", err)).withStatus(status), false
+	}
+
+	if answer.Len() == 0 {
+		return errorResponse(CategoryParse, "No response from OpenAI API").withStatus(status), false
+	}
+
+	resp := &Response{
+		Success:    true,
+		Content:    answer.String(),
+		StatusCode: status,
+		Truncated:  truncated,
+		Choices:    []string{answer.String()},
+	}
+	resp.RawJSON, _ = json.Marshal(chunks)
+	resp.addUsage(tokens)
+	return resp, false
+}
@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// streamServer answers each call with the next of the given replies,
+// sent with status 200 and the given content type
+func streamServer(t *testing.T, contentType string, bodies ...string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	replies := make([]reply, len(bodies))
+	for i, body := range bodies {
+		replies[i] = reply{http.StatusOK, contentType, body}
+	}
+	return newTestServer(t, replies...)
+}
+
+func TestAnalyzeImageStreamEvents(t *testing.T) {
+	server, _ := streamServer(t, "text/event-stream",
+		"data: {\"choices\":[{\"delta\":{\"content\":\"Chante\"}}]}\n\n"+
+			"data: {\"choices\":[{\"delta\":{\"content\":\"relle\"},\"finish_reason\":\"stop\"}]}\n\n"+
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2,\"total_tokens\":7}}\n\n"+
+			"data: [DONE]\n\n")
+
+	var deltas []string
+	resp, err := AnalyzeImageStream(testRequest(server.URL), func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || resp.Content != "Chanterelle" {
+		t.Fatalf("got %+v", resp)
+	}
+	if strings.Join(deltas, "|") != "Chante|relle" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if resp.TotalTokens != 7 {
+		t.Errorf("TotalTokens = %d, want 7", resp.TotalTokens)
+	}
+
+	var chunks []json.RawMessage
+	if err := json.Unmarshal(resp.RawJSON, &chunks); err != nil || len(chunks) != 3 {
+		t.Errorf("RawJSON = %s, want an array of 3 chunks", resp.RawJSON)
+	}
+}
+
+func TestAnalyzeImageStreamContentPath(t *testing.T) {
+	server, _ := streamServer(t, "text/event-stream",
+		"data: {\"output\":{\"text\":\"Fly \"}}\n\ndata: {\"output\":{\"text\":\"agaric\"}}\n\ndata: [DONE]\n\n")
+
+	req := testRequest(server.URL)
+	req.ContentPath = "output.text"
+	resp, err := AnalyzeImageStream(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Fly agaric" {
+		t.Errorf("Content = %q, want %q", resp.Content, "Fly agaric")
+	}
+}
+
+func TestAnalyzeImageStreamCompleteBody(t *testing.T) {
+	server, _ := streamServer(t, "application/json",
+		`{"choices":[{"message":{"content":"Porcini"},"finish_reason":"stop"}]}`)
+
+	var deltas []string
+	resp, err := AnalyzeImageStream(testRequest(server.URL), func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Porcini" || len(deltas) != 1 || deltas[0] != "Porcini" {
+		t.Errorf("Content = %q, deltas = %q", resp.Content, deltas)
+	}
+	if resp.RawJSON == nil {
+		t.Error("RawJSON not set")
+	}
+}
+
+func TestAnalyzeImageStreamNonJSON(t *testing.T) {
+	server, _ := streamServer(t, "text/html", "<html><body>Maintenance</body></html>")
+
+	resp, err := AnalyzeImageStream(testRequest(server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || !strings.Contains(resp.ErrorMessage, "non-JSON") {
+		t.Errorf("got %+v, want a non-JSON failure", resp)
+	}
+}
+
+func TestAnalyzeImageStreamRetryMalformed(t *testing.T) {
+	server, calls := streamServer(t, "text/event-stream",
+		"data: {\"choices\":[{\"delta\":\n\n",
+		"data: {\"choices\":[{\"delta\":{\"content\":\"Morel\"}}]}\n\ndata: [DONE]\n\n")
+
+	req := testRequest(server.URL)
+	req.RetryMalformedJSON = true
+	resp, err := AnalyzeImageStream(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || resp.Content != "Morel" || calls.Load() != 2 {
+		t.Errorf("got %+v after %d calls", resp, calls.Load())
+	}
+}
@@ -0,0 +1,192 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/prompts"
+)
+
+// responseFormat selects the output format of a chat completion
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+// jsonSchema is a named JSON schema for the "json_schema" response format
+type jsonSchema struct {
+	Name   string          `json:"name"`
+	Strict bool            `json:"strict"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// Edibility values allowed by the mushroom schema
+const (
+	EdibilityEdible    = "edible"
+	EdibilityPoisonous = "poisonous"
+	EdibilityInedible  = "inedible"
+	EdibilityUnknown   = "unknown"
+)
+
+// mushroomSchema describes a Classification for strict structured output
+//
+// Strict mode requires every property to be listed as required and no
+// others to be allowed.
+var mushroomSchema = &jsonSchema{
+	Name:   "mushroom_classification",
+	Strict: true,
+	Schema: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "species": {"type": "string", "description": "Common name"},
+    "scientific_name": {"type": "string", "description": "Genus species"},
+    "confidence": {"type": "string", "enum": ["High", "Medium", "Low"]},
+    "features": {"type": "array", "items": {"type": "string"}},
+    "edibility": {"type": "string", "enum": ["edible", "poisonous", "inedible", "unknown"]},
+    "safety_warning": {"type": "string"},
+    "similar_species": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["species", "scientific_name", "confidence", "features", "edibility", "safety_warning", "similar_species"],
+  "additionalProperties": false
+}`),
+}
+
+// responseFormat returns the response format requested by req, or nil
+func (req *Request) responseFormat() *responseFormat {
+	if req.schema != nil {
+		return &responseFormat{Type: "json_schema", JSONSchema: req.schema}
+	}
+	if req.JSONResponse {
+		return &responseFormat{Type: "json_object"}
+	}
+	return nil
+}
+
+// Classification is a mushroom analysis parsed from a JSON answer
+type Classification struct {
+	// Common name of the species
+	Species string `json:"species"`
+
+	// Scientific (binomial) name
+	ScientificName string `json:"scientific_name"`
+
+	// How certain the identification is (High/Medium/Low)
+	Confidence string `json:"confidence"`
+
+	// Visual characteristics that led to the identification
+	Features []string `json:"features"`
+
+	// Whether the mushroom is edible, poisonous or unknown; one of the
+	// Edibility constants for results of AnalyzeImageSchema
+	Edibility string `json:"edibility"`
+
+	// Important safety information
+	SafetyWarning string `json:"safety_warning"`
+
+	// Mushrooms it might be confused with
+	SimilarSpecies []string `json:"similar_species"`
+}
+
+// AnalyzeImageStructured classifies an image and parses the typed result
+//
+// Sends req with the JSON analysis prompt in place of req.Prompt and
+// asks the API for a JSON object via response_format. req itself is not
+// modified. Failed requests return the *Error of the Response; answers
+// that are not valid JSON, or name no species at all, return an *Error
+// with CategoryParse.
+func AnalyzeImageStructured(req *Request) (*Classification, error) {
+	return AnalyzeImageStructuredContext(context.Background(), req)
+}
+
+// AnalyzeImageStructuredContext is like AnalyzeImageStructured but can be
+// cancelled
+func AnalyzeImageStructuredContext(ctx context.Context, req *Request) (*Classification, error) {
+	structured := *req
+	structured.Prompt = prompts.MushroomJSON()
+	structured.JSONResponse = true
+	// A follow-up nudge would break the JSON answer
+	structured.MinContentLength = 0
+
+	resp, err := AnalyzeImageContext(ctx, &structured)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+
+	return parseClassification(resp.Content)
+}
+
+// AnalyzeImageSchema classifies an image into a guaranteed shape
+//
+// Like AnalyzeImageStructured, but asks the API for a strict JSON schema
+// via response_format, so the answer has every field of Classification
+// and a confidence and edibility from the allowed values. The edibility
+// is checked again here; a value outside the Edibility constants returns
+// an *Error with CategoryParse. Requires a model that supports
+// structured outputs, such as gpt-4o.
+func AnalyzeImageSchema(req *Request) (*Classification, error) {
+	return AnalyzeImageSchemaContext(context.Background(), req)
+}
+
+// AnalyzeImageSchemaContext is like AnalyzeImageSchema but can be
+// cancelled
+func AnalyzeImageSchemaContext(ctx context.Context, req *Request) (*Classification, error) {
+	structured := *req
+	structured.Prompt = prompts.MushroomJSON()
+	structured.schema = mushroomSchema
+	structured.MinContentLength = 0
+
+	resp, err := AnalyzeImageContext(ctx, &structured)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+
+	c, err := parseClassification(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEdibility(c.Edibility); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// checkEdibility rejects edibility values the schema does not allow
+func checkEdibility(edibility string) error {
+	switch edibility {
+	case EdibilityEdible, EdibilityPoisonous, EdibilityInedible, EdibilityUnknown:
+		return nil
+	default:
+		return &Error{
+			Category: CategoryParse,
+			Message:  fmt.Sprintf("Model returned an unknown edibility %q", edibility),
+		}
+	}
+}
+
+// parseClassification parses and checks a JSON analysis answer
+func parseClassification(text string) (*Classification, error) {
+	var c Classification
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &c); err != nil {
+		return nil, &Error{
+			Category: CategoryParse,
+			Message:  fmt.Sprintf("Model returned invalid JSON: %v", err),
+		}
+	}
+
+	if c.Species == "" && c.ScientificName == "" {
+		return nil, &Error{
+			Category: CategoryParse,
+			Message:  "Model returned JSON without a species",
+		}
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// answerServer answers every call with a chat completion whose content
+// is answer, and records the last request body
+func answerServer(t *testing.T, answer string) (*httptest.Server, *map[string]any) {
+	t.Helper()
+	content, _ := json.Marshal(answer)
+	sent := new(map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(sent); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":` + string(content) + `},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, sent
+}
+
+const chanterelleJSON = `{"species":"Chanterelle","scientific_name":"Cantharellus cibarius","confidence":"High",` +
+	`"features":["false gills","apricot smell"],"edibility":"edible","safety_warning":"Check for Jack-o'-lantern",` +
+	`"similar_species":["Omphalotus olearius"]}`
+
+func TestAnalyzeImageSchema(t *testing.T) {
+	server, sent := answerServer(t, chanterelleJSON)
+
+	c, err := AnalyzeImageSchema(testRequest(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Species != "Chanterelle" || c.ScientificName != "Cantharellus cibarius" || c.Edibility != EdibilityEdible ||
+		len(c.Features) != 2 || len(c.SimilarSpecies) != 1 {
+		t.Errorf("got %+v", c)
+	}
+
+	format, _ := (*sent)["response_format"].(map[string]any)
+	schema, _ := format["json_schema"].(map[string]any)
+	if format["type"] != "json_schema" || schema["name"] != "mushroom_classification" || schema["strict"] != true {
+		t.Errorf("response_format = %v, want the strict mushroom schema", format)
+	}
+}
+
+func TestAnalyzeImageSchemaRejects(t *testing.T) {
+	tests := map[string]string{
+		"not JSON":          "It is a chanterelle.",
+		"truncated":         `{"species":"Chanterelle","edibil`,
+		"no species":        `{"species":"","scientific_name":"","edibility":"edible"}`,
+		"unknown edibility": `{"species":"Chanterelle","scientific_name":"Cantharellus cibarius","edibility":"tasty"}`,
+		"missing edibility": `{"species":"Chanterelle","scientific_name":"Cantharellus cibarius"}`,
+	}
+	for name, answer := range tests {
+		server, _ := answerServer(t, answer)
+		c, err := AnalyzeImageSchema(testRequest(server.URL))
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.Category != CategoryParse {
+			t.Errorf("%s: got %+v, %v; want a parse error", name, c, err)
+		}
+	}
+}
+
+func TestAnalyzeImageStructured(t *testing.T) {
+	// Without a schema any edibility is passed on as answered
+	server, sent := answerServer(t, "\n"+`{"species":"Chanterelle","edibility":"good"}`+"\n")
+
+	c, err := AnalyzeImageStructured(testRequest(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Species != "Chanterelle" || c.Edibility != "good" {
+		t.Errorf("got %+v", c)
+	}
+	if format, _ := (*sent)["response_format"].(map[string]any); format["type"] != "json_object" {
+		t.Errorf("response_format = %v, want json_object", format)
+	}
+
+	server, _ = answerServer(t, "It is a chanterelle.")
+	if _, err := AnalyzeImageStructured(testRequest(server.URL)); err == nil {
+		t.Error("want an error for an answer that is not JSON")
+	}
+}
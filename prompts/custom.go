@@ -0,0 +1,81 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Custom stores a user-edited replacement for the Mushroom prompt
+//
+// The prompt is kept as plain text in a single file. Without the file,
+// the built-in Mushroom prompt is used.
+type Custom struct {
+	path string
+}
+
+// NewCustom returns a store backed by the file at path
+func NewCustom(path string) *Custom {
+	return &Custom{path: path}
+}
+
+// DefaultCustomPath returns the prompt file in the user's config directory
+func DefaultCustomPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "mushroom-classifier", "prompt.txt"), nil
+}
+
+// Path returns the file backing the store
+func (c *Custom) Path() string {
+	return c.path
+}
+
+// Load returns the saved prompt, or Mushroom() if none has been saved
+func (c *Custom) Load() (string, error) {
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Mushroom(), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file %s: %w", c.path, err)
+	}
+
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return Mushroom(), nil
+	}
+	return text, nil
+}
+
+// Save stores text as the prompt
+//
+// The file and its directory are created if needed. Saving blank text
+// or the built-in prompt removes the file, so later changes to the
+// built-in prompt take effect again.
+func (c *Custom) Save(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" || text == Mushroom() {
+		return c.Reset()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create prompt directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, []byte(text+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write prompt file %s: %w", c.path, err)
+	}
+	return nil
+}
+
+// Reset removes the saved prompt, restoring Mushroom()
+func (c *Custom) Reset() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove prompt file %s: %w", c.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCustomRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mushroom-classifier", "prompt.txt")
+	store := NewCustom(path)
+
+	if text, err := store.Load(); err != nil || text != Mushroom() {
+		t.Fatalf("without a file: got %q, %v, want the built-in prompt", text, err)
+	}
+
+	const custom = "Name the species and say whether it is edible."
+	if err := store.Save("  " + custom + "\n\n"); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := NewCustom(path).Load(); err != nil || text != custom {
+		t.Errorf("after saving: got %q, %v, want %q", text, err, custom)
+	}
+
+	if err := store.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("prompt file still there after Reset: %v", err)
+	}
+	if text, _ := store.Load(); text != Mushroom() {
+		t.Errorf("after Reset: got %q, want the built-in prompt", text)
+	}
+}
+
+func TestCustomSaveBuiltInOrBlank(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.txt")
+	store := NewCustom(path)
+
+	for _, text := range []string{Mushroom(), "   "} {
+		if err := store.Save("Custom prompt"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Save(text); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("saving %.20q kept the prompt file", text)
+		}
+	}
+}
+
+func TestCustomLoadBlankFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.txt")
+	if err := os.WriteFile(path, []byte("\n  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := NewCustom(path).Load(); err != nil || text != Mushroom() {
+		t.Errorf("got %q, %v, want the built-in prompt", text, err)
+	}
+}
@@ -0,0 +1,53 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Question is one question of a combined multi-question request
+type Question struct {
+	// JSON key the answer is returned under, e.g. "edibility"
+	Key string
+
+	// Question put to the model
+	Text string
+}
+
+// StandardQuestions are the questions of a full analysis asked in one call
+var StandardQuestions = []Question{
+	{Key: "species", Text: "What species is this? Give the common name and scientific name."},
+	{Key: "edibility", Text: "Is it edible, poisonous, or unknown? Include any safety warnings."},
+	{Key: "habitat", Text: "Where and when does this species typically grow?"},
+}
+
+// Keys returns the answer keys of questions, in order
+func Keys(questions []Question) []string {
+	keys := make([]string, len(questions))
+	for i, q := range questions {
+		keys[i] = q.Key
+	}
+	return keys
+}
+
+// MultiQuestion returns a prompt asking several questions in one request
+//
+// The model is told to answer with a single JSON object holding one
+// string per question key, so that the answers can be split apart with
+// analysis.ParseAnswers.
+func MultiQuestion(questions []Question) string {
+	var b strings.Builder
+	b.WriteString("You are an expert mycologist. Look at this image of a mushroom and answer each of the following questions:\n\n")
+
+	keys := make([]string, 0, len(questions))
+	for _, q := range questions {
+		fmt.Fprintf(&b, "- %s: %s\n", q.Key, q.Text)
+		keys = append(keys, fmt.Sprintf("%q: \"...\"", q.Key))
+	}
+
+	b.WriteString("\nReturn ONLY a JSON object, with no other text, that has exactly one string value per question key:\n\n")
+	fmt.Fprintf(&b, "{%s}\n\n", strings.Join(keys, ", "))
+	b.WriteString("IMPORTANT: Always err on the side of caution. If uncertain, clearly state so in the relevant answer.")
+
+	return b.String()
+}
@@ -0,0 +1,26 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMultiQuestion(t *testing.T) {
+	prompt := MultiQuestion(StandardQuestions)
+
+	for _, q := range StandardQuestions {
+		if !strings.Contains(prompt, "- "+q.Key+": "+q.Text) {
+			t.Errorf("prompt lacks question %q", q.Key)
+		}
+	}
+	if !strings.Contains(prompt, `{"species": "...", "edibility": "...", "habitat": "..."}`) {
+		t.Errorf("prompt lacks the JSON shape:\n%s", prompt)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	got := strings.Join(Keys(StandardQuestions), ",")
+	if got != "species,edibility,habitat" {
+		t.Errorf("Keys = %s", got)
+	}
+}
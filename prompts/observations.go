@@ -0,0 +1,71 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Observations are field notes a photo cannot show, which narrow the
+// identification considerably
+type Observations struct {
+	// Color of the spore print, e.g. "white" or "purple-brown"
+	SporePrintColor string
+
+	// Where the mushroom grew, e.g. "beech forest" or "lawn"
+	Habitat string
+
+	// What it grew on, e.g. "dead hardwood", "soil" or "dung"
+	Substrate string
+
+	// Time of year it was found, e.g. "late autumn"
+	Season string
+}
+
+// Count returns the number of observations given
+func (o Observations) Count() int {
+	n := 0
+	for _, field := range o.fields() {
+		if field.value != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// observation is one labelled field of Observations
+type observation struct {
+	label string
+	value string
+}
+
+// fields returns the observations in prompt order, trimmed
+func (o Observations) fields() []observation {
+	return []observation{
+		{"Spore print color", strings.TrimSpace(o.SporePrintColor)},
+		{"Habitat", strings.TrimSpace(o.Habitat)},
+		{"Substrate", strings.TrimSpace(o.Substrate)},
+		{"Season", strings.TrimSpace(o.Season)},
+	}
+}
+
+// WithObservations adds the observer's field notes to prompt
+//
+// Appends an "Additional observations:" section listing each given
+// observation. Blank observations are left out, and without any the
+// prompt is returned unchanged.
+func WithObservations(prompt string, obs Observations) string {
+	if obs.Count() == 0 {
+		return prompt
+	}
+
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nAdditional observations:\n")
+	for _, field := range obs.fields() {
+		if field.value != "" {
+			fmt.Fprintf(&b, "- %s: %s\n", field.label, field.value)
+		}
+	}
+	b.WriteString("\nTake these observations into account; they were made by the finder in the field.")
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithObservations(t *testing.T) {
+	full := Observations{
+		SporePrintColor: "white",
+		Habitat:         " beech forest ",
+		Substrate:       "soil",
+		Season:          "late autumn",
+	}
+	prompt := WithObservations("Identify this mushroom.", full)
+	want := "Identify this mushroom.\n\nAdditional observations:\n" +
+		"- Spore print color: white\n" +
+		"- Habitat: beech forest\n" +
+		"- Substrate: soil\n" +
+		"- Season: late autumn\n" +
+		"\nTake these observations into account; they were made by the finder in the field."
+	if prompt != want {
+		t.Errorf("got:\n%s\nwant:\n%s", prompt, want)
+	}
+
+	// Blank observations are left out
+	prompt = WithObservations("Identify this mushroom.", Observations{Habitat: "lawn", Season: "  "})
+	if !strings.Contains(prompt, "- Habitat: lawn\n") {
+		t.Errorf("habitat missing:\n%s", prompt)
+	}
+	for _, label := range []string{"Spore print color", "Substrate", "Season"} {
+		if strings.Contains(prompt, label) {
+			t.Errorf("blank %s listed:\n%s", label, prompt)
+		}
+	}
+
+	for _, obs := range []Observations{{}, {SporePrintColor: " ", Substrate: "\t"}} {
+		if prompt := WithObservations("Identify this mushroom.", obs); prompt != "Identify this mushroom." {
+			t.Errorf("%+v changed the prompt to:\n%s", obs, prompt)
+		}
+	}
+}
+
+func TestObservationsCount(t *testing.T) {
+	tests := []struct {
+		obs  Observations
+		want int
+	}{
+		{Observations{}, 0},
+		{Observations{Habitat: "   "}, 0},
+		{Observations{SporePrintColor: "purple-brown"}, 1},
+		{Observations{Habitat: "lawn", Substrate: "dung"}, 2},
+		{Observations{SporePrintColor: "white", Habitat: "lawn", Substrate: "soil", Season: "spring"}, 4},
+	}
+	for _, tt := range tests {
+		if got := tt.obs.Count(); got != tt.want {
+			t.Errorf("%+v: Count() = %d, want %d", tt.obs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package prompts provides the prompts sent to the model
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mushroom returns the prompt for mushroom analysis
+//
+// Shared by the GUI and the command line so both ask for the same
+// structured answer.
+func Mushroom() string {
+	return `You are an expert mycologist. Analyze this image of a mushroom and provide:
+
+1. **Species Identification**: Common name and scientific name
+2. **Confidence Level**: How certain you are of the identification (High/Medium/Low)
+3. **Key Identifying Features**: What visual characteristics led to this identification
+4. **Edibility**: Whether this mushroom is edible, poisonous, or unknown
+5. **Safety Warning**: Any important safety information
+6. **Similar Species**: Other mushrooms it might be confused with
+
+IMPORTANT: Always err on the side of caution. If uncertain, clearly state so. Never encourage consumption of wild mushrooms without expert verification.`
+}
+
+// MushroomDetailed returns the prompt for a second, closer analysis
+//
+// Used when the first answer had low confidence: the model is asked to
+// examine fine details before committing to an identification.
+func MushroomDetailed() string {
+	return `You are an expert mycologist. A first look at this image of a mushroom gave only a low-confidence identification. Examine it again closely, paying attention to fine details: cap surface and margin, gill attachment and spacing, stem texture, rings or volva, colour changes, and the habitat visible in the background. Then provide:
+
+1. **Species Identification**: Common name and scientific name
+2. **Confidence Level**: How certain you are of the identification (High/Medium/Low)
+3. **Key Identifying Features**: The visual details that support this identification, and any that argue against it
+4. **Edibility**: Whether this mushroom is edible, poisonous, or unknown
+5. **Safety Warning**: Any important safety information
+6. **Similar Species**: Other mushrooms it might be confused with, and how to tell them apart
+
+IMPORTANT: Always err on the side of caution. If uncertain, clearly state so. Never encourage consumption of wild mushrooms without expert verification.`
+}
+
+// MushroomJSON returns the prompt for a mushroom analysis answered as JSON
+//
+// Asks for the same sections as Mushroom, as the fields of a single JSON
+// object. The API's JSON mode requires the word "JSON" in the prompt.
+func MushroomJSON() string {
+	return `You are an expert mycologist. Analyze this image of a mushroom and return ONLY a JSON object, with no other text, in this form:
+
+{
+  "species": "common name",
+  "scientific_name": "Genus species",
+  "confidence": "High, Medium or Low",
+  "features": ["key identifying feature", "..."],
+  "edibility": "edible, poisonous or unknown, with a short explanation",
+  "safety_warning": "important safety information",
+  "similar_species": ["mushroom it might be confused with", "..."]
+}
+
+IMPORTANT: Always err on the side of caution. If uncertain, clearly state so. Never encourage consumption of wild mushrooms without expert verification.`
+}
+
+// WithLanguage asks for the answer to prompt to be given in language
+//
+// Appends "Respond in <language>." to the prompt. An empty language
+// leaves the prompt unchanged, so answers stay in English.
+func WithLanguage(prompt, language string) string {
+	language = strings.TrimSpace(language)
+	if language == "" {
+		return prompt
+	}
+	return fmt.Sprintf("%s\n\nRespond in %s.", prompt, language)
+}
@@ -0,0 +1,19 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithLanguage(t *testing.T) {
+	prompt := WithLanguage(Mushroom(), " German ")
+	if !strings.HasPrefix(prompt, Mushroom()) || !strings.HasSuffix(prompt, "\n\nRespond in German.") {
+		t.Errorf("prompt does not end with the language instruction:\n%s", prompt)
+	}
+
+	for _, language := range []string{"", "  "} {
+		if prompt := WithLanguage(Mushroom(), language); prompt != Mushroom() {
+			t.Errorf("language %q changed the prompt", language)
+		}
+	}
+}
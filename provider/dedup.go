@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// Deduplicated wraps a provider so identical concurrent requests share
+// one call
+//
+// While a request is in flight, further requests with the same contents
+// (image, prompt, model and all other settings) wait for its result
+// instead of being sent and billed again. Requests are not cached once
+// answered. Streaming is not offered through the wrapper. Use Deduplicate
+// to create one; it is safe for concurrent use.
+type Deduplicated struct {
+	Provider
+
+	mu    sync.Mutex
+	calls map[[sha256.Size]byte]*sharedCall
+
+	// Called when a request starts waiting for a call, shared or not
+	// (tests only)
+	onWait func()
+}
+
+// sharedCall is a request in flight and, once done, its result
+type sharedCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	resp    *openai.Response
+	err     error
+}
+
+// Deduplicate returns p wrapped to share identical concurrent requests
+func Deduplicate(p Provider) *Deduplicated {
+	return &Deduplicated{
+		Provider: p,
+		calls:    make(map[[sha256.Size]byte]*sharedCall),
+	}
+}
+
+// AnalyzeImage sends req, or waits for an identical request in flight
+//
+// The shared call keeps the values but not the cancellation of the ctx
+// that started it, so it runs as long as any caller waits for it and is
+// cancelled when the last one gives up. Every caller gets its own copy
+// of the Response; a caller whose ctx is done stops waiting and gets
+// ctx.Err().
+func (d *Deduplicated) AnalyzeImage(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+	key, err := req.Fingerprint()
+	if err != nil {
+		// Cannot tell duplicates apart, so send it on its own
+		return d.Provider.AnalyzeImage(ctx, req)
+	}
+
+	d.mu.Lock()
+	call, shared := d.calls[key]
+	if !shared {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &sharedCall{done: make(chan struct{}), cancel: cancel}
+		d.calls[key] = call
+		go d.run(callCtx, key, call, req)
+	}
+	call.waiters++
+	d.mu.Unlock()
+	if d.onWait != nil {
+		d.onWait()
+	}
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		d.leave(key, call)
+		return nil, ctx.Err()
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	resp := *call.resp
+	return &resp, nil
+}
+
+// run sends the request of a shared call and hands the result to its
+// waiters
+//
+// A panic in the provider is reported to the waiters as an error.
+func (d *Deduplicated) run(ctx context.Context, key [sha256.Size]byte, call *sharedCall, req *openai.Request) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.resp, call.err = nil, fmt.Errorf("%s provider panicked: %v", d.Name(), r)
+		}
+		call.cancel()
+		d.forget(key, call)
+		close(call.done)
+	}()
+
+	call.resp, call.err = d.Provider.AnalyzeImage(ctx, req)
+}
+
+// leave stops a caller waiting for call, cancelling it if no caller is
+// left
+func (d *Deduplicated) leave(key [sha256.Size]byte, call *sharedCall) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	call.waiters--
+	if call.waiters == 0 {
+		call.cancel()
+		// Later identical requests start afresh instead of joining
+		if d.calls[key] == call {
+			delete(d.calls, key)
+		}
+	}
+}
+
+// forget removes call from the calls in flight, unless a new call for
+// the same key replaced it already
+func (d *Deduplicated) forget(key [sha256.Size]byte, call *sharedCall) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.calls[key] == call {
+		delete(d.calls, key)
+	}
+}
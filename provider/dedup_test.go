@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// funcProvider answers requests with a function
+type funcProvider func(ctx context.Context, req *openai.Request) (*openai.Response, error)
+
+func (funcProvider) Name() string {
+	return "func"
+}
+
+func (f funcProvider) AnalyzeImage(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+	return f(ctx, req)
+}
+
+// testRequest returns a request to url; all of them are identical
+func testRequest(url string) *openai.Request {
+	return &openai.Request{APIKey: "test-key", APIURL: url, Prompt: "Identify this mushroom"}
+}
+
+// deduplicate wraps p, reporting on the returned channel each request
+// that starts waiting for a call
+func deduplicate(p Provider) (*Deduplicated, chan struct{}) {
+	waiting := make(chan struct{}, 10)
+	d := Deduplicate(p)
+	d.onWait = func() { waiting <- struct{}{} }
+	return d, waiting
+}
+
+func TestDeduplicatedSharesConcurrentCalls(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		calls.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Chanterelle"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	d, waiting := deduplicate(OpenAI{})
+
+	var wg sync.WaitGroup
+	responses := make([]*openai.Response, 2)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := d.AnalyzeImage(context.Background(), testRequest(server.URL))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			responses[i] = resp
+		}(i)
+	}
+
+	// Answer only once both requests wait
+	<-waiting
+	<-waiting
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server called %d times, want 1", n)
+	}
+	for i, resp := range responses {
+		if resp == nil || resp.Content != "Chanterelle" {
+			t.Errorf("response %d = %+v", i, resp)
+		}
+	}
+	if responses[0] == responses[1] {
+		t.Error("callers share one Response")
+	}
+}
+
+func TestDeduplicatedFirstCallerCancels(t *testing.T) {
+	release := make(chan struct{})
+	d, waiting := deduplicate(funcProvider(func(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+		select {
+		case <-release:
+			return &openai.Response{Success: true, Content: "Porcini"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := d.AnalyzeImage(ctx, testRequest("http://example.com"))
+		first <- err
+	}()
+	<-waiting
+
+	second := make(chan *openai.Response, 1)
+	go func() {
+		resp, err := d.AnalyzeImage(context.Background(), testRequest("http://example.com"))
+		if err != nil {
+			t.Errorf("second caller: %v", err)
+		}
+		second <- resp
+	}()
+	<-waiting
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller got %v, want context.Canceled", err)
+	}
+
+	close(release)
+	if resp := <-second; resp == nil || resp.Content != "Porcini" {
+		t.Errorf("second caller got %+v, want the answer", resp)
+	}
+}
+
+func TestDeduplicatedLastCallerCancels(t *testing.T) {
+	stopped := make(chan struct{})
+	d, waiting := deduplicate(funcProvider(func(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+		<-ctx.Done()
+		close(stopped)
+		return nil, ctx.Err()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := d.AnalyzeImage(ctx, testRequest("http://example.com"))
+		done <- err
+	}()
+	<-waiting
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	<-stopped
+}
+
+func TestDeduplicatedPanic(t *testing.T) {
+	var calls atomic.Int32
+	d := Deduplicate(funcProvider(func(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		return &openai.Response{Success: true, Content: "Morel"}, nil
+	}))
+
+	_, err := d.AnalyzeImage(context.Background(), testRequest("http://example.com"))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("got %v, want the panic as an error", err)
+	}
+
+	// The failed call must not block identical requests after it
+	resp, err := d.AnalyzeImage(context.Background(), testRequest("http://example.com"))
+	if err != nil || resp.Content != "Morel" || calls.Load() != 2 {
+		t.Errorf("got %+v, %v after %d calls", resp, err, calls.Load())
+	}
+}
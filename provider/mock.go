@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// MockContent is the sample answer of the mock provider
+//
+// It follows the sections of prompts.Mushroom so the result view and
+// the answer parsers behave as with a real classification.
+const MockContent = `**Demo result from the offline mock provider, not an identification of your image.**
+
+1. **Species Identification**: Chanterelle (Cantharellus cibarius)
+2. **Confidence Level**: Medium
+3. **Key Identifying Features**: Egg-yellow funnel-shaped cap with a wavy margin; blunt, forked false gills running down the stem; solid pale stem; fruity, apricot-like smell.
+4. **Edibility**: Edible, a prized choice edible when correctly identified.
+5. **Safety Warning**: This is sample text. Never eat a wild mushroom without confirmation from an experienced forager.
+6. **Similar Species**: Jack-o'-lantern (Omphalotus olearius), which is poisonous and has true, sharp gills; false chanterelle (Hygrophoropsis aurantiaca).`
+
+// MockJSONContent is the sample answer to requests for a JSON response
+const MockJSONContent = `{
+  "species": "Chanterelle (demo result from the offline mock provider)",
+  "scientific_name": "Cantharellus cibarius",
+  "confidence": "Medium",
+  "features": ["egg-yellow funnel-shaped cap", "blunt forked false gills", "fruity smell"],
+  "edibility": "edible, a prized choice edible when correctly identified",
+  "safety_warning": "This is sample text. Never eat a wild mushroom without confirmation from an experienced forager.",
+  "similar_species": ["Jack-o'-lantern (Omphalotus olearius)", "false chanterelle (Hygrophoropsis aurantiaca)"]
+}`
+
+// Mock answers every request with a canned sample, without a network
+// connection, e.g. for demos offline
+type Mock struct {
+	// Answer returned for every request (MockContent when empty)
+	Content string
+
+	// Time to wait before answering, e.g. to try timeouts and
+	// cancellation; the wait ends early when the context is done
+	Delay time.Duration
+}
+
+// Name returns "mock"
+func (Mock) Name() string {
+	return "mock"
+}
+
+// AnalyzeImage calls openai.AnalyzeImageMockContext with the mock's
+// answer, or MockJSONContent if req asks for a JSON response, after
+// waiting for Delay
+func (m Mock) AnalyzeImage(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+	if m.Delay > 0 {
+		timer := time.NewTimer(m.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	content := m.Content
+	if content == "" {
+		content = MockContent
+		if req.JSONResponse {
+			content = MockJSONContent
+		}
+	}
+	return openai.AnalyzeImageMockContext(ctx, req, content)
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/analysis"
+)
+
+func TestMockDeterministic(t *testing.T) {
+	var first string
+	for i := 0; i < 3; i++ {
+		resp, err := Mock{}.AnalyzeImage(context.Background(), testRequest("http://example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Success || resp.Content != MockContent {
+			t.Fatalf("got %+v, want the sample answer", resp)
+		}
+		if i == 0 {
+			first = resp.Content
+		} else if resp.Content != first {
+			t.Errorf("answer %d differs from the first", i)
+		}
+	}
+
+	// The sample parses like a real answer
+	if species := analysis.ParseSpecies(MockContent); species != "Cantharellus cibarius" {
+		t.Errorf("species = %q", species)
+	}
+	if confidence := analysis.ParseConfidence(MockContent); confidence != analysis.Medium {
+		t.Errorf("confidence = %s", confidence)
+	}
+}
+
+func TestMockContent(t *testing.T) {
+	req := testRequest("http://example.com")
+
+	resp, err := Mock{Content: "Porcini"}.AnalyzeImage(context.Background(), req)
+	if err != nil || !resp.Success || resp.Content != "Porcini" {
+		t.Errorf("custom answer: got %+v, %v", resp, err)
+	}
+
+	req.JSONResponse = true
+	resp, err = Mock{}.AnalyzeImage(context.Background(), req)
+	if err != nil || !resp.Success || resp.Content != MockJSONContent {
+		t.Errorf("JSON answer: got %+v, %v", resp, err)
+	}
+}
+
+func TestMockCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if resp, err := (Mock{}).AnalyzeImage(ctx, testRequest("http://example.com")); err == nil {
+		t.Errorf("got %+v after cancelling", resp)
+	}
+}
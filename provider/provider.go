@@ -0,0 +1,134 @@
+// Package provider selects the vision API used for classification
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/config"
+	"github.com/mushroom-classifier/mushroom-classifier-go/openai"
+)
+
+// Provider sends image analysis requests to a vision API
+//
+// Requests and responses use the openai types whatever the provider;
+// settings a provider does not support are ignored.
+type Provider interface {
+	// Name identifies the provider, as used in the PROVIDER setting
+	Name() string
+
+	// AnalyzeImage sends req and returns the answer
+	//
+	// Failures are reported in the Response as with openai.AnalyzeImage.
+	// If ctx is done first, ctx.Err() is returned instead.
+	AnalyzeImage(ctx context.Context, req *openai.Request) (*openai.Response, error)
+}
+
+// Streamer is implemented by providers that can stream answers
+type Streamer interface {
+	// AnalyzeImageStream is like AnalyzeImage but calls onDelta with each
+	// piece of the answer as it arrives
+	AnalyzeImageStream(ctx context.Context, req *openai.Request, onDelta func(string)) (*openai.Response, error)
+}
+
+// New returns the provider with the given name
+//
+// Known names are "openai", "anthropic" and "mock"; an empty name
+// selects OpenAI.
+func New(name string) (Provider, error) {
+	switch name {
+	case "", "openai":
+		return OpenAI{}, nil
+	case "anthropic":
+		return Anthropic{}, nil
+	case "mock":
+		return Mock{}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+}
+
+// FromConfig returns the provider selected in cfg
+//
+// Like New, but also applies provider settings such as the answer of the
+// mock provider and its delay.
+func FromConfig(cfg *config.Config) (Provider, error) {
+	if cfg.Provider == "mock" {
+		return Mock{Content: cfg.MockResponse, Delay: cfg.MockDelay}, nil
+	}
+	return New(cfg.Provider)
+}
+
+// AnalyzeMulti sends n identical requests to p concurrently
+//
+// Returns one Response per request, in no particular relation to the
+// order they completed; failed requests are reported in their Response.
+// n below 1 is treated as 1. Each call gets its own copy of req.
+// Cancelling ctx aborts every outstanding request and returns ctx.Err().
+func AnalyzeMulti(ctx context.Context, p Provider, req *openai.Request, n int) ([]*openai.Response, error) {
+	n = max(n, 1)
+
+	responses := make([]*openai.Response, n)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := *req
+			// A cancelled call leaves a nil response; ctx.Err() covers it
+			responses[i], _ = p.AnalyzeImage(ctx, &r)
+		}(i)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// Ping checks that p accepts the key and URL of req
+//
+// Like openai.Ping, but for any provider: a minimal text-only request
+// built with openai.PingRequest is sent, and nil is returned if it
+// succeeds.
+func Ping(ctx context.Context, p Provider, req *openai.Request) error {
+	resp, err := p.AnalyzeImage(ctx, openai.PingRequest(req))
+	if err != nil {
+		return err
+	}
+	return resp.Err()
+}
+
+// OpenAI sends requests to the OpenAI chat completions API or a
+// compatible server
+type OpenAI struct{}
+
+// Name returns "openai"
+func (OpenAI) Name() string {
+	return "openai"
+}
+
+// AnalyzeImage calls openai.AnalyzeImageContext
+func (OpenAI) AnalyzeImage(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+	return openai.AnalyzeImageContext(ctx, req)
+}
+
+// AnalyzeImageStream calls openai.AnalyzeImageStreamContext
+func (OpenAI) AnalyzeImageStream(ctx context.Context, req *openai.Request, onDelta func(string)) (*openai.Response, error) {
+	return openai.AnalyzeImageStreamContext(ctx, req, onDelta)
+}
+
+// Anthropic sends requests to Anthropic's Messages API
+type Anthropic struct{}
+
+// Name returns "anthropic"
+func (Anthropic) Name() string {
+	return "anthropic"
+}
+
+// AnalyzeImage calls openai.AnalyzeImageAnthropicContext
+func (Anthropic) AnalyzeImage(ctx context.Context, req *openai.Request) (*openai.Response, error) {
+	return openai.AnalyzeImageAnthropicContext(ctx, req)
+}
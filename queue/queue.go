@@ -0,0 +1,174 @@
+// Package queue provides a persistent queue of pending classifications
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Job is one image waiting to be classified
+type Job struct {
+	// Path of the image file
+	ImagePath string `json:"image_path"`
+
+	// Model to classify with
+	Model string `json:"model"`
+}
+
+// Queue is a first-in, first-out list of jobs
+//
+// When backed by a file, every change is written to disk before the
+// call returns, so pending jobs survive a crash and can be reloaded with
+// Open. Queue is safe for concurrent use.
+type Queue struct {
+	mu   sync.Mutex
+	path string
+	jobs []Job
+}
+
+// Open loads the queue stored at path
+//
+// A missing file yields an empty queue. An empty path gives a queue that
+// is kept in memory only.
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path}
+	if path == "" {
+		return q, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &q.jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse queue %s: %w", path, err)
+	}
+	return q, nil
+}
+
+// Enqueue adds a job to the end of the queue
+func (q *Queue) Enqueue(job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.jobs = append(q.jobs, job)
+	if err := q.save(); err != nil {
+		q.jobs = q.jobs[:len(q.jobs)-1]
+		return err
+	}
+	return nil
+}
+
+// Peek returns the job at the front of the queue without removing it
+//
+// Callers remove the job with Dequeue once it has been processed, so a
+// job interrupted by a crash is still pending on restart.
+func (q *Queue) Peek() (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.jobs) == 0 {
+		return Job{}, false
+	}
+	return q.jobs[0], true
+}
+
+// Dequeue removes and returns the job at the front of the queue
+func (q *Queue) Dequeue() (Job, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.jobs) == 0 {
+		return Job{}, false, nil
+	}
+
+	previous := q.jobs
+	job := q.jobs[0]
+	q.jobs = q.jobs[1:]
+	if err := q.save(); err != nil {
+		q.jobs = previous
+		return Job{}, false, err
+	}
+	return job, true, nil
+}
+
+// Clear removes all jobs
+func (q *Queue) Clear() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	previous := q.jobs
+	q.jobs = nil
+	if err := q.save(); err != nil {
+		q.jobs = previous
+		return err
+	}
+	return nil
+}
+
+// Len returns the number of pending jobs
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.jobs)
+}
+
+// Pending returns a copy of the pending jobs in order
+func (q *Queue) Pending() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]Job(nil), q.jobs...)
+}
+
+// save writes the jobs to the backing file, if any
+//
+// The file is replaced atomically: the jobs are written to a temporary
+// file in the same directory, synced, and renamed over the old file, so
+// a crash leaves either the old or the new queue but never a partial one.
+func (q *Queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+
+	jobs := q.jobs
+	if jobs == nil {
+		jobs = []Job{}
+	}
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queue: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save queue: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save queue: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save queue: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save queue: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), q.path); err != nil {
+		return fmt.Errorf("failed to save queue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestQueuePersistence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.json")
+
+	q, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs := []Job{
+		{ImagePath: "/photos/a.jpg", Model: "gpt-4o"},
+		{ImagePath: "/photos/b.jpg", Model: "gpt-4o-mini"},
+		{ImagePath: "/photos/c.jpg", Model: "gpt-4o"},
+	}
+	for _, job := range jobs {
+		if err := q.Enqueue(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A restart sees every job in order
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Pending(); !reflect.DeepEqual(got, jobs) {
+		t.Fatalf("Pending() = %+v, want %+v", got, jobs)
+	}
+
+	// Processed jobs stay removed
+	job, ok, err := reopened.Dequeue()
+	if err != nil || !ok || job != jobs[0] {
+		t.Fatalf("Dequeue() = %+v, %v, %v", job, ok, err)
+	}
+	reopened, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Pending(); !reflect.DeepEqual(got, jobs[1:]) {
+		t.Errorf("after Dequeue, Pending() = %+v, want %+v", got, jobs[1:])
+	}
+
+	if err := reopened.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 0 {
+		t.Errorf("after Clear, Len() = %d", reopened.Len())
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the queue", len(entries))
+	}
+}
+
+func TestOpenMissingAndCorrupt(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := Open(filepath.Join(dir, "missing.json"))
+	if err != nil || q.Len() != 0 {
+		t.Errorf("missing file: Len() = %d, err = %v", q.Len(), err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	os.WriteFile(corrupt, []byte(`[{"image_path": `), 0o644)
+	if _, err := Open(corrupt); err == nil {
+		t.Error("corrupt queue opened")
+	}
+}
+
+func TestMemoryQueue(t *testing.T) {
+	q, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Enqueue(Job{ImagePath: "a.jpg"})
+	if job, ok := q.Peek(); !ok || job.ImagePath != "a.jpg" || q.Len() != 1 {
+		t.Errorf("Peek() = %+v, %v", job, ok)
+	}
+}
@@ -0,0 +1,122 @@
+package thumbnail
+
+import (
+	"image"
+	"log"
+	"sync"
+
+	"github.com/mushroom-classifier/mushroom-classifier-go/base64"
+)
+
+// loadWorkers bounds the number of thumbnails decoded at once
+const loadWorkers = 2
+
+// Loader decodes and caches thumbnails on demand
+//
+// Thumbnails are only decoded for the keys passed to Load, in the
+// background, and dropped again by Retain once they leave the prefetch
+// window, so memory stays bounded for long lists. Loader is safe for
+// concurrent use.
+type Loader struct {
+	// Decodes the full image for a key
+	decode func(key string) (image.Image, error)
+
+	// Longest side of a thumbnail in pixels
+	size int
+
+	// Called (from a background goroutine) when a thumbnail is ready
+	onLoaded func(key string)
+
+	// Limits concurrent decoding
+	sem chan struct{}
+
+	mu      sync.Mutex
+	cache   map[string]image.Image
+	pending map[string]bool
+}
+
+// NewLoader creates a loader producing thumbnails of at most size pixels
+//
+// decode reads the full image for a key, usually a file path. onLoaded
+// may be nil.
+func NewLoader(size int, decode func(key string) (image.Image, error), onLoaded func(key string)) *Loader {
+	return &Loader{
+		decode:   decode,
+		size:     size,
+		onLoaded: onLoaded,
+		sem:      make(chan struct{}, loadWorkers),
+		cache:    make(map[string]image.Image),
+		pending:  make(map[string]bool),
+	}
+}
+
+// Get returns the thumbnail for a key if it has been loaded
+//
+// A key whose image could not be decoded is reported as loaded with a
+// nil image, so it is not retried on every scroll.
+func (l *Loader) Get(key string) (image.Image, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	img, ok := l.cache[key]
+	return img, ok
+}
+
+// Load starts decoding the thumbnails that are neither cached nor
+// already being decoded
+func (l *Loader) Load(keys []string) {
+	for _, key := range keys {
+		l.mu.Lock()
+		_, cached := l.cache[key]
+		busy := l.pending[key]
+		if !cached && !busy {
+			l.pending[key] = true
+		}
+		l.mu.Unlock()
+
+		if !cached && !busy {
+			go l.load(key)
+		}
+	}
+}
+
+// Retain drops cached thumbnails for all keys not in keep
+func (l *Loader) Retain(keep []string) {
+	wanted := make(map[string]bool, len(keep))
+	for _, key := range keep {
+		wanted[key] = true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key := range l.cache {
+		if !wanted[key] {
+			delete(l.cache, key)
+		}
+	}
+}
+
+// load decodes one thumbnail and stores it in the cache
+func (l *Loader) load(key string) {
+	l.sem <- struct{}{}
+	img, err := l.decode(key)
+	if err == nil {
+		img = base64.Downscale(img, l.size)
+	}
+	<-l.sem
+
+	if err != nil {
+		log.Printf("Warning: failed to load thumbnail for %s: %v", key, err)
+		img = nil
+	}
+
+	l.mu.Lock()
+	delete(l.pending, key)
+	l.cache[key] = img
+	l.mu.Unlock()
+
+	if l.onLoaded != nil {
+		l.onLoaded(key)
+	}
+}
@@ -0,0 +1,64 @@
+// Package thumbnail provides lazily loaded image thumbnails for lists
+//
+// The viewport model decides which rows of a scrolling list need their
+// thumbnails, independently of any GUI toolkit, and the Loader decodes
+// those thumbnails in the background on demand.
+package thumbnail
+
+// Range is a half-open range of list indexes [First, Last)
+type Range struct {
+	First int
+	Last  int
+}
+
+// Len returns the number of indexes in the range
+func (r Range) Len() int {
+	return max(0, r.Last-r.First)
+}
+
+// Contains reports whether index i is in the range
+func (r Range) Contains(i int) bool {
+	return i >= r.First && i < r.Last
+}
+
+// Viewport describes a vertically scrolling list of equal-height rows
+type Viewport struct {
+	// Number of rows in the list
+	Count int
+
+	// Height of one row
+	RowHeight float32
+
+	// Rows loaded beyond each edge of the visible area, so thumbnails
+	// are ready before they scroll into view
+	Prefetch int
+}
+
+// Visible returns the rows at least partly shown when the list is
+// scrolled down by offset and the visible area is height tall
+func (v Viewport) Visible(offset, height float32) Range {
+	if v.Count == 0 || v.RowHeight <= 0 || height <= 0 {
+		return Range{}
+	}
+
+	offset = max(offset, 0)
+	first := int(offset / v.RowHeight)
+	last := int((offset + height + v.RowHeight - 1) / v.RowHeight)
+	return v.clamp(Range{First: first, Last: last})
+}
+
+// Around returns the rows to load for a visible range: the range itself
+// widened by Prefetch rows on each side, clamped to the list
+func (v Viewport) Around(visible Range) Range {
+	return v.clamp(Range{
+		First: visible.First - v.Prefetch,
+		Last:  visible.Last + v.Prefetch,
+	})
+}
+
+// clamp limits a range to the rows of the list
+func (v Viewport) clamp(r Range) Range {
+	r.First = min(max(r.First, 0), v.Count)
+	r.Last = min(max(r.Last, r.First), v.Count)
+	return r
+}
@@ -0,0 +1,58 @@
+package thumbnail
+
+import "testing"
+
+func TestViewportVisible(t *testing.T) {
+	v := Viewport{Count: 100, RowHeight: 50}
+
+	tests := []struct {
+		name           string
+		offset, height float32
+		want           Range
+	}{
+		{"top", 0, 200, Range{0, 4}},
+		{"partly shown rows", 25, 200, Range{0, 5}},
+		{"row boundary", 100, 100, Range{2, 4}},
+		{"negative offset", -30, 100, Range{0, 2}},
+		{"end of list", 4900, 500, Range{98, 100}},
+		{"past the end", 10000, 200, Range{100, 100}},
+		{"no height", 0, 0, Range{}},
+	}
+	for _, tt := range tests {
+		if got := v.Visible(tt.offset, tt.height); got != tt.want {
+			t.Errorf("%s: Visible(%v, %v) = %+v, want %+v", tt.name, tt.offset, tt.height, got, tt.want)
+		}
+	}
+
+	if got := (Viewport{RowHeight: 50}).Visible(0, 200); got.Len() != 0 {
+		t.Errorf("empty list: Visible = %+v", got)
+	}
+}
+
+func TestViewportAround(t *testing.T) {
+	v := Viewport{Count: 20, RowHeight: 50, Prefetch: 3}
+
+	tests := []struct {
+		visible, want Range
+	}{
+		{Range{5, 9}, Range{2, 12}},
+		{Range{0, 4}, Range{0, 7}},
+		{Range{17, 20}, Range{14, 20}},
+		{Range{1, 19}, Range{0, 20}},
+	}
+	for _, tt := range tests {
+		if got := v.Around(tt.visible); got != tt.want {
+			t.Errorf("Around(%+v) = %+v, want %+v", tt.visible, got, tt.want)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	r := Range{First: 2, Last: 5}
+	if r.Len() != 3 || !r.Contains(2) || !r.Contains(4) || r.Contains(5) || r.Contains(1) {
+		t.Errorf("Range %+v: Len %d, Contains wrong", r, r.Len())
+	}
+	if (Range{First: 5, Last: 2}).Len() != 0 {
+		t.Error("inverted range has a length")
+	}
+}